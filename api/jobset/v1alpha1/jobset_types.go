@@ -15,6 +15,8 @@ package v1alpha1
 
 import (
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,6 +27,49 @@ const (
 	JobIndexKey           string = "jobset.sigs.k8s.io/job-index"
 	JobNameKey            string = "job-name" // TODO(#26): Migrate to the fully qualified label name.
 	ExclusiveKey          string = "alpha.jobset.sigs.k8s.io/exclusive-topology"
+	// JobSetGenerationKey is the label holding the generation of the JobSet spec a child
+	// Job or pod was created from, or last brought in line with.
+	JobSetGenerationKey string = "jobset.sigs.k8s.io/jobset-generation"
+	// WorkloadTypeKey is the label holding the workload type of the JobSet on its child Jobs and pods.
+	WorkloadTypeKey string = "jobset.sigs.k8s.io/workload-type"
+	// PodHostnameKey is the annotation holding the stable DNS hostname of a pod,
+	// set when Network.AnnotatePodHostnames is enabled.
+	PodHostnameKey string = "jobset.sigs.k8s.io/hostname"
+	// CoordinatorEnvName is the environment variable holding the stable DNS hostname of
+	// the coordinator pod, injected into all containers when Coordinator is set.
+	CoordinatorEnvName string = "JOBSET_COORDINATOR"
+	// WaitForCapacityKey is the annotation which, when set to "true", makes the controller
+	// wait for enough schedulable capacity in the cluster before creating the child jobs.
+	// The check is approximate: it compares the resources requested by all pods of the
+	// JobSet against the total allocatable resources of ready, schedulable nodes.
+	WaitForCapacityKey string = "alpha.jobset.sigs.k8s.io/wait-for-capacity"
+	// ConcurrencyKeyKey is the annotation grouping JobSets which must not run concurrently.
+	// Of the unfinished, unsuspended JobSets in a namespace sharing a key, only the oldest
+	// runs; the child jobs of the others are not created until it finishes or is suspended.
+	ConcurrencyKeyKey string = "alpha.jobset.sigs.k8s.io/concurrency-key"
+	// SuspendOnQuotaExceededKey is the annotation which, when set to "true", makes the
+	// controller hold the child jobs suspended while starting them would exceed a resource
+	// quota of the namespace, and resume them once the quota has room again.
+	SuspendOnQuotaExceededKey string = "alpha.jobset.sigs.k8s.io/suspend-on-quota-exceeded"
+	// ManagedServiceAccountKey is the annotation which, when set to "true", makes the
+	// controller create a ServiceAccount named after the JobSet and run all of its pods
+	// under it. The ServiceAccount is owned by the JobSet and deleted along with it, so
+	// role bindings granted to it are scoped to a single run of the workload.
+	ManagedServiceAccountKey string = "alpha.jobset.sigs.k8s.io/managed-service-account"
+	// CleanupNowKey is the annotation which, when set to "true" on a Completed or Failed
+	// JobSet, makes the controller delete it immediately, regardless of the time remaining
+	// of its TTLSecondsAfterFinished. It has no effect on JobSets which have not finished.
+	CleanupNowKey string = "alpha.jobset.sigs.k8s.io/cleanup-now"
+	// CompleteNowKey is the annotation which, when set to "true" on a JobSet which has not
+	// finished, makes the controller mark it Completed and delete its active child jobs.
+	// It is the way to finish JobSets whose success policy uses the Never operator.
+	CompleteNowKey string = "alpha.jobset.sigs.k8s.io/complete-now"
+	// PodGroupNameKey is the annotation holding the name of the pod group of the JobSet on
+	// its child pods, when gang scheduling is enabled. It is the key read by Volcano.
+	PodGroupNameKey string = "scheduling.k8s.io/group-name"
+	// PodGroupMinMemberKey is the annotation holding the number of pods of the pod group
+	// which must be scheduled together, on the child pods when gang scheduling is enabled.
+	PodGroupMinMemberKey string = "jobset.sigs.k8s.io/pod-group-min-member"
 )
 
 type JobSetConditionType string
@@ -37,11 +82,57 @@ const (
 	JobSetFailed JobSetConditionType = "Failed"
 	// JobSetSuspended means the job is suspended
 	JobSetSuspended JobSetConditionType = "Suspended"
+	// JobSetImagePullFailure means pods of the job are unable to pull their container images.
+	JobSetImagePullFailure JobSetConditionType = "ImagePullFailure"
+	// JobSetPodsPending means pods of the job have been pending for longer than the configured threshold.
+	JobSetPodsPending JobSetConditionType = "PodsPending"
+	// JobSetReconcileError means the last reconcile of the job failed, and carries the error.
+	JobSetReconcileError JobSetConditionType = "ReconcileError"
+	// JobSetWaitingForCapacity means the child jobs are not created yet, because the cluster
+	// does not have enough schedulable capacity to run all of their pods.
+	JobSetWaitingForCapacity JobSetConditionType = "WaitingForCapacity"
+	// JobSetQueued means the child jobs are not created yet, because an older JobSet sharing
+	// the same concurrency key is still running.
+	JobSetQueued JobSetConditionType = "Queued"
+	// JobSetCompletionsExceedParallelism is an advisory condition meaning the child Jobs of a
+	// replicated job with DNS hostnames enabled run more completions than pods in parallel,
+	// so not all of their indices run at the same time and some peers cannot be reached.
+	JobSetCompletionsExceedParallelism JobSetConditionType = "CompletionsExceedParallelism"
+	// JobSetRestarting means the jobs of a previous restart attempt are being deleted,
+	// and the jobs of the current attempt have not all been recreated yet.
+	JobSetRestarting JobSetConditionType = "Restarting"
+	// JobSetQuotaExceeded means the child jobs are held suspended, because starting them
+	// would exceed a resource quota of the namespace.
+	JobSetQuotaExceeded JobSetConditionType = "QuotaExceeded"
+	// JobSetWaitingForDependencies means the child jobs of some replicated jobs are not
+	// created yet, because the replicated jobs they depend on are not ready.
+	JobSetWaitingForDependencies JobSetConditionType = "WaitingForDependencies"
+	// JobSetUnsupportedJobFeatures means the child jobs use fields which the cluster does
+	// not support, e.g. because their feature gate is disabled, and which the API server
+	// dropped when creating them.
+	JobSetUnsupportedJobFeatures JobSetConditionType = "UnsupportedJobFeatures"
+	// JobSetReady means every replicated job has all of its child Jobs running with all of
+	// their pods ready, and there is at least one. It is cleared once the JobSet finishes.
+	JobSetReady JobSetConditionType = "Ready"
+	// JobSetUnsatisfiableSuccessPolicy means all child Jobs have completed without satisfying
+	// the success policy, because the child Jobs of replicated jobs it targets were never
+	// created and cannot be, e.g. because their dependencies will not become ready.
+	JobSetUnsatisfiableSuccessPolicy JobSetConditionType = "UnsatisfiableSuccessPolicy"
+	// JobSetInvalidCoordinator means the coordinator does not identify a pod of the JobSet,
+	// e.g. because the replicas or completions of its replicated job changed, so its hostname
+	// is not injected into the child Jobs.
+	JobSetInvalidCoordinator JobSetConditionType = "InvalidCoordinator"
+	// JobSetServiceAccountConflict means a ServiceAccount named after the JobSet already
+	// exists and is not controlled by it, so the child Jobs are not created, rather than
+	// running the pods under a foreign identity.
+	JobSetServiceAccountConflict JobSetConditionType = "ServiceAccountConflict"
 )
 
 // JobSetSpec defines the desired state of JobSet
 type JobSetSpec struct {
 	// ReplicatedJobs is the group of jobs that will form the set.
+	// Replicated jobs can be removed while the JobSet is suspended, in which case their
+	// child jobs are deleted once the JobSet is resumed.
 	// +listType=map
 	// +listMapKey=name
 	ReplicatedJobs []ReplicatedJob `json:"replicatedJobs,omitempty"`
@@ -60,8 +151,125 @@ type JobSetSpec struct {
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	FailurePolicy *FailurePolicy `json:"failurePolicy,omitempty"`
 
+	// ParallelismOverrides overrides the parallelism of the child Jobs of the named
+	// replicatedJobs, without editing their templates.
+	// Overrides are applied when the child Jobs are created. The override of a replicatedJob
+	// can only be updated while its child Jobs are suspended, and is applied to the existing
+	// child Jobs when they are resumed. The overrides of a gang scheduled JobSet, or of one
+	// setting totalPodsEnvName, can only be updated while the JobSet is suspended.
+	// +optional
+	ParallelismOverrides map[string]int32 `json:"parallelismOverrides,omitempty"`
+
+	// JobDefaults sets the completions and parallelism of the child Jobs of replicatedJobs
+	// whose templates leave them unset, to avoid repeating them across many identical
+	// replicatedJobs. Values set in a template take precedence.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	// +optional
+	JobDefaults *JobDefaults `json:"jobDefaults,omitempty"`
+
+	// JobNaming customizes the names of the child Jobs, which otherwise follow the format
+	// <jobSet.name>-<replicatedJob.name>-<job-index>, e.g. for tooling parsing the names.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	// +optional
+	JobNaming *JobNaming `json:"jobNaming,omitempty"`
+
+	// SchedulerName is the name of the scheduler set on the pod templates of all
+	// child Jobs that do not set one themselves.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	// +optional
+	SchedulerName string `json:"schedulerName,omitempty"`
+
+	// PodAnnotations are merged into the pod template annotations of all child Jobs, but not
+	// into the annotations of the Jobs themselves, e.g. for service mesh sidecar injection.
+	// Annotations set in a pod template take precedence. Keys under the jobset.sigs.k8s.io
+	// domain are reserved.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	// +optional
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// PropagatedLabels are the keys of the JobSet labels copied to all child Jobs and their
+	// pod templates, e.g. for cost allocation. Labels set in a Job or pod template take
+	// precedence. Keys under the jobset.sigs.k8s.io domain are reserved.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	// +optional
+	PropagatedLabels []string `json:"propagatedLabels,omitempty"`
+
+	// PropagatedAnnotations are the keys of the JobSet annotations copied to all child Jobs
+	// and their pod templates. Annotations set in a Job or pod template take precedence.
+	// Keys under the jobset.sigs.k8s.io domain are reserved.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	// +optional
+	PropagatedAnnotations []string `json:"propagatedAnnotations,omitempty"`
+
+	// SharedVolumes are emptyDir volumes added to the pod template of every child Job of the
+	// JobSet, in addition to the shared volumes of its ReplicatedJob, and mounted into all of
+	// its containers. Their names must not collide with a volume of any ReplicatedJob.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	SharedVolumes []SharedVolume `json:"sharedVolumes,omitempty"`
+
+	// WorkloadType tags the JobSet with the type of workload it runs. The controller sets it
+	// as the jobset.sigs.k8s.io/workload-type label on all child Jobs and pods, as a hint for
+	// schedulers and policies.
+	// +kubebuilder:validation:Enum=Training;Batch;Service
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	// +optional
+	WorkloadType WorkloadType `json:"workloadType,omitempty"`
+
+	// TotalPodsEnvName, if set, is the name of an environment variable injected into all
+	// containers of the child Jobs, holding the total number of pods the JobSet runs at once,
+	// i.e. the sum over replicatedJobs of replicas times parallelism. This is commonly needed
+	// by distributed frameworks, e.g. as WORLD_SIZE. Environment variables set in a container
+	// take precedence. Child Jobs are recreated when the JobSet is resumed if the total
+	// changed while it was suspended, due to updated parallelism overrides.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	// +optional
+	TotalPodsEnvName string `json:"totalPodsEnvName,omitempty"`
+
+	// Coordinator, if set, identifies the pod coordinating the others, e.g. the rank 0 pod
+	// of distributed training. Its stable DNS hostname is injected into all containers of
+	// the child Jobs as the JOBSET_COORDINATOR environment variable. Requires DNS hostnames
+	// to be enabled for the coordinator replicatedJob.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	// +optional
+	Coordinator *Coordinator `json:"coordinator,omitempty"`
+
+	// SchedulingPolicy configures how the pods of the JobSet are scheduled.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	// +optional
+	SchedulingPolicy *SchedulingPolicy `json:"schedulingPolicy,omitempty"`
+
+	// TTLSecondsAfterFinished limits the lifetime of a JobSet that has finished
+	// execution, either Completed or Failed. If set, the JobSet becomes eligible for
+	// deletion TTLSecondsAfterFinished seconds after it finishes, and its child Jobs are
+	// garbage collected along with it. If set to zero, the JobSet is eligible for deletion
+	// immediately after it finishes. If unset, the JobSet is not deleted automatically.
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+
+	// ActiveDeadlineSeconds limits how long the JobSet may run before it is failed with
+	// the DeadlineExceeded reason and its child Jobs are deleted. The running time is
+	// counted from the creation of its first child Jobs, across restarts, and excludes the
+	// time the JobSet spent suspended. It must be positive if set.
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
 	// Suspend suspends all running child Jobs when set to true.
 	Suspend *bool `json:"suspend,omitempty"`
+
+	// SuspendPolicy determines what happens to the running pods of the child Jobs when the
+	// JobSet or their ReplicatedJob is suspended. With DeletePods, the default, the child
+	// Jobs are suspended and their pods are deleted gracefully, so workloads can checkpoint
+	// on SIGTERM. With KeepPods, running child Jobs are not suspended. Their parallelism is
+	// lowered to their number of running pods, and lowered again as pods finish, so the
+	// running pods are left to finish. A pod finishing before the parallelism is lowered may
+	// still be replaced. The parallelism is restored on resume.
+	// +kubebuilder:validation:Enum=DeletePods;KeepPods
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	// +optional
+	SuspendPolicy SuspendPolicy `json:"suspendPolicy,omitempty"`
 }
 
 // JobSetStatus defines the observed state of JobSet
@@ -74,6 +282,39 @@ type JobSetStatus struct {
 	// Restarts tracks the number of times the JobSet has restarted (i.e. recreated in case of RecreateAll policy).
 	Restarts int `json:"restarts,omitempty"`
 
+	// RestartsCountTowardsMax is the number of restarts counting towards the maxRestarts of
+	// the failure policy. Unlike Restarts, it is reset when a failed JobSet is restarted with
+	// updated container images.
+	// +optional
+	RestartsCountTowardsMax int `json:"restartsCountTowardsMax,omitempty"`
+
+	// CompletionPercentage is the percentage of the expected completions of all child Jobs
+	// which have succeeded.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	CompletionPercentage int32 `json:"completionPercentage,omitempty"`
+
+	// Ready is the number of active child Jobs of the current run whose pods are all ready.
+	// +optional
+	Ready int32 `json:"ready,omitempty"`
+
+	// StartTime is the time the first child Jobs of the JobSet were created. The active
+	// deadline is counted from it, so time spent waiting before any child Job is created,
+	// e.g. queued or waiting for capacity, does not count.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// SuspendedDuration is the cumulative time the JobSet has spent suspended, across all
+	// suspend and resume cycles. It is updated each time the JobSet is resumed.
+	// +optional
+	SuspendedDuration *metav1.Duration `json:"suspendedDuration,omitempty"`
+
+	// TotalRequests is the sum of the resource requests of all pods the JobSet intends to
+	// run, computed from the pod templates, parallelism and replica counts.
+	// +optional
+	TotalRequests corev1.ResourceList `json:"totalRequests,omitempty"`
+
 	// ReplicatedJobsStatus track the number of JobsReady for each replicatedJob.
 	// +optional
 	// +listType=map
@@ -87,12 +328,19 @@ type ReplicatedJobStatus struct {
 	Ready     int32  `json:"ready"`
 	Succeeded int32  `json:"succeeded"`
 	Failed    int32  `json:"failed"`
+	// FirstPodNodeName is the name of the node the first pod of the ReplicatedJob, i.e. the
+	// pod with the lowest job and completion index, was scheduled on. It is meant to help
+	// debug the placement of the ReplicatedJob.
+	// +optional
+	FirstPodNodeName string `json:"firstPodNodeName,omitempty"`
 }
 
 // +genclient
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Restarts",JSONPath=".status.restarts",type=string,description="Number of restarts"
+// +kubebuilder:printcolumn:name="Ready",type="string",priority=0,JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Ready Jobs",JSONPath=".status.ready",type=integer,description="Number of ready child jobs"
 // +kubebuilder:printcolumn:name="Completed",type="string",priority=0,JSONPath=".status.conditions[?(@.type==\"Completed\")].status"
 // +kubebuilder:printcolumn:name="Age",JSONPath=".metadata.creationTimestamp",type=date,description="Time this JobSet was created"
 
@@ -117,24 +365,223 @@ type ReplicatedJob struct {
 	// for the Job name.
 	Name string `json:"name"`
 	// Template defines the template of the Job that will be created.
+	// Container images can be updated while the JobSet is failed or suspended, in which
+	// case its child Jobs are recreated with the updated images. A JobSet which failed by
+	// exceeding its active deadline is not restarted.
 	Template batchv1.JobTemplateSpec `json:"template"`
 	// Network defines the networking options for the job.
 	// +optional
 	Network *Network `json:"network,omitempty"`
 	// Replicas is the number of jobs that will be created from this ReplicatedJob's template.
-	// Jobs names will be in the format: <jobSet.name>-<spec.replicatedJob.name>-<job-index>
+	// Jobs names will be in the format: <jobSet.name>-<spec.replicatedJob.name>-<job-index>,
+	// unless customized by the jobNaming of the JobSet.
 	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
 	Replicas int `json:"replicas,omitempty"`
+	// SharedVolumes are emptyDir volumes added to the pod template of every child Job
+	// of this ReplicatedJob and mounted into all of its containers.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	SharedVolumes []SharedVolume `json:"sharedVolumes,omitempty"`
+	// CreationPriority orders the creation of child Jobs across ReplicatedJobs. The child Jobs
+	// of ReplicatedJobs with a higher priority are created first, so their pods can be
+	// scheduled ahead of lower priority ones. ReplicatedJobs with equal priority are created
+	// in the order they are listed. Defaults to 0.
+	// +optional
+	CreationPriority int32 `json:"creationPriority,omitempty"`
+	// HostNamespaces configures the host namespaces shared by all pods of this ReplicatedJob,
+	// overriding the settings of the pod template.
+	// +optional
+	HostNamespaces *HostNamespaces `json:"hostNamespaces,omitempty"`
+	// IndexNodeAffinity pins each child Job of this ReplicatedJob to a topology domain
+	// derived from its job index, e.g. to spread the child Jobs across zones.
+	// +optional
+	IndexNodeAffinity *IndexNodeAffinity `json:"indexNodeAffinity,omitempty"`
+	// ExclusivePlacement places each child Job of this ReplicatedJob in a topology domain of
+	// its own: the pods of a child Job require the same domain, and pods of other Jobs may
+	// not run in it. It takes precedence over the exclusive topology annotation of the JobSet.
+	// +optional
+	ExclusivePlacement *ExclusivePlacement `json:"exclusivePlacement,omitempty"`
+	// FailurePolicy overrides the failure policy of the JobSet for failures of the child
+	// Jobs of this ReplicatedJob, e.g. to tolerate more restarts for best-effort workers
+	// than for a critical driver. When child Jobs of several ReplicatedJobs fail, the
+	// lowest maxRestarts of their policies applies. Only maxRestarts, level and rules may
+	// be overridden, since the other settings apply to the JobSet as a whole.
+	// +optional
+	FailurePolicy *FailurePolicy `json:"failurePolicy,omitempty"`
+	// DependsOn names other ReplicatedJobs whose child Jobs must all be ready, or have
+	// succeeded, before the child Jobs of this ReplicatedJob are created, e.g. to start an
+	// MPI coordinator before its workers. Dependencies must not form a cycle.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Suspend suspends the child Jobs of this ReplicatedJob independently of the JobSet,
+	// e.g. to scale down workers while their coordinator keeps running. The child Jobs are
+	// suspended while either the JobSet or the ReplicatedJob is suspended. Unlike the rest
+	// of the ReplicatedJob, it can be updated, and the pod template node selector and
+	// tolerations may be updated while it is suspended.
+	// +optional
+	Suspend *bool `json:"suspend,omitempty"`
+}
+
+// IndexNodeAffinity pins child Jobs to topology domains by their job index.
+// The pods of the child Job with index i require nodes whose label Key has the
+// value Values[i % len(Values)].
+type IndexNodeAffinity struct {
+	// Key is the node label identifying the topology domain, e.g. topology.kubernetes.io/zone.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+	// Values are the topology domains the child Jobs are assigned to, in index order.
+	// +kubebuilder:validation:MinItems=1
+	Values []string `json:"values"`
+}
+
+// ExclusivePlacement configures the placement of child Jobs in exclusive topology domains.
+type ExclusivePlacement struct {
+	// TopologyKey is the node label identifying the topology domains, e.g.
+	// cloud.google.com/gke-nodepool.
+	// +kubebuilder:validation:MinLength=1
+	TopologyKey string `json:"topologyKey"`
+}
+
+// HostNamespaces defines which host namespaces the pods use.
+type HostNamespaces struct {
+	// IPC sets hostIPC on the pods, to use the IPC namespace of the host.
+	// +optional
+	IPC *bool `json:"ipc,omitempty"`
+	// PID sets hostPID on the pods, to use the PID namespace of the host.
+	// +optional
+	PID *bool `json:"pid,omitempty"`
+	// Network sets hostNetwork on the pods, to use the network namespace of the host.
+	// Pods on the host network take the hostname of their node, so this cannot be
+	// combined with EnableDNSHostnames.
+	// +optional
+	Network *bool `json:"network,omitempty"`
+}
+
+// SharedVolume defines an emptyDir volume shared by all containers of a pod.
+type SharedVolume struct {
+	// Name of the volume. Must not collide with a volume defined in the pod template.
+	Name string `json:"name"`
+	// MountPath is the path within the containers at which the volume is mounted.
+	MountPath string `json:"mountPath"`
+	// Medium is the storage medium backing the volume. Set to Memory to use a tmpfs,
+	// e.g. for shared memory. Defaults to the medium backing the node.
+	// +kubebuilder:validation:Enum="";Memory
+	// +optional
+	Medium corev1.StorageMedium `json:"medium,omitempty"`
+	// SizeLimit is the total amount of local storage or memory required for the volume.
+	// +optional
+	SizeLimit *resource.Quantity `json:"sizeLimit,omitempty"`
 }
 
 type Network struct {
 	// EnableDNSHostnames allows pods to be reached via their hostnames.
 	// Pods will be reachable using the fully qualified pod hostname, which is in the format:
-	// <jobSet.name>-<spec.replicatedJob.name>-<job-index>-<pod-index>.<jobSet.name>-<spec.replicatedJob.name>
+	// <jobSet.name>-<spec.replicatedJob.name>-<job-index>-<pod-index>.<subdomain>,
+	// where the subdomain defaults to <jobSet.name>
+	// Replicated jobs without a custom subdomain share the headless service of the JobSet,
+	// so they must either all enable or all disable DNS hostnames.
 	// +optional
 	EnableDNSHostnames *bool `json:"enableDNSHostnames,omitempty"`
+
+	// AnnotatePodHostnames adds the hostname of each pod qualified by its subdomain, i.e.
+	// <hostname>.<subdomain>, to the jobset.sigs.k8s.io/hostname annotation, for workloads
+	// unable to use the downward API. It resolves from pods in the namespace of the JobSet.
+	// Since pods of an indexed Job share a single template, the annotation is added by
+	// the controller once the pods are created. Requires EnableDNSHostnames.
+	// +optional
+	AnnotatePodHostnames *bool `json:"annotatePodHostnames,omitempty"`
+
+	// PublishEndpointSlice makes the controller maintain an EndpointSlice listing the addresses
+	// of the ready pods of the ReplicatedJob, for discovery by external load balancers. It is
+	// named after the custom Subdomain if set, or <jobSet.name>-<spec.replicatedJob.name>, and
+	// associated with the headless Service of the ReplicatedJob. Its address type is the IP
+	// family of the pods.
+	// +optional
+	PublishEndpointSlice *bool `json:"publishEndpointSlice,omitempty"`
+
+	// PublishNotReadyAddresses makes the headless Service publish the addresses of the pods
+	// before they are ready, so distributed jobs can resolve their peers during startup.
+	// Defaults to true. Can only be disabled with a custom Subdomain.
+	// +optional
+	PublishNotReadyAddresses *bool `json:"publishNotReadyAddresses,omitempty"`
+
+	// ServiceRestartPolicy determines whether the headless Service of the pods is reused
+	// or recreated when the JobSet restarts. Reusing the Service avoids disrupting DNS
+	// resolution during restarts. Defaults to Reuse.
+	// +kubebuilder:validation:Enum=Reuse;Recreate
+	// +kubebuilder:default=Reuse
+	// +optional
+	ServiceRestartPolicy ServiceRestartPolicy `json:"serviceRestartPolicy,omitempty"`
+
+	// ServiceSelector adds labels to the pod selector of the headless Service, to have it
+	// select a subset of the pods of the ReplicatedJob, e.g. only the pods of the first job.
+	// The selector always includes the JobSet and ReplicatedJob name labels, so the Service
+	// never selects pods outside of the ReplicatedJob. Requires EnableDNSHostnames and a
+	// custom Subdomain.
+	// +optional
+	ServiceSelector map[string]string `json:"serviceSelector,omitempty"`
+
+	// Subdomain is the name of the headless Service of the pods, set as the subdomain of
+	// their pod templates when EnableDNSHostnames is enabled. Defaults to the JobSet name,
+	// whose headless Service is shared by all replicatedJobs without a custom Subdomain.
+	// Must be a valid RFC 1123 label, unique among the replicatedJobs of the JobSet and
+	// different from the JobSet name.
+	// +optional
+	Subdomain string `json:"subdomain,omitempty"`
+
+	// DNSPolicy is set as the DNS policy of the pod templates of the child Jobs which do not
+	// set one themselves.
+	// +kubebuilder:validation:Enum=ClusterFirstWithHostNet;ClusterFirst;Default;None
+	// +optional
+	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+
+	// DNSConfig is set as the DNS parameters of the pod templates of the child Jobs which do
+	// not set them themselves.
+	// +optional
+	DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
 }
 
+// ServiceRestartPolicy defines what happens to the headless Service of a ReplicatedJob
+// when the JobSet restarts.
+type ServiceRestartPolicy string
+
+const (
+	// ServiceRestartPolicyReuse keeps the existing Service across restarts.
+	ServiceRestartPolicyReuse ServiceRestartPolicy = "Reuse"
+
+	// ServiceRestartPolicyRecreate deletes the Service on restart, so it is created
+	// again along with the child Jobs.
+	ServiceRestartPolicyRecreate ServiceRestartPolicy = "Recreate"
+)
+
+// SuspendPolicy defines what happens to the running pods of a suspended JobSet.
+type SuspendPolicy string
+
+const (
+	// SuspendPolicyDeletePods suspends the child Jobs, deleting their pods gracefully.
+	SuspendPolicyDeletePods SuspendPolicy = "DeletePods"
+
+	// SuspendPolicyKeepPods keeps the running pods of the child Jobs, lowering their
+	// parallelism to their number of running pods.
+	SuspendPolicyKeepPods SuspendPolicy = "KeepPods"
+)
+
+// WorkloadType is the type of workload a JobSet runs.
+type WorkloadType string
+
+const (
+	// WorkloadTypeTraining is a distributed training workload.
+	WorkloadTypeTraining WorkloadType = "Training"
+
+	// WorkloadTypeBatch is a batch processing workload.
+	WorkloadTypeBatch WorkloadType = "Batch"
+
+	// WorkloadTypeService is a long running workload serving requests.
+	WorkloadTypeService WorkloadType = "Service"
+)
+
 // Operator defines the target of a SuccessPolicy or FailurePolicy.
 type Operator string
 
@@ -144,22 +591,194 @@ const (
 
 	// OperatorAny applies to any single job matching the jobSelector.
 	OperatorAny Operator = "Any"
+
+	// OperatorNever never completes the JobSet from the success of its jobs, for
+	// service-like workloads. The JobSet runs until it fails, or until it is completed
+	// explicitly with the CompleteNowKey annotation.
+	OperatorNever Operator = "Never"
+)
+
+// FailureLevel defines which failures of a child Job are counted by a FailurePolicy.
+type FailureLevel string
+
+const (
+	// FailureLevelJob counts a child Job as failed once the Job itself has failed,
+	// which happens after its backoffLimit has been exhausted.
+	FailureLevelJob FailureLevel = "Job"
+
+	// FailureLevelPod counts a child Job as failed as soon as any of its pods fail,
+	// without waiting for the backoffLimit of the Job to be reached.
+	FailureLevelPod FailureLevel = "Pod"
+)
+
+// RestartStrategy defines how the child Jobs are replaced when a JobSet restarts.
+type RestartStrategy string
+
+const (
+	// RestartStrategyRecreate deletes the child Jobs of the previous attempt, and then
+	// recreates them.
+	RestartStrategyRecreate RestartStrategy = "Recreate"
+
+	// RestartStrategyBlueGreen creates the child Jobs of the new attempt first, and deletes
+	// those of the previous attempt once the new ones are ready.
+	RestartStrategyBlueGreen RestartStrategy = "BlueGreen"
 )
 
 type FailurePolicy struct {
 	// MaxRestarts defines the limit on the number of JobSet restarts.
 	// A restart is achieved by recreating all active child jobs.
 	MaxRestarts int `json:"maxRestarts,omitempty"`
+
+	// Level determines whether the failure policy is triggered by failed child Jobs
+	// or by failed pods of the child Jobs. Defaults to Job.
+	// +kubebuilder:validation:Enum=Job;Pod
+	// +optional
+	Level FailureLevel `json:"level,omitempty"`
+
+	// AggregationWindow coalesces child Job failures which occur close together into a
+	// single restart. Once a child Job of the current run fails, the failure policy waits
+	// for the window to pass from its failure before restarting, so further failures within
+	// the window do not cause additional restarts. Only failures with a known failure time,
+	// i.e. failed child Jobs, are delayed.
+	// +optional
+	AggregationWindow *metav1.Duration `json:"aggregationWindow,omitempty"`
+
+	// MaxUnavailable limits how many child Jobs of the previous attempt are torn down at
+	// once when the JobSet restarts, so the workload keeps partial capacity while it is
+	// recreated. A child Job is unavailable unless all of its pods are ready or it
+	// completed, and finished or unavailable child Jobs of the previous attempt do not
+	// count against the limit. If unset, all child Jobs of the previous attempt are
+	// deleted at once.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
+
+	// RestartStrategy determines how the child Jobs are replaced when the JobSet restarts.
+	// With Recreate, the child Jobs of the previous attempt are deleted before they are
+	// recreated. With BlueGreen, the child Jobs of the new attempt are created alongside
+	// them, with names suffixed by the restart attempt, and the previous ones are only
+	// deleted once all new child Jobs are ready. If the new attempt fails before, the child
+	// Jobs of older attempts are deleted right away, so the JobSet temporarily uses up to
+	// twice its resources. BlueGreen cannot be combined with exclusive placement or gang
+	// scheduling. Defaults to Recreate.
+	// +kubebuilder:validation:Enum=Recreate;BlueGreen
+	// +optional
+	RestartStrategy RestartStrategy `json:"restartStrategy,omitempty"`
+
+	// Rules select the action taken on a child Job failure by the exit codes of the failed
+	// containers of its pods, e.g. to restart on retriable infrastructure failures but fail
+	// immediately on application errors. The first rule matching an exit code applies.
+	// Failures not matched by any rule restart the JobSet within maxRestarts.
+	// +optional
+	Rules []FailurePolicyRule `json:"rules,omitempty"`
+}
+
+// FailurePolicyAction is the action taken on a child Job failure matched by a FailurePolicyRule.
+type FailurePolicyAction string
+
+const (
+	// FailurePolicyActionRestartJobSet restarts the JobSet, unless it has reached maxRestarts.
+	FailurePolicyActionRestartJobSet FailurePolicyAction = "RestartJobSet"
+
+	// FailurePolicyActionFailJobSet fails the JobSet immediately, without restarting it.
+	FailurePolicyActionFailJobSet FailurePolicyAction = "FailJobSet"
+
+	// FailurePolicyActionIgnore disregards a pod failure, so the child Job keeps retrying
+	// its pods until it reaches its backoffLimit. It can only be used with the Pod failure
+	// level, as a child Job which failed as a whole cannot be resumed.
+	FailurePolicyActionIgnore FailurePolicyAction = "Ignore"
+)
+
+// FailurePolicyRule matches child Job failures by the exit codes of their failed containers.
+type FailurePolicyRule struct {
+	// Action is the action taken when the rule matches.
+	// +kubebuilder:validation:Enum=RestartJobSet;FailJobSet;Ignore
+	Action FailurePolicyAction `json:"action"`
+
+	// OnExitCodes are the container exit codes matched by the rule, between 1 and 255.
+	// +kubebuilder:validation:MinItems=1
+	OnExitCodes []int32 `json:"onExitCodes"`
+}
+
+// Coordinator identifies a pod of the JobSet by the replicatedJob and Job index running it.
+type Coordinator struct {
+	// ReplicatedJob is the name of the replicatedJob running the coordinator.
+	ReplicatedJob string `json:"replicatedJob"`
+
+	// JobIndex is the index of the Job running the coordinator, whose pod with
+	// completion index 0 is the coordinator. Defaults to 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	JobIndex int `json:"jobIndex,omitempty"`
+}
+
+// JobDefaults are the defaults applied to the templates of all replicatedJobs.
+type JobDefaults struct {
+	// Completions is the default completions of the child Jobs. Must be positive.
+	// +optional
+	Completions *int32 `json:"completions,omitempty"`
+
+	// Parallelism is the default parallelism of the child Jobs. Must be positive.
+	// +optional
+	Parallelism *int32 `json:"parallelism,omitempty"`
+}
+
+// JobNaming customizes the names of the child Jobs of a JobSet. The names follow the format
+// <prefix><jobSet.name><separator><replicatedJob.name><separator><job-index><suffix>, and must
+// be valid DNS labels. With the BlueGreen restart strategy, the restart attempt is appended as
+// <separator>r<attempt>.
+type JobNaming struct {
+	// Prefix is prepended to the names of the child Jobs.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Suffix is appended to the names of the child Jobs.
+	// +optional
+	Suffix string `json:"suffix,omitempty"`
+
+	// Separator joins the parts of the names of the child Jobs, and the names of the JobSet
+	// and the ReplicatedJob in the names of the EndpointSlices of the ReplicatedJobs without
+	// a custom subdomain. It may only contain lowercase alphanumeric characters or '-'.
+	// The hostnames of the pods are the names of their Jobs joined with the pod index by a
+	// '-', as set by the Job controller. Defaults to '-'.
+	// +optional
+	Separator string `json:"separator,omitempty"`
+}
+
+// SchedulingPolicy configures how the pods of a JobSet are scheduled.
+type SchedulingPolicy struct {
+	// Gang, if set, requests that all pods of the JobSet are scheduled together or not at
+	// all. All child pods are annotated with a shared pod group name and the number of pods
+	// of the group, for gang schedulers such as Volcano or the Coscheduling plugin to pick
+	// up. Requires the completions and parallelism of all replicatedJobs to be set.
+	// +optional
+	Gang *GangPolicy `json:"gang,omitempty"`
+}
+
+// GangPolicy configures the pod group of a gang scheduled JobSet.
+type GangPolicy struct {
+	// PodGroupName is the name of the pod group of the JobSet. Defaults to the name of
+	// the JobSet.
+	// +optional
+	PodGroupName string `json:"podGroupName,omitempty"`
 }
 
 type SuccessPolicy struct {
-	// Operator determines either All or Any of the selected jobs should succeed to consider the JobSet successful
-	// +kubebuilder:validation:Enum=All;Any
+	// Operator determines either All or Any of the selected jobs should succeed to consider the JobSet successful.
+	// With Never, the JobSet is not completed by its jobs and TargetReplicatedJobs must be empty.
+	// +kubebuilder:validation:Enum=All;Any;Never
 	Operator Operator `json:"operator"`
 
 	// TargetReplicatedJobs are the names of the replicated jobs the operator will apply to.
 	// A null or empty list will apply to all replicatedJobs.
 	TargetReplicatedJobs []string `json:"targetReplicatedJobs,omitempty"`
+
+	// MinSucceeded, if set, is the number of child Jobs of a targeted replicatedJob which must
+	// succeed for the JobSet to succeed, e.g. to finish once K of N workers completed. The
+	// JobSet succeeds once any targeted replicatedJob has that many succeeded Jobs. Requires
+	// the Any operator, and must not exceed the replicas of any targeted replicatedJob.
+	// +optional
+	MinSucceeded *int32 `json:"minSucceeded,omitempty"`
 }
 
 func init() {
@@ -14,54 +14,133 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 	util "sigs.k8s.io/jobset/pkg/util/collections"
 
+	admissionv1 "k8s.io/api/admission/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 )
 
+// DefaultEnableDNSHostnames is the value the defaulting webhook sets for
+// Network.EnableDNSHostnames on replicated jobs that leave it unset.
+var DefaultEnableDNSHostnames = true
+
+// PodFailurePolicyEnabled reports whether the cluster has the JobPodFailurePolicy feature
+// gate enabled. If not, the validating webhook rejects job templates setting a
+// podFailurePolicy, which the API server would otherwise drop from the child jobs.
+var PodFailurePolicyEnabled = true
+
+// MaxTotalPods is the maximum number of pods a JobSet may run at once, i.e. the sum over its
+// replicatedJobs of replicas times parallelism. The validating webhook rejects JobSets
+// exceeding it, to protect the scheduler from accidentally huge JobSets. Zero disables it.
+var MaxTotalPods = 50000
+
+// MaxReplicatedJobs is the maximum number of replicatedJobs a JobSet may define. The
+// validating webhook rejects JobSets exceeding it, since the controller and API server
+// handle the child jobs and services of each of them. Zero disables it.
+var MaxReplicatedJobs = 100
+
+// RecordDefaultingEvents makes the defaulting webhook record an event on each created
+// JobSet listing the fields it set because they were omitted from the spec.
+var RecordDefaultingEvents = false
+
 func (js *JobSet) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	defaulter := &jobSetDefaulter{}
+	if RecordDefaultingEvents {
+		defaulter.recorder = mgr.GetEventRecorderFor("jobset-webhook")
+	}
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(js).
+		WithDefaulter(defaulter).
 		Complete()
 }
 
-//+kubebuilder:webhook:path=/mutate-jobset-x-k8s-io-v1alpha1-jobset,mutating=true,failurePolicy=fail,sideEffects=None,groups=jobset.x-k8s.io,resources=jobsets,verbs=create;update,versions=v1alpha1,name=mjobset.kb.io,admissionReviewVersions=v1
+// jobSetDefaulter defaults JobSets, and reports the defaults applied to newly created ones in
+// an event if it has a recorder. Dry runs record no events, since they must have no side
+// effects.
+type jobSetDefaulter struct {
+	recorder record.EventRecorder
+}
+
+var _ admission.CustomDefaulter = &jobSetDefaulter{}
+
+// Default implements admission.CustomDefaulter.
+func (d *jobSetDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	js, ok := obj.(*JobSet)
+	if !ok {
+		return fmt.Errorf("expected a JobSet but got a %T", obj)
+	}
+	defaulted := js.applyDefaults()
+	if d.recorder == nil || len(defaulted) == 0 {
+		return nil
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if req.Operation != admissionv1.Create || pointer.BoolDeref(req.DryRun, false) {
+		return nil
+	}
+	d.recorder.Eventf(js, corev1.EventTypeWarning, "DefaultsApplied", "defaulted fields omitted from the spec: %s", strings.Join(defaulted, ", "))
+	return nil
+}
+
+//+kubebuilder:webhook:path=/mutate-jobset-x-k8s-io-v1alpha1-jobset,mutating=true,failurePolicy=fail,sideEffects=NoneOnDryRun,groups=jobset.x-k8s.io,resources=jobsets,verbs=create;update,versions=v1alpha1,name=mjobset.kb.io,admissionReviewVersions=v1
 
 var _ webhook.Defaulter = &JobSet{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
 func (js *JobSet) Default() {
+	js.applyDefaults()
+}
+
+// applyDefaults sets the defaults of the fields omitted from the spec, and returns the paths
+// of the fields it set.
+func (js *JobSet) applyDefaults() []string {
+	var defaulted []string
 	// Default success policy to operator "All" targeting all replicatedJobs.
 	if js.Spec.SuccessPolicy == nil {
 		js.Spec.SuccessPolicy = &SuccessPolicy{Operator: OperatorAll}
+		defaulted = append(defaulted, "spec.successPolicy")
 	}
 	for i, _ := range js.Spec.ReplicatedJobs {
+		path := fmt.Sprintf("spec.replicatedJobs[%s]", js.Spec.ReplicatedJobs[i].Name)
 		// Default job completion mode to indexed.
 		if js.Spec.ReplicatedJobs[i].Template.Spec.CompletionMode == nil {
 			js.Spec.ReplicatedJobs[i].Template.Spec.CompletionMode = completionModePtr(batchv1.IndexedCompletion)
+			defaulted = append(defaulted, path+".template.spec.completionMode")
 		}
-		// Enable DNS hostnames by default.
+		// Default DNS hostnames to DefaultEnableDNSHostnames.
 		if js.Spec.ReplicatedJobs[i].Network == nil {
 			js.Spec.ReplicatedJobs[i].Network = &Network{}
 		}
 		if js.Spec.ReplicatedJobs[i].Network.EnableDNSHostnames == nil {
-			js.Spec.ReplicatedJobs[i].Network.EnableDNSHostnames = pointer.Bool(true)
+			js.Spec.ReplicatedJobs[i].Network.EnableDNSHostnames = pointer.Bool(DefaultEnableDNSHostnames)
+			defaulted = append(defaulted, path+".network.enableDNSHostnames")
 		}
 		// Default pod restart policy to OnFailure.
 		if js.Spec.ReplicatedJobs[i].Template.Spec.Template.Spec.RestartPolicy == "" {
 			js.Spec.ReplicatedJobs[i].Template.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
+			defaulted = append(defaulted, path+".template.spec.template.spec.restartPolicy")
 		}
 	}
+	return defaulted
 }
 
 //+kubebuilder:webhook:path=/validate-jobset-x-k8s-io-v1alpha1-jobset,mutating=false,failurePolicy=fail,sideEffects=None,groups=jobset.x-k8s.io,resources=jobsets,verbs=create;update,versions=v1alpha1,name=vjobset.kb.io,admissionReviewVersions=v1
@@ -71,11 +150,357 @@ var _ webhook.Validator = &JobSet{}
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (js *JobSet) ValidateCreate() error {
 	var allErrs []error
-	// Validate that replicatedJobs listed in success policy are part of this JobSet.
-	validReplicatedJobs := replicatedJobNamesFromSpec(js)
-	for _, rjobName := range js.Spec.SuccessPolicy.TargetReplicatedJobs {
-		if !util.Contains(validReplicatedJobs, rjobName) {
-			allErrs = append(allErrs, fmt.Errorf("invalid replicatedJob name '%s' does not appear in .spec.ReplicatedJobs", rjobName))
+	// Validate the JobSet name is a valid DNS subdomain, since it is used to generate
+	// the names of the child jobs and services.
+	for _, errMessage := range validation.IsDNS1123Subdomain(js.Name) {
+		allErrs = append(allErrs, fmt.Errorf("invalid JobSet name '%s': %s", js.Name, errMessage))
+	}
+	// Validate that the JobSet can reach a terminal state. Defaulting always sets a success
+	// policy, which requires at least one targeted job to run to completion.
+	if len(js.Spec.ReplicatedJobs) == 0 {
+		allErrs = append(allErrs, fmt.Errorf("at least one replicatedJob must be specified"))
+	}
+	if MaxReplicatedJobs > 0 && len(js.Spec.ReplicatedJobs) > MaxReplicatedJobs {
+		allErrs = append(allErrs, fmt.Errorf("invalid JobSet: it defines %d replicatedJobs, which exceeds the maximum of %d", len(js.Spec.ReplicatedJobs), MaxReplicatedJobs))
+	}
+	if js.Spec.SuccessPolicy == nil {
+		allErrs = append(allErrs, fmt.Errorf("successPolicy must be set"))
+	} else {
+		// Validate that the success policy operator is known, since any other value would
+		// make the JobSet complete before any of its jobs succeeded.
+		switch op := js.Spec.SuccessPolicy.Operator; op {
+		case OperatorAll, OperatorAny:
+		case OperatorNever:
+			if len(js.Spec.SuccessPolicy.TargetReplicatedJobs) > 0 {
+				allErrs = append(allErrs, fmt.Errorf("successPolicy with operator %s must not set targetReplicatedJobs", OperatorNever))
+			}
+		default:
+			allErrs = append(allErrs, fmt.Errorf("invalid successPolicy operator '%s': must be one of %s, %s or %s", op, OperatorAll, OperatorAny, OperatorNever))
+		}
+		// Validate that replicatedJobs listed in success policy are part of this JobSet.
+		validReplicatedJobs := replicatedJobNamesFromSpec(js)
+		for _, rjobName := range js.Spec.SuccessPolicy.TargetReplicatedJobs {
+			if !util.Contains(validReplicatedJobs, rjobName) {
+				allErrs = append(allErrs, fmt.Errorf("invalid replicatedJob name '%s' does not appear in .spec.ReplicatedJobs", rjobName))
+			}
+		}
+		allErrs = append(allErrs, validateMinSucceeded(js)...)
+	}
+	// Validate that the failure policy does not allow a negative number of restarts.
+	if js.Spec.FailurePolicy != nil && js.Spec.FailurePolicy.MaxRestarts < 0 {
+		allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy.maxRestarts: %d must not be negative", js.Spec.FailurePolicy.MaxRestarts))
+	}
+	if js.Spec.FailurePolicy != nil {
+		allErrs = append(allErrs, validateFailurePolicyRules(js.Spec.FailurePolicy, "")...)
+	}
+	// Validate that maxUnavailable is not set with the BlueGreen restart strategy, which
+	// keeps the child Jobs of the previous attempt until the new ones are ready.
+	if js.Spec.FailurePolicy != nil && js.Spec.FailurePolicy.RestartStrategy == RestartStrategyBlueGreen && js.Spec.FailurePolicy.MaxUnavailable != nil {
+		allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy: maxUnavailable cannot be set with the %s restart strategy", RestartStrategyBlueGreen))
+	}
+	allErrs = append(allErrs, validateBlueGreenPlacement(js)...)
+	if err := validateTTLSecondsAfterFinished(js); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateActiveDeadlineSeconds(js); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	// Validate that dependencies name other replicatedJobs of this JobSet, without cycles.
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		for _, dep := range rjob.DependsOn {
+			if dep == rjob.Name {
+				allErrs = append(allErrs, fmt.Errorf("invalid dependsOn for replicatedJob '%s': it cannot depend on itself", rjob.Name))
+			} else if !util.Contains(replicatedJobNamesFromSpec(js), dep) {
+				allErrs = append(allErrs, fmt.Errorf("invalid dependsOn for replicatedJob '%s': replicatedJob '%s' does not appear in .spec.ReplicatedJobs", rjob.Name, dep))
+			}
+		}
+	}
+	if cycle := dependencyCycle(js); len(cycle) > 0 {
+		allErrs = append(allErrs, fmt.Errorf("invalid dependsOn: replicatedJobs depend on each other in a cycle: %s", strings.Join(cycle, " -> ")))
+	}
+	// Validate that failure policy overrides of replicatedJobs only set the settings which
+	// can differ between replicatedJobs.
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if rjob.FailurePolicy == nil {
+			continue
+		}
+		if rjob.FailurePolicy.MaxRestarts < 0 {
+			allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy.maxRestarts for replicatedJob '%s': %d must not be negative", rjob.Name, rjob.FailurePolicy.MaxRestarts))
+		}
+		if rjob.FailurePolicy.AggregationWindow != nil {
+			allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy for replicatedJob '%s': aggregationWindow can only be set on the failurePolicy of the JobSet", rjob.Name))
+		}
+		if rjob.FailurePolicy.MaxUnavailable != nil {
+			allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy for replicatedJob '%s': maxUnavailable can only be set on the failurePolicy of the JobSet", rjob.Name))
+		}
+		if rjob.FailurePolicy.RestartStrategy != "" {
+			allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy for replicatedJob '%s': restartStrategy can only be set on the failurePolicy of the JobSet", rjob.Name))
+		}
+		allErrs = append(allErrs, validateFailurePolicyRules(rjob.FailurePolicy, fmt.Sprintf(" for replicatedJob '%s'", rjob.Name))...)
+	}
+	allErrs = append(allErrs, validateParallelismOverrides(js)...)
+	allErrs = append(allErrs, validateJobDefaults(js)...)
+	if err := validateTotalPods(js); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	allErrs = append(allErrs, validateGangPolicy(js)...)
+	// Validate that pod failure policies, which are passed through to the child jobs as is,
+	// are only set when the cluster supports them.
+	if !PodFailurePolicyEnabled {
+		for _, rjob := range js.Spec.ReplicatedJobs {
+			if rjob.Template.Spec.PodFailurePolicy != nil {
+				allErrs = append(allErrs, fmt.Errorf("invalid podFailurePolicy for replicatedJob '%s': the JobPodFailurePolicy feature gate is not enabled in the cluster", rjob.Name))
+			}
+		}
+	}
+	// Validate that the scheduler name is a valid DNS subdomain, as required for pod specs.
+	if js.Spec.SchedulerName != "" {
+		for _, errMessage := range validation.IsDNS1123Subdomain(js.Spec.SchedulerName) {
+			allErrs = append(allErrs, fmt.Errorf("invalid schedulerName '%s': %s", js.Spec.SchedulerName, errMessage))
+		}
+	}
+	// Validate that the workload type is one of the known types.
+	switch js.Spec.WorkloadType {
+	case "", WorkloadTypeTraining, WorkloadTypeBatch, WorkloadTypeService:
+	default:
+		allErrs = append(allErrs, fmt.Errorf("invalid workloadType '%s': must be one of %s, %s or %s", js.Spec.WorkloadType, WorkloadTypeTraining, WorkloadTypeBatch, WorkloadTypeService))
+	}
+	// Validate that the suspend policy is one of the known policies.
+	switch js.Spec.SuspendPolicy {
+	case "", SuspendPolicyDeletePods, SuspendPolicyKeepPods:
+	default:
+		allErrs = append(allErrs, fmt.Errorf("invalid suspendPolicy '%s': must be one of %s or %s", js.Spec.SuspendPolicy, SuspendPolicyDeletePods, SuspendPolicyKeepPods))
+	}
+	// Validate that the total pods environment variable has a valid name.
+	if js.Spec.TotalPodsEnvName != "" {
+		for _, errMessage := range validation.IsEnvVarName(js.Spec.TotalPodsEnvName) {
+			allErrs = append(allErrs, fmt.Errorf("invalid totalPodsEnvName '%s': %s", js.Spec.TotalPodsEnvName, errMessage))
+		}
+	}
+	// Validate that pod annotations are valid and do not use keys reserved for JobSet.
+	if len(js.Spec.PodAnnotations) > 0 {
+		if err := apivalidation.ValidateAnnotations(js.Spec.PodAnnotations, field.NewPath("spec", "podAnnotations")).ToAggregate(); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		for key := range js.Spec.PodAnnotations {
+			if reservedAnnotationKey(key) {
+				allErrs = append(allErrs, fmt.Errorf("invalid pod annotation '%s': keys under the %s domain are reserved", key, reservedDomain))
+			}
+		}
+	}
+	// Validate that propagated labels and annotations are valid keys not reserved for JobSet.
+	for _, propagated := range []struct {
+		kind string
+		keys []string
+	}{{"label", js.Spec.PropagatedLabels}, {"annotation", js.Spec.PropagatedAnnotations}} {
+		for _, key := range propagated.keys {
+			for _, errMessage := range validation.IsQualifiedName(key) {
+				allErrs = append(allErrs, fmt.Errorf("invalid propagated %s '%s': %s", propagated.kind, key, errMessage))
+			}
+			if reservedAnnotationKey(key) {
+				allErrs = append(allErrs, fmt.Errorf("invalid propagated %s '%s': keys under the %s domain are reserved", propagated.kind, key, reservedDomain))
+			}
+		}
+	}
+	// Validate that the coordinator is a pod of the JobSet with a stable DNS hostname.
+	if coordinator := js.Spec.Coordinator; coordinator != nil {
+		var rjob *ReplicatedJob
+		for i := range js.Spec.ReplicatedJobs {
+			if js.Spec.ReplicatedJobs[i].Name == coordinator.ReplicatedJob {
+				rjob = &js.Spec.ReplicatedJobs[i]
+			}
+		}
+		switch {
+		case rjob == nil:
+			allErrs = append(allErrs, fmt.Errorf("invalid coordinator: replicatedJob '%s' does not appear in .spec.ReplicatedJobs", coordinator.ReplicatedJob))
+		case rjob.Network == nil || !pointer.BoolDeref(rjob.Network.EnableDNSHostnames, false):
+			allErrs = append(allErrs, fmt.Errorf("invalid coordinator: replicatedJob '%s' must enable DNS hostnames for the coordinator to be resolvable", coordinator.ReplicatedJob))
+		// The coordinator is identified by its pod index, which only indexed jobs have.
+		case rjob.Template.Spec.CompletionMode != nil && *rjob.Template.Spec.CompletionMode != batchv1.IndexedCompletion:
+			allErrs = append(allErrs, fmt.Errorf("invalid coordinator: replicatedJob '%s' must use the %s completion mode for the coordinator to have a stable pod index", coordinator.ReplicatedJob, batchv1.IndexedCompletion))
+		case coordinator.JobIndex < 0 || coordinator.JobIndex >= rjob.Replicas:
+			allErrs = append(allErrs, fmt.Errorf("invalid coordinator: jobIndex %d is out of range for replicatedJob '%s' with %d replicas", coordinator.JobIndex, coordinator.ReplicatedJob, rjob.Replicas))
+		}
+	}
+	// Validate that every replicatedJob creates at least one job, and that the names of all
+	// jobs created from the replicatedJob fit in the job-name label of their pods.
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if rjob.Replicas < 1 {
+			allErrs = append(allErrs, fmt.Errorf("invalid replicas for replicatedJob '%s': %d must be at least 1", rjob.Name, rjob.Replicas))
+			continue
+		}
+		lastJobName := lastJobName(js, &rjob, 0)
+		if len(lastJobName) > validation.DNS1123LabelMaxLength {
+			allErrs = append(allErrs, fmt.Errorf("replicatedJob '%s' would create jobs with names longer than %d characters, such as '%s'", rjob.Name, validation.DNS1123LabelMaxLength, lastJobName))
+		}
+	}
+	allErrs = append(allErrs, validateJobNaming(js)...)
+	// Validate that index node affinities use valid node label keys and values.
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if rjob.IndexNodeAffinity == nil {
+			continue
+		}
+		for _, errMessage := range validation.IsQualifiedName(rjob.IndexNodeAffinity.Key) {
+			allErrs = append(allErrs, fmt.Errorf("invalid indexNodeAffinity key for replicatedJob '%s': %s", rjob.Name, errMessage))
+		}
+		if len(rjob.IndexNodeAffinity.Values) == 0 {
+			allErrs = append(allErrs, fmt.Errorf("invalid indexNodeAffinity for replicatedJob '%s': at least one value is required", rjob.Name))
+		}
+		for _, value := range rjob.IndexNodeAffinity.Values {
+			for _, errMessage := range validation.IsValidLabelValue(value) {
+				allErrs = append(allErrs, fmt.Errorf("invalid indexNodeAffinity value '%s' for replicatedJob '%s': %s", value, rjob.Name, errMessage))
+			}
+		}
+	}
+	// Validate that exclusive placements use a valid node label key.
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if rjob.ExclusivePlacement == nil {
+			continue
+		}
+		for _, errMessage := range validation.IsQualifiedName(rjob.ExclusivePlacement.TopologyKey) {
+			allErrs = append(allErrs, fmt.Errorf("invalid exclusivePlacement topologyKey for replicatedJob '%s': %s", rjob.Name, errMessage))
+		}
+	}
+	// Validate that child job deadlines are positive, so invalid templates are rejected
+	// up front instead of failing every job creation.
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if deadline := rjob.Template.Spec.ActiveDeadlineSeconds; deadline != nil && *deadline <= 0 {
+			allErrs = append(allErrs, fmt.Errorf("invalid activeDeadlineSeconds for replicatedJob '%s': %d must be positive", rjob.Name, *deadline))
+		}
+	}
+	// Validate that pod hostnames are only annotated when pods have stable DNS hostnames.
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if rjob.Network != nil && pointer.BoolDeref(rjob.Network.AnnotatePodHostnames, false) && !pointer.BoolDeref(rjob.Network.EnableDNSHostnames, false) {
+			allErrs = append(allErrs, fmt.Errorf("replicatedJob '%s' sets annotatePodHostnames, which requires enableDNSHostnames", rjob.Name))
+		}
+	}
+	// Validate that pods on the host network are not expected to have stable DNS hostnames.
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if rjob.HostNamespaces != nil && pointer.BoolDeref(rjob.HostNamespaces.Network, false) &&
+			rjob.Network != nil && pointer.BoolDeref(rjob.Network.EnableDNSHostnames, false) {
+			allErrs = append(allErrs, fmt.Errorf("replicatedJob '%s' uses the host network, which cannot be combined with enableDNSHostnames", rjob.Name))
+		}
+	}
+	// Validate that custom subdomains are valid service names, used by a single replicatedJob
+	// and distinct from the default subdomain, i.e. the JobSet name, whose service is shared.
+	// The shared service selects all pods of the JobSet, so the replicatedJobs on the default
+	// subdomain must agree on whether their pods have DNS hostnames.
+	subdomains := map[string]string{}
+	defaultSubdomain := false
+	var withDNSHostnames, withoutDNSHostnames string
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if rjob.Network == nil || rjob.Network.Subdomain == "" {
+			if rjob.Network != nil && pointer.BoolDeref(rjob.Network.EnableDNSHostnames, false) {
+				defaultSubdomain = true
+				if withDNSHostnames == "" {
+					withDNSHostnames = rjob.Name
+				}
+			} else if withoutDNSHostnames == "" {
+				withoutDNSHostnames = rjob.Name
+			}
+			if rjob.Network != nil && len(rjob.Network.ServiceSelector) > 0 {
+				allErrs = append(allErrs, fmt.Errorf("invalid serviceSelector for replicatedJob '%s': requires a custom subdomain, since the default headless service is shared by the replicatedJobs", rjob.Name))
+			}
+			if rjob.Network != nil && !pointer.BoolDeref(rjob.Network.PublishNotReadyAddresses, true) {
+				allErrs = append(allErrs, fmt.Errorf("invalid publishNotReadyAddresses for replicatedJob '%s': can only be disabled with a custom subdomain, since the default headless service is shared by the replicatedJobs", rjob.Name))
+			}
+			continue
+		}
+		for _, errMessage := range validation.IsDNS1123Label(rjob.Network.Subdomain) {
+			allErrs = append(allErrs, fmt.Errorf("invalid subdomain '%s' for replicatedJob '%s': %s", rjob.Network.Subdomain, rjob.Name, errMessage))
+		}
+		if rjob.Network.Subdomain == js.Name {
+			allErrs = append(allErrs, fmt.Errorf("invalid subdomain '%s' for replicatedJob '%s': the JobSet name is the default subdomain", rjob.Network.Subdomain, rjob.Name))
+		}
+		if other, ok := subdomains[rjob.Network.Subdomain]; ok {
+			allErrs = append(allErrs, fmt.Errorf("invalid subdomain '%s' for replicatedJob '%s': already used by replicatedJob '%s'", rjob.Network.Subdomain, rjob.Name, other))
+		}
+		subdomains[rjob.Network.Subdomain] = rjob.Name
+	}
+	if withDNSHostnames != "" && withoutDNSHostnames != "" {
+		allErrs = append(allErrs, fmt.Errorf("invalid enableDNSHostnames for replicatedJob '%s': disabled, but replicatedJob '%s' enables it on the default subdomain, whose headless service selects the pods of both", withoutDNSHostnames, withDNSHostnames))
+	}
+	if defaultSubdomain {
+		for _, errMessage := range validation.IsDNS1123Label(js.Name) {
+			allErrs = append(allErrs, fmt.Errorf("invalid JobSet name '%s': it is the default subdomain of the pods with DNS hostnames, %s", js.Name, errMessage))
+		}
+	}
+	// Validate that custom Service selectors are valid labels narrowing the default selector
+	// to pods the replicatedJob can actually create.
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if rjob.Network == nil || len(rjob.Network.ServiceSelector) == 0 {
+			continue
+		}
+		if !pointer.BoolDeref(rjob.Network.EnableDNSHostnames, false) {
+			allErrs = append(allErrs, fmt.Errorf("invalid serviceSelector for replicatedJob '%s': requires enableDNSHostnames", rjob.Name))
+		}
+		keys := make([]string, 0, len(rjob.Network.ServiceSelector))
+		for key := range rjob.Network.ServiceSelector {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		podLabels := rjob.Template.Spec.Template.Labels
+		for _, key := range keys {
+			value := rjob.Network.ServiceSelector[key]
+			for _, errMessage := range validation.IsQualifiedName(key) {
+				allErrs = append(allErrs, fmt.Errorf("invalid serviceSelector key '%s' for replicatedJob '%s': %s", key, rjob.Name, errMessage))
+			}
+			for _, errMessage := range validation.IsValidLabelValue(value) {
+				allErrs = append(allErrs, fmt.Errorf("invalid serviceSelector value '%s' for replicatedJob '%s': %s", value, rjob.Name, errMessage))
+			}
+			if key == JobSetNameKey || key == ReplicatedJobNameKey {
+				allErrs = append(allErrs, fmt.Errorf("invalid serviceSelector key '%s' for replicatedJob '%s': the label is always selected by the Service", key, rjob.Name))
+			} else if templateValue, ok := podLabels[key]; ok && templateValue != value {
+				allErrs = append(allErrs, fmt.Errorf("invalid serviceSelector for replicatedJob '%s': selects %s=%s, but its pod template sets %s=%s, so no pods would be selected", rjob.Name, key, value, key, templateValue))
+			}
+		}
+	}
+	// Validate that pod templates leave the hostname and subdomain to the controller when
+	// it manages DNS hostnames, since the injected values would silently override them.
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if rjob.Network == nil || !pointer.BoolDeref(rjob.Network.EnableDNSHostnames, false) {
+			continue
+		}
+		podSpec := rjob.Template.Spec.Template.Spec
+		if podSpec.Subdomain != "" {
+			allErrs = append(allErrs, fmt.Errorf("replicatedJob '%s' sets subdomain '%s' on its pod template, which conflicts with enableDNSHostnames", rjob.Name, podSpec.Subdomain))
+		}
+		if podSpec.Hostname != "" {
+			allErrs = append(allErrs, fmt.Errorf("replicatedJob '%s' sets hostname '%s' on its pod template, which conflicts with enableDNSHostnames", rjob.Name, podSpec.Hostname))
+		}
+	}
+	allErrs = append(allErrs, validateManagedServiceAccount(js)...)
+	// Validate that container names are unique within each pod template, across both
+	// containers and init containers, otherwise every child job would fail to create.
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		podSpec := rjob.Template.Spec.Template.Spec
+		containerNames := map[string]bool{}
+		for _, container := range append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...) {
+			if containerNames[container.Name] {
+				allErrs = append(allErrs, fmt.Errorf("replicatedJob '%s' has more than one container named '%s' in its pod template", rjob.Name, container.Name))
+			}
+			containerNames[container.Name] = true
+		}
+	}
+	// Validate that shared volumes do not collide with the volumes of the pod template. The
+	// shared volumes of the JobSet are added to every replicated job, so they must not collide
+	// with the volumes of any of them.
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		volumeNames := []string{}
+		for _, volume := range rjob.Template.Spec.Template.Spec.Volumes {
+			volumeNames = append(volumeNames, volume.Name)
+		}
+		for _, sv := range js.Spec.SharedVolumes {
+			if util.Contains(volumeNames, sv.Name) {
+				allErrs = append(allErrs, fmt.Errorf("shared volume '%s' of the JobSet collides with a volume of replicatedJob '%s'", sv.Name, rjob.Name))
+			}
+			volumeNames = append(volumeNames, sv.Name)
+		}
+		for _, sv := range rjob.SharedVolumes {
+			if util.Contains(volumeNames, sv.Name) {
+				allErrs = append(allErrs, fmt.Errorf("shared volume '%s' of replicatedJob '%s' collides with a volume of the same name", sv.Name, rjob.Name))
+			}
+			volumeNames = append(volumeNames, sv.Name)
 		}
 	}
 	return errors.Join(allErrs...)
@@ -85,13 +510,138 @@ func (js *JobSet) ValidateCreate() error {
 func (js *JobSet) ValidateUpdate(old runtime.Object) error {
 	mungedSpec := js.Spec.DeepCopy()
 	oldSpec := old.(*JobSet).Spec
+	oldStatus := old.(*JobSet).Status
+	oldFailed := meta.IsStatusConditionTrue(oldStatus.Conditions, string(JobSetFailed))
+	var allErrs []error
+	// Annotations are mutable, so exclusive placement may be requested after creation.
+	if _, ok := old.(*JobSet).Annotations[ExclusiveKey]; !ok {
+		allErrs = append(allErrs, validateBlueGreenPlacement(js)...)
+	}
+	// So may the managed service account.
+	allErrs = append(allErrs, validateManagedServiceAccount(js)...)
+	// Replicated jobs can be removed while the JobSet is suspended. The child jobs of the
+	// removed replicated jobs are deleted when the JobSet is resumed.
+	oldReplicatedJobs := oldSpec.ReplicatedJobs
 	if pointer.BoolDeref(oldSpec.Suspend, false) {
-		for index := range js.Spec.ReplicatedJobs {
-			mungedSpec.ReplicatedJobs[index].Template.Spec.Template.Spec.NodeSelector = oldSpec.ReplicatedJobs[index].Template.Spec.Template.Spec.NodeSelector
+		var removed []string
+		oldReplicatedJobs, removed = remainingReplicatedJobs(oldSpec.ReplicatedJobs, replicatedJobNamesFromSpec(js))
+		allErrs = append(allErrs, validateRemovedReplicatedJobs(js, removed)...)
+	}
+	for index := range mungedSpec.ReplicatedJobs {
+		if index >= len(oldReplicatedJobs) {
+			break
+		}
+		oldRJob := oldReplicatedJobs[index]
+		// Replicated jobs can be suspended and resumed independently of the JobSet.
+		mungedSpec.ReplicatedJobs[index].Suspend = oldRJob.Suspend
+		// Node selectors and tolerations can be updated while the child jobs are suspended,
+		// either by the JobSet or by their replicated job.
+		if pointer.BoolDeref(oldSpec.Suspend, false) || pointer.BoolDeref(oldRJob.Suspend, false) {
+			mungedSpec.ReplicatedJobs[index].Template.Spec.Template.Spec.NodeSelector = oldRJob.Template.Spec.Template.Spec.NodeSelector
+			mungedSpec.ReplicatedJobs[index].Template.Spec.Template.Spec.Tolerations = oldRJob.Template.Spec.Template.Spec.Tolerations
+		}
+		// Container images can be updated while the JobSet is failed or suspended, e.g. to fix
+		// a bad image. The child jobs are recreated with the updated images.
+		if oldFailed || pointer.BoolDeref(oldSpec.Suspend, false) {
+			mungeContainerImages(&mungedSpec.ReplicatedJobs[index].Template.Spec.Template.Spec, &oldRJob.Template.Spec.Template.Spec)
+		}
+		// Restarting a failed JobSet with updated images resets the restarts counting towards
+		// maxRestarts, so the jobs of its later attempts must still have valid names.
+		rjob := &js.Spec.ReplicatedJobs[index]
+		if oldFailed && containerImagesChanged(&rjob.Template.Spec.Template.Spec, &oldRJob.Template.Spec.Template.Spec) {
+			if name := lastJobName(js, rjob, oldStatus.Restarts+1); rjob.Replicas > 0 && len(name) > validation.DNS1123LabelMaxLength {
+				allErrs = append(allErrs, fmt.Errorf("replicatedJob '%s' cannot be restarted with updated images: it would create jobs with names longer than %d characters, such as '%s'", rjob.Name, validation.DNS1123LabelMaxLength, name))
+			}
+		}
+	}
+	// The TTL after finishing is mutable, so it is validated on updates as well.
+	if err := validateTTLSecondsAfterFinished(js); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	// So is the active deadline.
+	if err := validateActiveDeadlineSeconds(js); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	// Parallelism overrides are mutable for replicated jobs whose child jobs are suspended.
+	allErrs = append(allErrs, validateParallelismOverrides(js)...)
+	overridesChanged := false
+	for _, rjob := range oldSpec.ReplicatedJobs {
+		oldParallelism, oldOk := oldSpec.ParallelismOverrides[rjob.Name]
+		parallelism, ok := js.Spec.ParallelismOverrides[rjob.Name]
+		if oldOk == ok && oldParallelism == parallelism {
+			continue
+		}
+		overridesChanged = true
+		if !pointer.BoolDeref(oldSpec.Suspend, false) && !pointer.BoolDeref(rjob.Suspend, false) {
+			allErrs = append(allErrs, fmt.Errorf("invalid parallelism override for replicatedJob '%s': can only be updated while its child jobs are suspended", rjob.Name))
+		} else if !pointer.BoolDeref(oldSpec.Suspend, false) && js.Spec.SchedulingPolicy != nil && js.Spec.SchedulingPolicy.Gang != nil {
+			// The pod group spans all child jobs, so its size can only change while none of them run.
+			allErrs = append(allErrs, fmt.Errorf("invalid parallelism override for replicatedJob '%s': can only be updated while the gang scheduled JobSet is suspended", rjob.Name))
+		} else if !pointer.BoolDeref(oldSpec.Suspend, false) && js.Spec.TotalPodsEnvName != "" {
+			// So does the total number of pods injected into the containers of all child jobs.
+			allErrs = append(allErrs, fmt.Errorf("invalid parallelism override for replicatedJob '%s': can only be updated while the JobSet injecting %s is suspended", rjob.Name, js.Spec.TotalPodsEnvName))
+		}
+	}
+	// Raising a parallelism override must not take the JobSet over the total pod limit.
+	if overridesChanged {
+		if err := validateTotalPods(js); err != nil {
+			allErrs = append(allErrs, err)
 		}
 	}
 	// Note that SucccessPolicy and failurePolicy are made immutable via CEL.
-	return apivalidation.ValidateImmutableField(mungedSpec.ReplicatedJobs, oldSpec.ReplicatedJobs, field.NewPath("spec").Child("replicatedJobs")).ToAggregate()
+	for _, err := range apivalidation.ValidateImmutableField(mungedSpec.ReplicatedJobs, oldReplicatedJobs, field.NewPath("spec").Child("replicatedJobs")) {
+		allErrs = append(allErrs, err)
+	}
+	return errors.Join(allErrs...)
+}
+
+// remainingReplicatedJobs splits the old replicated jobs into those which remain in the
+// updated JobSet, and the names of those which were removed.
+func remainingReplicatedJobs(oldReplicatedJobs []ReplicatedJob, names []string) ([]ReplicatedJob, []string) {
+	var remaining []ReplicatedJob
+	var removed []string
+	for _, rjob := range oldReplicatedJobs {
+		if util.Contains(names, rjob.Name) {
+			remaining = append(remaining, rjob)
+		} else {
+			removed = append(removed, rjob.Name)
+		}
+	}
+	return remaining, removed
+}
+
+// lastJobName returns the longest name of the jobs created from the replicatedJob, i.e. that
+// of its last job in the last restart attempt which the failure policy allows, counting the
+// given number of earlier restarts which do not count towards maxRestarts.
+func lastJobName(js *JobSet, rjob *ReplicatedJob, uncountedRestarts int) string {
+	attempt := 0
+	if js.Spec.FailurePolicy != nil {
+		attempt = js.Spec.FailurePolicy.MaxRestarts + uncountedRestarts
+	}
+	return JobName(js, rjob, rjob.Replicas-1, attempt)
+}
+
+// validateRemovedReplicatedJobs validates that the removed replicated jobs are not
+// referenced by the rest of the JobSet, and that at least one replicated job remains.
+func validateRemovedReplicatedJobs(js *JobSet, removed []string) []error {
+	var allErrs []error
+	if len(removed) > 0 && len(js.Spec.ReplicatedJobs) == 0 {
+		allErrs = append(allErrs, fmt.Errorf("at least one replicatedJob must remain"))
+	}
+	for _, name := range removed {
+		if js.Spec.SuccessPolicy != nil && util.Contains(js.Spec.SuccessPolicy.TargetReplicatedJobs, name) {
+			allErrs = append(allErrs, fmt.Errorf("replicatedJob '%s' cannot be removed: it is targeted by the successPolicy", name))
+		}
+		if js.Spec.Coordinator != nil && js.Spec.Coordinator.ReplicatedJob == name {
+			allErrs = append(allErrs, fmt.Errorf("replicatedJob '%s' cannot be removed: it runs the coordinator", name))
+		}
+		for _, rjob := range js.Spec.ReplicatedJobs {
+			if util.Contains(rjob.DependsOn, name) {
+				allErrs = append(allErrs, fmt.Errorf("replicatedJob '%s' cannot be removed: replicatedJob '%s' depends on it", name, rjob.Name))
+			}
+		}
+	}
+	return allErrs
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -99,6 +649,328 @@ func (js *JobSet) ValidateDelete() error {
 	return nil
 }
 
+// validateParallelismOverrides validates that parallelism overrides target replicatedJobs of
+// the JobSet, with positive values.
+func validateParallelismOverrides(js *JobSet) []error {
+	var allErrs []error
+	for rjobName, parallelism := range js.Spec.ParallelismOverrides {
+		if !util.Contains(replicatedJobNamesFromSpec(js), rjobName) {
+			allErrs = append(allErrs, fmt.Errorf("invalid parallelism override: replicatedJob '%s' does not appear in .spec.ReplicatedJobs", rjobName))
+		}
+		if parallelism <= 0 {
+			allErrs = append(allErrs, fmt.Errorf("invalid parallelism override for replicatedJob '%s': %d must be positive", rjobName, parallelism))
+		}
+	}
+	return allErrs
+}
+
+// validateJobDefaults validates that the completions and parallelism defaults are positive.
+func validateJobDefaults(js *JobSet) []error {
+	defaults := js.Spec.JobDefaults
+	if defaults == nil {
+		return nil
+	}
+	var allErrs []error
+	if defaults.Completions != nil && *defaults.Completions <= 0 {
+		allErrs = append(allErrs, fmt.Errorf("invalid jobDefaults: completions %d must be positive", *defaults.Completions))
+	}
+	if defaults.Parallelism != nil && *defaults.Parallelism <= 0 {
+		allErrs = append(allErrs, fmt.Errorf("invalid jobDefaults: parallelism %d must be positive", *defaults.Parallelism))
+	}
+	return allErrs
+}
+
+// validateTotalPods validates that the JobSet does not run more pods at once than MaxTotalPods.
+func validateTotalPods(js *JobSet) error {
+	if MaxTotalPods <= 0 {
+		return nil
+	}
+	var total int64
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		parallelism, ok := js.Spec.ParallelismOverrides[rjob.Name]
+		if !ok {
+			parallelism = 1
+			if rjob.Template.Spec.Parallelism != nil {
+				parallelism = *rjob.Template.Spec.Parallelism
+			} else if js.Spec.JobDefaults != nil && js.Spec.JobDefaults.Parallelism != nil {
+				parallelism = *js.Spec.JobDefaults.Parallelism
+			}
+		}
+		total += int64(rjob.Replicas) * int64(parallelism)
+	}
+	if total > int64(MaxTotalPods) {
+		return fmt.Errorf("invalid JobSet: it runs %d pods in total (replicas times parallelism, summed over replicatedJobs), which exceeds the maximum of %d", total, MaxTotalPods)
+	}
+	return nil
+}
+
+// validateGangPolicy validates the pod group name of a gang scheduled JobSet, and that the
+// number of pods of its pod group can be computed, i.e. that the completions and parallelism
+// of all replicatedJobs are set.
+func validateGangPolicy(js *JobSet) []error {
+	if js.Spec.SchedulingPolicy == nil || js.Spec.SchedulingPolicy.Gang == nil {
+		return nil
+	}
+	var allErrs []error
+	if name := js.Spec.SchedulingPolicy.Gang.PodGroupName; name != "" {
+		for _, errMessage := range validation.IsDNS1123Subdomain(name) {
+			allErrs = append(allErrs, fmt.Errorf("invalid schedulingPolicy.gang.podGroupName '%s': %s", name, errMessage))
+		}
+	}
+	defaults := js.Spec.JobDefaults
+	if defaults == nil {
+		defaults = &JobDefaults{}
+	}
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if rjob.Template.Spec.Completions == nil && defaults.Completions == nil {
+			allErrs = append(allErrs, fmt.Errorf("invalid schedulingPolicy.gang: completions of replicatedJob '%s' must be set to compute the size of the pod group", rjob.Name))
+		}
+		if _, ok := js.Spec.ParallelismOverrides[rjob.Name]; !ok && rjob.Template.Spec.Parallelism == nil && defaults.Parallelism == nil {
+			allErrs = append(allErrs, fmt.Errorf("invalid schedulingPolicy.gang: parallelism of replicatedJob '%s' must be set to compute the size of the pod group", rjob.Name))
+		}
+	}
+	return allErrs
+}
+
+// validateMinSucceeded validates that minSucceeded is only set with the Any operator, and is
+// positive without exceeding the replicas of any targeted replicatedJob.
+func validateMinSucceeded(js *JobSet) []error {
+	minSucceeded := js.Spec.SuccessPolicy.MinSucceeded
+	if minSucceeded == nil {
+		return nil
+	}
+	var allErrs []error
+	if js.Spec.SuccessPolicy.Operator != OperatorAny {
+		allErrs = append(allErrs, fmt.Errorf("invalid successPolicy.minSucceeded: it can only be set with operator %s", OperatorAny))
+	}
+	if *minSucceeded <= 0 {
+		allErrs = append(allErrs, fmt.Errorf("invalid successPolicy.minSucceeded: %d must be positive", *minSucceeded))
+	}
+	targets := js.Spec.SuccessPolicy.TargetReplicatedJobs
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if len(targets) > 0 && !util.Contains(targets, rjob.Name) {
+			continue
+		}
+		if int(*minSucceeded) > rjob.Replicas {
+			allErrs = append(allErrs, fmt.Errorf("invalid successPolicy.minSucceeded: %d exceeds the %d replicas of replicatedJob '%s'", *minSucceeded, rjob.Replicas, rjob.Name))
+		}
+	}
+	return allErrs
+}
+
+// validateBlueGreenPlacement validates that the BlueGreen restart strategy is not combined
+// with placement features which cannot hold the child Jobs of two attempts at once. With
+// exclusive placement, the new Jobs are kept out of the topology domains still held by the
+// previous ones, which are only deleted once the new ones are ready. With gang scheduling,
+// the pods of both attempts join the same pod group.
+func validateBlueGreenPlacement(js *JobSet) []error {
+	if js.Spec.FailurePolicy == nil || js.Spec.FailurePolicy.RestartStrategy != RestartStrategyBlueGreen {
+		return nil
+	}
+	var allErrs []error
+	if _, ok := js.Annotations[ExclusiveKey]; ok {
+		allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy: the %s restart strategy cannot be used with the %s annotation", RestartStrategyBlueGreen, ExclusiveKey))
+	}
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if rjob.ExclusivePlacement != nil {
+			allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy: the %s restart strategy cannot be used with the exclusivePlacement of replicatedJob '%s'", RestartStrategyBlueGreen, rjob.Name))
+		}
+	}
+	if js.Spec.SchedulingPolicy != nil && js.Spec.SchedulingPolicy.Gang != nil {
+		allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy: the %s restart strategy cannot be used with gang scheduling", RestartStrategyBlueGreen))
+	}
+	return allErrs
+}
+
+// validateManagedServiceAccount validates that pod templates leave the service account to
+// the controller when it manages one for the JobSet.
+func validateManagedServiceAccount(js *JobSet) []error {
+	if js.Annotations[ManagedServiceAccountKey] != "true" {
+		return nil
+	}
+	var allErrs []error
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if name := rjob.Template.Spec.Template.Spec.ServiceAccountName; name != "" {
+			allErrs = append(allErrs, fmt.Errorf("replicatedJob '%s' sets serviceAccountName '%s' on its pod template, which conflicts with the %s annotation", rjob.Name, name, ManagedServiceAccountKey))
+		}
+	}
+	return allErrs
+}
+
+// validateTTLSecondsAfterFinished validates that the TTL after finishing is not negative.
+func validateTTLSecondsAfterFinished(js *JobSet) error {
+	if ttl := js.Spec.TTLSecondsAfterFinished; ttl != nil && *ttl < 0 {
+		return fmt.Errorf("invalid ttlSecondsAfterFinished: %d must not be negative", *ttl)
+	}
+	return nil
+}
+
+// validateJobNaming validates that the prefix, suffix and separator of the job naming keep the
+// names of the child jobs and headless services valid DNS labels. They are validated along
+// with minimal names, since only together with them they need to form a valid DNS label.
+func validateJobNaming(js *JobSet) []error {
+	if js.Spec.JobNaming == nil {
+		return nil
+	}
+	var allErrs []error
+	if prefix := js.Spec.JobNaming.Prefix; prefix != "" {
+		for _, errMessage := range validation.IsDNS1123Label(prefix + "0") {
+			allErrs = append(allErrs, fmt.Errorf("invalid jobNaming prefix '%s': %s", prefix, errMessage))
+		}
+	}
+	if suffix := js.Spec.JobNaming.Suffix; suffix != "" {
+		for _, errMessage := range validation.IsDNS1123Label("0" + suffix) {
+			allErrs = append(allErrs, fmt.Errorf("invalid jobNaming suffix '%s': %s", suffix, errMessage))
+		}
+	}
+	if sep := js.Spec.JobNaming.Separator; sep != "" {
+		for _, errMessage := range validation.IsDNS1123Label("0" + sep + "0") {
+			allErrs = append(allErrs, fmt.Errorf("invalid jobNaming separator '%s': %s", sep, errMessage))
+		}
+	}
+	return allErrs
+}
+
+// containerImagesChanged reports whether the image of any container of the pod spec differs
+// from that of the matching container of the old pod spec.
+func containerImagesChanged(podSpec, oldPodSpec *corev1.PodSpec) bool {
+	for i := range podSpec.InitContainers {
+		if i < len(oldPodSpec.InitContainers) && podSpec.InitContainers[i].Image != oldPodSpec.InitContainers[i].Image {
+			return true
+		}
+	}
+	for i := range podSpec.Containers {
+		if i < len(oldPodSpec.Containers) && podSpec.Containers[i].Image != oldPodSpec.Containers[i].Image {
+			return true
+		}
+	}
+	return false
+}
+
+// mungeContainerImages sets the images of the containers of the pod spec to those of the
+// matching containers of the old pod spec, so that only changes of other fields remain.
+func mungeContainerImages(podSpec, oldPodSpec *corev1.PodSpec) {
+	for i := range podSpec.InitContainers {
+		if i < len(oldPodSpec.InitContainers) {
+			podSpec.InitContainers[i].Image = oldPodSpec.InitContainers[i].Image
+		}
+	}
+	for i := range podSpec.Containers {
+		if i < len(oldPodSpec.Containers) {
+			podSpec.Containers[i].Image = oldPodSpec.Containers[i].Image
+		}
+	}
+}
+
+// validateActiveDeadlineSeconds validates that the active deadline is positive.
+func validateActiveDeadlineSeconds(js *JobSet) error {
+	if deadline := js.Spec.ActiveDeadlineSeconds; deadline != nil && *deadline <= 0 {
+		return fmt.Errorf("invalid activeDeadlineSeconds: %d must be positive", *deadline)
+	}
+	return nil
+}
+
+// validateFailurePolicyRules validates that the rules of the failure policy match valid
+// container exit codes, that no exit code is matched by rules with different actions, and
+// that failures are only ignored with the Pod failure level.
+// The owner describes the replicatedJob of the failure policy in the errors, if any.
+func validateFailurePolicyRules(policy *FailurePolicy, owner string) []error {
+	var allErrs []error
+	// ruleForExitCode is the index of the first rule matching each exit code.
+	ruleForExitCode := map[int32]int{}
+	for i, rule := range policy.Rules {
+		switch rule.Action {
+		case FailurePolicyActionRestartJobSet, FailurePolicyActionFailJobSet:
+		case FailurePolicyActionIgnore:
+			if policy.Level != FailureLevelPod {
+				allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy.rules[%d]%s: action %s requires failure level %s", i, owner, rule.Action, FailureLevelPod))
+			}
+		default:
+			allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy.rules[%d]%s: action '%s' must be one of %s, %s or %s", i, owner, rule.Action, FailurePolicyActionRestartJobSet, FailurePolicyActionFailJobSet, FailurePolicyActionIgnore))
+		}
+		if len(rule.OnExitCodes) == 0 {
+			allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy.rules[%d]%s: at least one exit code must be specified", i, owner))
+		}
+		for _, exitCode := range rule.OnExitCodes {
+			if exitCode < 1 || exitCode > 255 {
+				allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy.rules[%d]%s: exit code %d must be between 1 and 255", i, owner, exitCode))
+				continue
+			}
+			first, ok := ruleForExitCode[exitCode]
+			if !ok {
+				ruleForExitCode[exitCode] = i
+				continue
+			}
+			if action := policy.Rules[first].Action; action != rule.Action {
+				allErrs = append(allErrs, fmt.Errorf("invalid failurePolicy.rules[%d]%s: exit code %d is already matched by rules[%d] with action %s", i, owner, exitCode, first, action))
+			}
+		}
+	}
+	return allErrs
+}
+
+// reservedDomain is the domain of the labels and annotations managed by JobSet.
+const reservedDomain = "jobset.sigs.k8s.io"
+
+// reservedAnnotationKey reports whether the annotation key is under the reserved domain or one of its subdomains.
+func reservedAnnotationKey(key string) bool {
+	i := strings.Index(key, "/")
+	if i < 0 {
+		return false
+	}
+	domain := key[:i]
+	return domain == reservedDomain || strings.HasSuffix(domain, "."+reservedDomain)
+}
+
+// dependencyCycle returns a cycle in the dependencies between the replicatedJobs, starting
+// and ending with the same replicatedJob, or nil if there is none. Self-dependencies and
+// dependencies on unknown replicatedJobs are reported separately and ignored here.
+func dependencyCycle(js *JobSet) []string {
+	dependsOn := map[string][]string{}
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		dependsOn[rjob.Name] = rjob.DependsOn
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var path []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range dependsOn[name] {
+			if _, ok := dependsOn[dep]; !ok || dep == name {
+				continue
+			}
+			switch state[dep] {
+			case visiting:
+				for i := range path {
+					if path[i] == dep {
+						return append(append([]string{}, path[i:]...), dep)
+					}
+				}
+			case 0:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if state[rjob.Name] == 0 {
+			if cycle := visit(rjob.Name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
 func completionModePtr(mode batchv1.CompletionMode) *batchv1.CompletionMode {
 	return &mode
 }
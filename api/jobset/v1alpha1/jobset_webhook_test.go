@@ -1,12 +1,20 @@
 package v1alpha1
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	admissionv1 "k8s.io/api/admission/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // TestPodTemplate is the default pod template spec used for testing.
@@ -340,6 +348,54 @@ func TestJobSetDefaulting(t *testing.T) {
 				},
 			},
 		},
+		{
+			// The failure policy is immutable, so its level and restart strategy are left unset,
+			// which count child Job failures and recreate the child Jobs, rather than defaulted
+			// on updates of existing JobSets.
+			name: "failure policy level is unset",
+			js: &JobSet{
+				Spec: JobSetSpec{
+					SuccessPolicy: defaultSuccessPolicy,
+					FailurePolicy: &FailurePolicy{MaxRestarts: 2},
+				},
+			},
+			want: &JobSet{
+				Spec: JobSetSpec{
+					SuccessPolicy: defaultSuccessPolicy,
+					FailurePolicy: &FailurePolicy{MaxRestarts: 2},
+				},
+			},
+		},
+		{
+			name: "failure policy level is set",
+			js: &JobSet{
+				Spec: JobSetSpec{
+					SuccessPolicy: defaultSuccessPolicy,
+					FailurePolicy: &FailurePolicy{MaxRestarts: 2, Level: FailureLevelPod},
+				},
+			},
+			want: &JobSet{
+				Spec: JobSetSpec{
+					SuccessPolicy: defaultSuccessPolicy,
+					FailurePolicy: &FailurePolicy{MaxRestarts: 2, Level: FailureLevelPod},
+				},
+			},
+		},
+		{
+			name: "failure policy restart strategy is set",
+			js: &JobSet{
+				Spec: JobSetSpec{
+					SuccessPolicy: defaultSuccessPolicy,
+					FailurePolicy: &FailurePolicy{MaxRestarts: 2, RestartStrategy: RestartStrategyBlueGreen},
+				},
+			},
+			want: &JobSet{
+				Spec: JobSetSpec{
+					SuccessPolicy: defaultSuccessPolicy,
+					FailurePolicy: &FailurePolicy{MaxRestarts: 2, RestartStrategy: RestartStrategyBlueGreen},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -351,3 +407,2020 @@ func TestJobSetDefaulting(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCreate(t *testing.T) {
+	validReplicatedJobs := []ReplicatedJob{
+		{
+			Name: "rjob",
+			Template: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: TestPodTemplate,
+				},
+			},
+			Network:  &Network{EnableDNSHostnames: pointer.Bool(true)},
+			Replicas: 1,
+		},
+	}
+	testCases := []struct {
+		name    string
+		js      *JobSet
+		wantErr string
+	}{
+		{
+			name: "valid jobset",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+		},
+		{
+			name: "jobset name is not a valid DNS subdomain",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "Invalid_Name"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid JobSet name 'Invalid_Name'",
+		},
+		{
+			name: "success policy targets unknown replicated job",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll, TargetReplicatedJobs: []string{"does-not-exist"}},
+				},
+			},
+			wantErr: "invalid replicatedJob name 'does-not-exist'",
+		},
+		{
+			name: "shared volume collides with a pod template volume",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name: "rjob",
+							Template: batchv1.JobTemplateSpec{
+								Spec: batchv1.JobSpec{
+									Template: corev1.PodTemplateSpec{
+										Spec: corev1.PodSpec{
+											Volumes: []corev1.Volume{{Name: "dshm"}},
+										},
+									},
+								},
+							},
+							SharedVolumes: []SharedVolume{{Name: "dshm", MountPath: "/dev/shm"}},
+							Replicas:      1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "shared volume 'dshm' of replicatedJob 'rjob' collides with a volume of the same name",
+		},
+		{
+			name: "jobset shared volume collides with a pod template volume",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob",
+							Replicas: 1,
+						},
+						{
+							Name: "rjob-with-volumes",
+							Template: batchv1.JobTemplateSpec{
+								Spec: batchv1.JobSpec{
+									Template: corev1.PodTemplateSpec{
+										Spec: corev1.PodSpec{
+											Volumes: []corev1.Volume{{Name: "dshm"}},
+										},
+									},
+								},
+							},
+							Replicas: 1,
+						},
+					},
+					SharedVolumes: []SharedVolume{{Name: "dshm", MountPath: "/dev/shm"}},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "shared volume 'dshm' of the JobSet collides with a volume of replicatedJob 'rjob-with-volumes'",
+		},
+		{
+			name: "parallelism override for unknown replicated job",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs:       validReplicatedJobs,
+					SuccessPolicy:        &SuccessPolicy{Operator: OperatorAll},
+					ParallelismOverrides: map[string]int32{"does-not-exist": 2},
+				},
+			},
+			wantErr: "invalid parallelism override: replicatedJob 'does-not-exist' does not appear in .spec.ReplicatedJobs",
+		},
+		{
+			name: "non-positive parallelism override",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs:       validReplicatedJobs,
+					SuccessPolicy:        &SuccessPolicy{Operator: OperatorAll},
+					ParallelismOverrides: map[string]int32{"rjob": 0},
+				},
+			},
+			wantErr: "invalid parallelism override for replicatedJob 'rjob': 0 must be positive",
+		},
+		{
+			name: "gang scheduling without completions",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs:   validReplicatedJobs,
+					SuccessPolicy:    &SuccessPolicy{Operator: OperatorAll},
+					JobDefaults:      &JobDefaults{Parallelism: pointer.Int32(2)},
+					SchedulingPolicy: &SchedulingPolicy{Gang: &GangPolicy{}},
+				},
+			},
+			wantErr: "invalid schedulingPolicy.gang: completions of replicatedJob 'rjob' must be set to compute the size of the pod group",
+		},
+		{
+			name: "gang scheduling with completions and parallelism defaults",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs:   validReplicatedJobs,
+					SuccessPolicy:    &SuccessPolicy{Operator: OperatorAll},
+					JobDefaults:      &JobDefaults{Completions: pointer.Int32(2), Parallelism: pointer.Int32(2)},
+					SchedulingPolicy: &SchedulingPolicy{Gang: &GangPolicy{PodGroupName: "group"}},
+				},
+			},
+		},
+		{
+			name: "gang scheduling with invalid pod group name",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs:   validReplicatedJobs,
+					SuccessPolicy:    &SuccessPolicy{Operator: OperatorAll},
+					JobDefaults:      &JobDefaults{Completions: pointer.Int32(2), Parallelism: pointer.Int32(2)},
+					SchedulingPolicy: &SchedulingPolicy{Gang: &GangPolicy{PodGroupName: "Group"}},
+				},
+			},
+			wantErr: "invalid schedulingPolicy.gang.podGroupName 'Group'",
+		},
+		{
+			name: "minSucceeded with operator All",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll, MinSucceeded: pointer.Int32(1)},
+				},
+			},
+			wantErr: "invalid successPolicy.minSucceeded: it can only be set with operator Any",
+		},
+		{
+			name: "minSucceeded exceeding replicas",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAny, TargetReplicatedJobs: []string{"rjob"}, MinSucceeded: pointer.Int32(2)},
+				},
+			},
+			wantErr: "invalid successPolicy.minSucceeded: 2 exceeds the 1 replicas of replicatedJob 'rjob'",
+		},
+		{
+			name: "minSucceeded within replicas",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAny, MinSucceeded: pointer.Int32(1)},
+				},
+			},
+		},
+		{
+			name: "unknown suspend policy",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					SuspendPolicy:  "Pause",
+				},
+			},
+			wantErr: "invalid suspendPolicy 'Pause': must be one of DeletePods or KeepPods",
+		},
+		{
+			name: "non-positive job defaults",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					JobDefaults:    &JobDefaults{Completions: pointer.Int32(4), Parallelism: pointer.Int32(0)},
+				},
+			},
+			wantErr: "invalid jobDefaults: parallelism 0 must be positive",
+		},
+		{
+			name: "exclusive placement without topology key",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:               "rjob",
+							Template:           batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:            &Network{EnableDNSHostnames: pointer.Bool(true)},
+							ExclusivePlacement: &ExclusivePlacement{},
+							Replicas:           1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid exclusivePlacement topologyKey for replicatedJob 'rjob'",
+		},
+		{
+			name: "invalid index node affinity",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:              "rjob",
+							Template:          batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							IndexNodeAffinity: &IndexNodeAffinity{Key: "topology.kubernetes.io/zone", Values: []string{"zone a"}},
+							Replicas:          1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid indexNodeAffinity value 'zone a' for replicatedJob 'rjob'",
+		},
+		{
+			name: "index node affinity without values",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:              "rjob",
+							Template:          batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							IndexNodeAffinity: &IndexNodeAffinity{Key: "topology.kubernetes.io/zone"},
+							Replicas:          1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid indexNodeAffinity for replicatedJob 'rjob': at least one value is required",
+		},
+		{
+			name: "pod annotation with reserved key",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					PodAnnotations: map[string]string{ExclusiveKey: "topology.kubernetes.io/zone"},
+				},
+			},
+			wantErr: "invalid pod annotation 'alpha.jobset.sigs.k8s.io/exclusive-topology': keys under the jobset.sigs.k8s.io domain are reserved",
+		},
+		{
+			name: "valid pod annotations",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					PodAnnotations: map[string]string{"sidecar.istio.io/inject": "true"},
+				},
+			},
+		},
+		{
+			name: "unknown workload type",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					WorkloadType:   "Inference",
+				},
+			},
+			wantErr: "invalid workloadType 'Inference': must be one of Training, Batch or Service",
+		},
+		{
+			name: "valid workload type",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					WorkloadType:   WorkloadTypeTraining,
+				},
+			},
+		},
+		{
+			name: "zero activeDeadlineSeconds",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name: "rjob",
+							Template: batchv1.JobTemplateSpec{
+								Spec: batchv1.JobSpec{
+									ActiveDeadlineSeconds: pointer.Int64(0),
+									Template:              TestPodTemplate,
+								},
+							},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid activeDeadlineSeconds for replicatedJob 'rjob': 0 must be positive",
+		},
+		{
+			name: "negative activeDeadlineSeconds",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name: "rjob",
+							Template: batchv1.JobTemplateSpec{
+								Spec: batchv1.JobSpec{
+									ActiveDeadlineSeconds: pointer.Int64(-10),
+									Template:              TestPodTemplate,
+								},
+							},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid activeDeadlineSeconds for replicatedJob 'rjob': -10 must be positive",
+		},
+		{
+			name: "invalid total pods env name",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs:   validReplicatedJobs,
+					SuccessPolicy:    &SuccessPolicy{Operator: OperatorAll},
+					TotalPodsEnvName: "WORLD=SIZE",
+				},
+			},
+			wantErr: "invalid totalPodsEnvName 'WORLD=SIZE'",
+		},
+		{
+			name: "negative max restarts",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					FailurePolicy:  &FailurePolicy{MaxRestarts: -1},
+				},
+			},
+			wantErr: "invalid failurePolicy.maxRestarts: -1 must not be negative",
+		},
+		{
+			name: "negative ttl after finished",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs:          validReplicatedJobs,
+					SuccessPolicy:           &SuccessPolicy{Operator: OperatorAll},
+					TTLSecondsAfterFinished: pointer.Int32(-1),
+				},
+			},
+			wantErr: "invalid ttlSecondsAfterFinished: -1 must not be negative",
+		},
+		{
+			name: "zero active deadline",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs:        validReplicatedJobs,
+					SuccessPolicy:         &SuccessPolicy{Operator: OperatorAll},
+					ActiveDeadlineSeconds: pointer.Int64(0),
+				},
+			},
+			wantErr: "invalid activeDeadlineSeconds: 0 must be positive",
+		},
+		{
+			name: "zero max restarts",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					FailurePolicy:  &FailurePolicy{},
+				},
+			},
+		},
+		{
+			name: "success policy any with valid target",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAny, TargetReplicatedJobs: []string{"rjob"}},
+				},
+			},
+		},
+		{
+			name: "success policy any with invalid target",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAny, TargetReplicatedJobs: []string{"does-not-exist"}},
+				},
+			},
+			wantErr: "invalid replicatedJob name 'does-not-exist' does not appear in .spec.ReplicatedJobs",
+		},
+		{
+			name: "success policy all with valid target",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll, TargetReplicatedJobs: []string{"rjob"}},
+				},
+			},
+		},
+		{
+			name: "success policy all with invalid target",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll, TargetReplicatedJobs: []string{"does-not-exist"}},
+				},
+			},
+			wantErr: "invalid replicatedJob name 'does-not-exist' does not appear in .spec.ReplicatedJobs",
+		},
+		{
+			name: "unknown success policy operator",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: "Some"},
+				},
+			},
+			wantErr: "invalid successPolicy operator 'Some': must be one of All, Any or Never",
+		},
+		{
+			name: "replicated job failure policy override",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:          "rjob",
+							Template:      batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:       &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas:      1,
+							FailurePolicy: &FailurePolicy{MaxRestarts: 5, Level: FailureLevelPod},
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+		},
+		{
+			name: "replicated job failure policy with negative max restarts",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:          "rjob",
+							Template:      batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:       &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas:      1,
+							FailurePolicy: &FailurePolicy{MaxRestarts: -1},
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid failurePolicy.maxRestarts for replicatedJob 'rjob': -1 must not be negative",
+		},
+		{
+			name: "replicated job failure policy with aggregation window",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:          "rjob",
+							Template:      batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:       &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas:      1,
+							FailurePolicy: &FailurePolicy{AggregationWindow: &metav1.Duration{Duration: time.Minute}},
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid failurePolicy for replicatedJob 'rjob': aggregationWindow can only be set on the failurePolicy of the JobSet",
+		},
+		{
+			name: "replicated job failure policy with max unavailable",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:          "rjob",
+							Template:      batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:       &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas:      1,
+							FailurePolicy: &FailurePolicy{MaxUnavailable: pointer.Int32(1)},
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid failurePolicy for replicatedJob 'rjob': maxUnavailable can only be set on the failurePolicy of the JobSet",
+		},
+		{
+			name: "replicated job failure policy with restart strategy",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:          "rjob",
+							Template:      batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:       &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas:      1,
+							FailurePolicy: &FailurePolicy{RestartStrategy: RestartStrategyBlueGreen},
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid failurePolicy for replicatedJob 'rjob': restartStrategy can only be set on the failurePolicy of the JobSet",
+		},
+		{
+			name: "blue-green restart strategy with max unavailable",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					FailurePolicy:  &FailurePolicy{MaxRestarts: 1, RestartStrategy: RestartStrategyBlueGreen, MaxUnavailable: pointer.Int32(1)},
+				},
+			},
+			wantErr: "invalid failurePolicy: maxUnavailable cannot be set with the BlueGreen restart strategy",
+		},
+		{
+			name: "blue-green restart strategy with the exclusive placement annotation",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js", Annotations: map[string]string{ExclusiveKey: "topology.kubernetes.io/zone"}},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					FailurePolicy:  &FailurePolicy{MaxRestarts: 1, RestartStrategy: RestartStrategyBlueGreen},
+				},
+			},
+			wantErr: "invalid failurePolicy: the BlueGreen restart strategy cannot be used with the alpha.jobset.sigs.k8s.io/exclusive-topology annotation",
+		},
+		{
+			name: "blue-green restart strategy with exclusive placement of a replicatedJob",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:               "rjob",
+							Template:           batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:            &Network{EnableDNSHostnames: pointer.Bool(true)},
+							ExclusivePlacement: &ExclusivePlacement{TopologyKey: "topology.kubernetes.io/zone"},
+							Replicas:           1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+					FailurePolicy: &FailurePolicy{MaxRestarts: 1, RestartStrategy: RestartStrategyBlueGreen},
+				},
+			},
+			wantErr: "invalid failurePolicy: the BlueGreen restart strategy cannot be used with the exclusivePlacement of replicatedJob 'rjob'",
+		},
+		{
+			name: "blue-green restart strategy with gang scheduling",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs:   validReplicatedJobs,
+					SuccessPolicy:    &SuccessPolicy{Operator: OperatorAll},
+					JobDefaults:      &JobDefaults{Completions: pointer.Int32(2), Parallelism: pointer.Int32(2)},
+					FailurePolicy:    &FailurePolicy{MaxRestarts: 1, RestartStrategy: RestartStrategyBlueGreen},
+					SchedulingPolicy: &SchedulingPolicy{Gang: &GangPolicy{}},
+				},
+			},
+			wantErr: "invalid failurePolicy: the BlueGreen restart strategy cannot be used with gang scheduling",
+		},
+		{
+			name: "blue-green restart strategy job names too long with restart suffix",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 56)},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					FailurePolicy:  &FailurePolicy{MaxRestarts: 10, RestartStrategy: RestartStrategyBlueGreen},
+				},
+			},
+			wantErr: "replicatedJob 'rjob' would create jobs with names longer than 63 characters, such as '" + strings.Repeat("a", 56) + "-rjob-0-r10'",
+		},
+		{
+			name: "job naming",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					JobNaming:      &JobNaming{Prefix: "team-a-", Suffix: "-train"},
+				},
+			},
+		},
+		{
+			name: "job naming with invalid prefix",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					JobNaming:      &JobNaming{Prefix: "Team_"},
+				},
+			},
+			wantErr: "invalid jobNaming prefix 'Team_'",
+		},
+		{
+			name: "job naming with suffix ending in a dash",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					JobNaming:      &JobNaming{Suffix: "-"},
+				},
+			},
+			wantErr: "invalid jobNaming suffix '-'",
+		},
+		{
+			name: "job naming with separator",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					JobNaming:      &JobNaming{Separator: "--"},
+				},
+			},
+		},
+		{
+			name: "job naming with invalid separator",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					JobNaming:      &JobNaming{Separator: "_"},
+				},
+			},
+			wantErr: "invalid jobNaming separator '_'",
+		},
+		{
+			name: "job names too long with job naming separator",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 50)},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					JobNaming:      &JobNaming{Separator: "-----"},
+				},
+			},
+			wantErr: "replicatedJob 'rjob' would create jobs with names longer than 63 characters, such as '" + strings.Repeat("a", 50) + "-----rjob-----0'",
+		},
+		{
+			name: "job names too long with job naming suffix",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 50)},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					JobNaming:      &JobNaming{Suffix: "-training"},
+				},
+			},
+			wantErr: "replicatedJob 'rjob' would create jobs with names longer than 63 characters, such as '" + strings.Repeat("a", 50) + "-rjob-0-training'",
+		},
+		{
+			name: "dependencies between replicated jobs",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:      "coordinator",
+							Template:  batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:   &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas:  1,
+							DependsOn: []string{},
+						},
+						{
+							Name:      "workers",
+							Template:  batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:   &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas:  1,
+							DependsOn: []string{"coordinator"},
+						},
+						{
+							Name:      "evaluator",
+							Template:  batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:   &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas:  1,
+							DependsOn: []string{"coordinator", "workers"},
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+		},
+		{
+			name: "dependency on unknown replicated job",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:      "workers",
+							Template:  batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:   &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas:  1,
+							DependsOn: []string{"coordinator"},
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid dependsOn for replicatedJob 'workers': replicatedJob 'coordinator' does not appear in .spec.ReplicatedJobs",
+		},
+		{
+			name: "dependency on itself",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:      "workers",
+							Template:  batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:   &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas:  1,
+							DependsOn: []string{"workers"},
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid dependsOn for replicatedJob 'workers': it cannot depend on itself",
+		},
+		{
+			name: "dependency cycle",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:      "coordinator",
+							Template:  batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:   &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas:  1,
+							DependsOn: []string{"evaluator"},
+						},
+						{
+							Name:      "workers",
+							Template:  batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:   &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas:  1,
+							DependsOn: []string{"coordinator"},
+						},
+						{
+							Name:      "evaluator",
+							Template:  batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:   &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas:  1,
+							DependsOn: []string{"workers"},
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid dependsOn: replicatedJobs depend on each other in a cycle: coordinator -> evaluator -> workers -> coordinator",
+		},
+		{
+			name: "pod template service account conflicts with managed service account",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "js",
+					Annotations: map[string]string{ManagedServiceAccountKey: "true"},
+				},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name: "rjob",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+								ServiceAccountName: "custom",
+								Containers:         []corev1.Container{{Name: "main", Image: "busybox"}},
+							}}}},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "replicatedJob 'rjob' sets serviceAccountName 'custom' on its pod template, which conflicts with the alpha.jobset.sigs.k8s.io/managed-service-account annotation",
+		},
+		{
+			name: "duplicate container names",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name: "rjob",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+								InitContainers: []corev1.Container{},
+								Containers:     []corev1.Container{{Name: "main", Image: "busybox"}, {Name: "main", Image: "busybox"}},
+							}}}},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "replicatedJob 'rjob' has more than one container named 'main' in its pod template",
+		},
+		{
+			name: "container name reused by init container",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name: "rjob",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+								InitContainers: []corev1.Container{{Name: "setup", Image: "busybox"}},
+								Containers:     []corev1.Container{{Name: "setup", Image: "busybox"}},
+							}}}},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "replicatedJob 'rjob' has more than one container named 'setup' in its pod template",
+		},
+		{
+			name: "coordinator",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "driver",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas: 2,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+					Coordinator:   &Coordinator{ReplicatedJob: "driver", JobIndex: 1},
+				},
+			},
+		},
+		{
+			name: "coordinator replicated job does not exist",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "driver",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas: 2,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+					Coordinator:   &Coordinator{ReplicatedJob: "workers"},
+				},
+			},
+			wantErr: "invalid coordinator: replicatedJob 'workers' does not appear in .spec.ReplicatedJobs",
+		},
+		{
+			name: "coordinator without DNS hostnames",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "driver",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(false)},
+							Replicas: 2,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+					Coordinator:   &Coordinator{ReplicatedJob: "driver"},
+				},
+			},
+			wantErr: "invalid coordinator: replicatedJob 'driver' must enable DNS hostnames for the coordinator to be resolvable",
+		},
+		{
+			name: "coordinator replicated job is not indexed",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name: "driver",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{
+								CompletionMode: completionModePtr(batchv1.NonIndexedCompletion),
+								Template:       TestPodTemplate,
+							}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas: 2,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+					Coordinator:   &Coordinator{ReplicatedJob: "driver"},
+				},
+			},
+			wantErr: "invalid coordinator: replicatedJob 'driver' must use the Indexed completion mode for the coordinator to have a stable pod index",
+		},
+		{
+			name: "coordinator job index out of range",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "driver",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas: 2,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+					Coordinator:   &Coordinator{ReplicatedJob: "driver", JobIndex: 2},
+				},
+			},
+			wantErr: "invalid coordinator: jobIndex 2 is out of range for replicatedJob 'driver' with 2 replicas",
+		},
+		{
+			name: "custom subdomains",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob-0",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), Subdomain: "workers"},
+							Replicas: 1,
+						},
+						{
+							Name:     "rjob-1",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), Subdomain: "driver"},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+		},
+		{
+			name: "invalid custom subdomain",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob-0",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), Subdomain: "Workers.svc"},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid subdomain 'Workers.svc' for replicatedJob 'rjob-0'",
+		},
+		{
+			name: "custom subdomain used twice",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob-0",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), Subdomain: "workers"},
+							Replicas: 1,
+						},
+						{
+							Name:     "rjob-1",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), Subdomain: "workers"},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid subdomain 'workers' for replicatedJob 'rjob-1': already used by replicatedJob 'rjob-0'",
+		},
+		{
+			name: "custom subdomain is the JobSet name",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob-0",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), Subdomain: "js"},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid subdomain 'js' for replicatedJob 'rjob-0': the JobSet name is the default subdomain",
+		},
+		{
+			name: "JobSet name is not a valid default subdomain",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js.example"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob-0",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid JobSet name 'js.example': it is the default subdomain of the pods with DNS hostnames",
+		},
+		{
+			name: "DNS hostnames enabled and disabled on the default subdomain",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob-0",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas: 1,
+						},
+						{
+							Name:     "rjob-1",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(false)},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid enableDNSHostnames for replicatedJob 'rjob-1': disabled, but replicatedJob 'rjob-0' enables it on the default subdomain",
+		},
+		{
+			name: "DNS hostnames disabled next to a custom subdomain",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob-0",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), Subdomain: "workers"},
+							Replicas: 1,
+						},
+						{
+							Name:     "rjob-1",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(false)},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+		},
+		{
+			name: "JobSet name with custom subdomains only",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js.example"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob-0",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), Subdomain: "workers"},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+		},
+		{
+			name: "publishNotReadyAddresses disabled without custom subdomain",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob-0",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), PublishNotReadyAddresses: pointer.Bool(false)},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid publishNotReadyAddresses for replicatedJob 'rjob-0': can only be disabled with a custom subdomain",
+		},
+		{
+			name: "propagated labels and annotations",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs:        validReplicatedJobs,
+					SuccessPolicy:         &SuccessPolicy{Operator: OperatorAll},
+					PropagatedLabels:      []string{"cost-center", "example.com/team"},
+					PropagatedAnnotations: []string{"owner"},
+				},
+			},
+		},
+		{
+			name: "reserved propagated label",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs:   validReplicatedJobs,
+					SuccessPolicy:    &SuccessPolicy{Operator: OperatorAll},
+					PropagatedLabels: []string{JobIndexKey},
+				},
+			},
+			wantErr: "invalid propagated label 'jobset.sigs.k8s.io/job-index': keys under the jobset.sigs.k8s.io domain are reserved",
+		},
+		{
+			name: "invalid propagated annotation",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs:        validReplicatedJobs,
+					SuccessPolicy:         &SuccessPolicy{Operator: OperatorAll},
+					PropagatedAnnotations: []string{"not a key"},
+				},
+			},
+			wantErr: "invalid propagated annotation 'not a key'",
+		},
+		{
+			name: "valid service selector",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"role": "coordinator"}}, Spec: TestPodTemplate.Spec}}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), Subdomain: "coordinator", ServiceSelector: map[string]string{JobIndexKey: "0", "role": "coordinator"}},
+							Replicas: 2,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+		},
+		{
+			name: "service selector without DNS hostnames",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{ServiceSelector: map[string]string{JobIndexKey: "0"}},
+							Replicas: 2,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid serviceSelector for replicatedJob 'rjob': requires enableDNSHostnames",
+		},
+		{
+			name: "service selector without custom subdomain",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), ServiceSelector: map[string]string{JobIndexKey: "0"}},
+							Replicas: 2,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid serviceSelector for replicatedJob 'rjob': requires a custom subdomain",
+		},
+		{
+			name: "invalid service selector",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), Subdomain: "coordinator", ServiceSelector: map[string]string{"not a key": "not a value"}},
+							Replicas: 2,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid serviceSelector key 'not a key' for replicatedJob 'rjob'",
+		},
+		{
+			name: "service selector overriding the replicatedJob",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), Subdomain: "coordinator", ServiceSelector: map[string]string{ReplicatedJobNameKey: "other"}},
+							Replicas: 2,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid serviceSelector key 'jobset.sigs.k8s.io/replicatedjob-name' for replicatedJob 'rjob': the label is always selected by the Service",
+		},
+		{
+			name: "service selector conflicting with the pod template",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"role": "worker"}}, Spec: TestPodTemplate.Spec}}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true), Subdomain: "coordinator", ServiceSelector: map[string]string{"role": "coordinator"}},
+							Replicas: 2,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid serviceSelector for replicatedJob 'rjob': selects role=coordinator, but its pod template sets role=worker, so no pods would be selected",
+		},
+		{
+			name: "success policy with never operator",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorNever},
+				},
+			},
+		},
+		{
+			name: "success policy with never operator and target replicated jobs",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorNever, TargetReplicatedJobs: []string{"rjob"}},
+				},
+			},
+			wantErr: "successPolicy with operator Never must not set targetReplicatedJobs",
+		},
+		{
+			name: "valid failure policy rules",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					FailurePolicy: &FailurePolicy{
+						MaxRestarts: 3,
+						Rules: []FailurePolicyRule{
+							{Action: FailurePolicyActionRestartJobSet, OnExitCodes: []int32{42}},
+							{Action: FailurePolicyActionFailJobSet, OnExitCodes: []int32{1, 2}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "failure policy rule with exit code out of range",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					FailurePolicy: &FailurePolicy{
+						MaxRestarts: 3,
+						Rules: []FailurePolicyRule{
+							{Action: FailurePolicyActionFailJobSet, OnExitCodes: []int32{256}},
+						},
+					},
+				},
+			},
+			wantErr: "invalid failurePolicy.rules[0]: exit code 256 must be between 1 and 255",
+		},
+		{
+			name: "failure policy rule without exit codes",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					FailurePolicy: &FailurePolicy{
+						MaxRestarts: 3,
+						Level:       FailureLevelPod,
+						Rules: []FailurePolicyRule{
+							{Action: FailurePolicyActionIgnore},
+						},
+					},
+				},
+			},
+			wantErr: "invalid failurePolicy.rules[0]: at least one exit code must be specified",
+		},
+		{
+			name: "ignore failure policy rule without pod failure level",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					FailurePolicy: &FailurePolicy{
+						MaxRestarts: 3,
+						Rules: []FailurePolicyRule{
+							{Action: FailurePolicyActionIgnore, OnExitCodes: []int32{2}},
+						},
+					},
+				},
+			},
+			wantErr: "invalid failurePolicy.rules[0]: action Ignore requires failure level Pod",
+		},
+		{
+			name: "failure policy rule with invalid action",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					FailurePolicy: &FailurePolicy{
+						MaxRestarts: 3,
+						Rules: []FailurePolicyRule{
+							{Action: "Retry", OnExitCodes: []int32{42}},
+						},
+					},
+				},
+			},
+			wantErr: "invalid failurePolicy.rules[0]: action 'Retry' must be one of RestartJobSet, FailJobSet or Ignore",
+		},
+		{
+			name: "contradicting failure policy rules",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					FailurePolicy: &FailurePolicy{
+						MaxRestarts: 3,
+						Rules: []FailurePolicyRule{
+							{Action: FailurePolicyActionRestartJobSet, OnExitCodes: []int32{42}},
+							{Action: FailurePolicyActionFailJobSet, OnExitCodes: []int32{1, 42}},
+						},
+					},
+				},
+			},
+			wantErr: "invalid failurePolicy.rules[1]: exit code 42 is already matched by rules[0] with action RestartJobSet",
+		},
+		{
+			name: "invalid scheduler name",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					SchedulerName:  "My_Scheduler",
+				},
+			},
+			wantErr: "invalid schedulerName 'My_Scheduler'",
+		},
+		{
+			name: "valid scheduler name",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+					SuccessPolicy:  &SuccessPolicy{Operator: OperatorAll},
+					SchedulerName:  "gang-scheduler",
+				},
+			},
+		},
+		{
+			name: "host network combined with DNS hostnames",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:           "rjob",
+							Template:       batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:        &Network{EnableDNSHostnames: pointer.Bool(true)},
+							HostNamespaces: &HostNamespaces{Network: pointer.Bool(true)},
+							Replicas:       1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "replicatedJob 'rjob' uses the host network, which cannot be combined with enableDNSHostnames",
+		},
+		{
+			name: "pod template subdomain combined with DNS hostnames",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name: "rjob",
+							Template: batchv1.JobTemplateSpec{
+								Spec: batchv1.JobSpec{
+									Template: corev1.PodTemplateSpec{
+										Spec: corev1.PodSpec{
+											RestartPolicy: corev1.RestartPolicyNever,
+											Subdomain:     "custom",
+											Containers:    TestPodTemplate.Spec.Containers,
+										},
+									},
+								},
+							},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(true)},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "replicatedJob 'rjob' sets subdomain 'custom' on its pod template, which conflicts with enableDNSHostnames",
+		},
+		{
+			name: "pod template subdomain without DNS hostnames",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name: "rjob",
+							Template: batchv1.JobTemplateSpec{
+								Spec: batchv1.JobSpec{
+									Template: corev1.PodTemplateSpec{
+										Spec: corev1.PodSpec{
+											RestartPolicy: corev1.RestartPolicyNever,
+											Subdomain:     "custom",
+											Containers:    TestPodTemplate.Spec.Containers,
+										},
+									},
+								},
+							},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(false)},
+							Replicas: 1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+		},
+		{
+			name: "host IPC and PID combined with DNS hostnames",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:           "rjob",
+							Template:       batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:        &Network{EnableDNSHostnames: pointer.Bool(true)},
+							HostNamespaces: &HostNamespaces{IPC: pointer.Bool(true), PID: pointer.Bool(true)},
+							Replicas:       1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+		},
+		{
+			name: "no replicated jobs",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "at least one replicatedJob must be specified",
+		},
+		{
+			name: "success policy unset",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: validReplicatedJobs,
+				},
+			},
+			wantErr: "successPolicy must be set",
+		},
+		{
+			name: "negative replicas",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Replicas: -1,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid replicas for replicatedJob 'rjob': -1 must be at least 1",
+		},
+		{
+			name: "job names too long",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(false)},
+							Replicas: 1000,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "replicatedJob 'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa' would create jobs with names longer than 63 characters, such as 'js-aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa-999'",
+		},
+		{
+			name: "job names at the length limit",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  &Network{EnableDNSHostnames: pointer.Bool(false)},
+							Replicas: 100,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+		},
+		{
+			name: "zero replicas",
+			js: &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name:     "rjob",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Replicas: 0,
+						},
+					},
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				},
+			},
+			wantErr: "invalid replicas for replicatedJob 'rjob': 0 must be at least 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.js.ValidateCreate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected validation error containing %q, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDefaultedJobSetCanTerminate(t *testing.T) {
+	js := &JobSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "js"},
+		Spec: JobSetSpec{
+			ReplicatedJobs: []ReplicatedJob{
+				{
+					Name:     "rjob",
+					Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+					Replicas: 1,
+				},
+			},
+		},
+	}
+	js.Default()
+
+	// The defaulted JobSet completes once all of its jobs complete.
+	if js.Spec.SuccessPolicy == nil || js.Spec.SuccessPolicy.Operator != OperatorAll || len(js.Spec.SuccessPolicy.TargetReplicatedJobs) != 0 {
+		t.Errorf("expected success policy targeting all replicated jobs, got: %v", js.Spec.SuccessPolicy)
+	}
+	if err := js.ValidateCreate(); err != nil {
+		t.Errorf("unexpected validation error for defaulted jobset: %v", err)
+	}
+}
+
+func TestDefaultEnableDNSHostnames(t *testing.T) {
+	testCases := []struct {
+		name          string
+		defaultValue  bool
+		network       *Network
+		wantEnableDNS bool
+	}{
+		{
+			name:          "default enabled, network unset",
+			defaultValue:  true,
+			wantEnableDNS: true,
+		},
+		{
+			name:          "default disabled, network unset",
+			defaultValue:  false,
+			wantEnableDNS: false,
+		},
+		{
+			name:          "default disabled, explicitly enabled",
+			defaultValue:  false,
+			network:       &Network{EnableDNSHostnames: pointer.Bool(true)},
+			wantEnableDNS: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			original := DefaultEnableDNSHostnames
+			DefaultEnableDNSHostnames = tc.defaultValue
+			defer func() { DefaultEnableDNSHostnames = original }()
+
+			js := &JobSet{
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Network:  tc.network,
+						},
+					},
+				},
+			}
+			js.Default()
+			if got := pointer.BoolDeref(js.Spec.ReplicatedJobs[0].Network.EnableDNSHostnames, !tc.wantEnableDNS); got != tc.wantEnableDNS {
+				t.Errorf("got enableDNSHostnames %v, want %v", got, tc.wantEnableDNS)
+			}
+		})
+	}
+}
+
+func TestPodFailurePolicy(t *testing.T) {
+	policy := &batchv1.PodFailurePolicy{
+		Rules: []batchv1.PodFailurePolicyRule{{
+			Action: batchv1.PodFailurePolicyActionFailJob,
+			OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+				Operator: batchv1.PodFailurePolicyOnExitCodesOpIn,
+				Values:   []int32{42},
+			},
+		}},
+	}
+	testCases := []struct {
+		name             string
+		enabled          bool
+		podFailurePolicy *batchv1.PodFailurePolicy
+		wantErr          string
+	}{
+		{
+			name:    "unset",
+			enabled: false,
+		},
+		{
+			name:             "set with the feature gate enabled",
+			enabled:          true,
+			podFailurePolicy: policy,
+		},
+		{
+			name:             "set with the feature gate disabled",
+			enabled:          false,
+			podFailurePolicy: policy,
+			wantErr:          "invalid podFailurePolicy for replicatedJob 'rjob': the JobPodFailurePolicy feature gate is not enabled",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			original := PodFailurePolicyEnabled
+			PodFailurePolicyEnabled = tc.enabled
+			defer func() { PodFailurePolicyEnabled = original }()
+
+			js := &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name: "rjob",
+							Template: batchv1.JobTemplateSpec{
+								Spec: batchv1.JobSpec{
+									Template:         TestPodTemplate,
+									PodFailurePolicy: tc.podFailurePolicy.DeepCopy(),
+								},
+							},
+							Replicas: 1,
+						},
+					},
+				},
+			}
+			js.Default()
+			// Defaulting passes the policy through as is, leaving it nil when unset.
+			if diff := cmp.Diff(tc.podFailurePolicy, js.Spec.ReplicatedJobs[0].Template.Spec.PodFailurePolicy); diff != "" {
+				t.Errorf("unexpected podFailurePolicy after defaulting (-want +got):\n%s", diff)
+			}
+			err := js.ValidateCreate()
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("got error %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaxTotalPods(t *testing.T) {
+	testCases := []struct {
+		name         string
+		maxTotalPods int
+		parallelism  int32
+		wantErr      string
+	}{
+		{
+			name:         "within the limit",
+			maxTotalPods: 100,
+			parallelism:  10,
+		},
+		{
+			name:         "exceeds the limit",
+			maxTotalPods: 100,
+			parallelism:  20,
+			wantErr:      "it runs 120 pods in total (replicas times parallelism, summed over replicatedJobs), which exceeds the maximum of 100",
+		},
+		{
+			name:         "limit disabled",
+			maxTotalPods: 0,
+			parallelism:  1000000,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			original := MaxTotalPods
+			MaxTotalPods = tc.maxTotalPods
+			defer func() { MaxTotalPods = original }()
+
+			js := &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec: JobSetSpec{
+					SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+					// The workers take the JobSet default parallelism, the driver its own.
+					JobDefaults: &JobDefaults{Parallelism: pointer.Int32(tc.parallelism)},
+					ReplicatedJobs: []ReplicatedJob{
+						{
+							Name: "driver",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{
+								Template:    TestPodTemplate,
+								Parallelism: pointer.Int32(20),
+							}},
+							Replicas: 1,
+						},
+						{
+							Name:     "workers",
+							Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+							Replicas: 5,
+						},
+					},
+				},
+			}
+			err := js.ValidateCreate()
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("got error %v, want error containing %q", err, tc.wantErr)
+			}
+
+			// Overriding the parallelism of the workers while suspended is limited alike.
+			old := js.DeepCopy()
+			old.Spec.Suspend = pointer.Bool(true)
+			old.Spec.JobDefaults.Parallelism = pointer.Int32(1)
+			updated := old.DeepCopy()
+			updated.Spec.ParallelismOverrides = map[string]int32{"workers": tc.parallelism}
+			err = updated.ValidateUpdate(old)
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("unexpected error on update: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("got error %v on update, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaxReplicatedJobs(t *testing.T) {
+	testCases := []struct {
+		name              string
+		maxReplicatedJobs int
+		replicatedJobs    int
+		wantErr           string
+	}{
+		{
+			name:              "at the limit",
+			maxReplicatedJobs: 3,
+			replicatedJobs:    3,
+		},
+		{
+			name:              "exceeds the limit",
+			maxReplicatedJobs: 3,
+			replicatedJobs:    4,
+			wantErr:           "it defines 4 replicatedJobs, which exceeds the maximum of 3",
+		},
+		{
+			name:              "limit disabled",
+			maxReplicatedJobs: 0,
+			replicatedJobs:    4,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			original := MaxReplicatedJobs
+			MaxReplicatedJobs = tc.maxReplicatedJobs
+			defer func() { MaxReplicatedJobs = original }()
+
+			js := &JobSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "js"},
+				Spec:       JobSetSpec{SuccessPolicy: &SuccessPolicy{Operator: OperatorAll}},
+			}
+			for i := 0; i < tc.replicatedJobs; i++ {
+				js.Spec.ReplicatedJobs = append(js.Spec.ReplicatedJobs, ReplicatedJob{
+					Name:     fmt.Sprintf("rjob-%d", i),
+					Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: TestPodTemplate}},
+					Replicas: 1,
+				})
+			}
+			err := js.ValidateCreate()
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("got error %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultingEvent(t *testing.T) {
+	makeJobSet := func() *JobSet {
+		return &JobSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "js"},
+			Spec: JobSetSpec{
+				SuccessPolicy: &SuccessPolicy{Operator: OperatorAll},
+				FailurePolicy: &FailurePolicy{MaxRestarts: 1},
+				ReplicatedJobs: []ReplicatedJob{
+					{
+						Name: "rjob",
+						Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{
+							CompletionMode: completionModePtr(batchv1.IndexedCompletion),
+							Template:       TestPodTemplate,
+						}},
+						Replicas: 1,
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name      string
+		operation admissionv1.Operation
+		dryRun    bool
+		wantEvent string
+	}{
+		{
+			name:      "create",
+			operation: admissionv1.Create,
+			wantEvent: "Warning DefaultsApplied defaulted fields omitted from the spec: " +
+				"spec.replicatedJobs[rjob].network.enableDNSHostnames",
+		},
+		{
+			name:      "dry run create",
+			operation: admissionv1.Create,
+			dryRun:    true,
+		},
+		{
+			name:      "update",
+			operation: admissionv1.Update,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			defaulter := &jobSetDefaulter{recorder: recorder}
+			ctx := admission.NewContextWithRequest(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: tc.operation,
+				DryRun:    pointer.Bool(tc.dryRun),
+			}})
+			js := makeJobSet()
+			if err := defaulter.Default(ctx, js); err != nil {
+				t.Fatalf("Default() error = %v", err)
+			}
+			if got := pointer.BoolDeref(js.Spec.ReplicatedJobs[0].Network.EnableDNSHostnames, false); got != DefaultEnableDNSHostnames {
+				t.Errorf("got enableDNSHostnames %t, want the default %t", got, DefaultEnableDNSHostnames)
+			}
+			select {
+			case got := <-recorder.Events:
+				if got != tc.wantEvent {
+					t.Errorf("got event %q, want %q", got, tc.wantEvent)
+				}
+			default:
+				if tc.wantEvent != "" {
+					t.Errorf("expected an event listing the applied defaults")
+				}
+			}
+
+			// Defaulting an already defaulted JobSet applies no defaults, and records no event.
+			if err := defaulter.Default(ctx, js); err != nil {
+				t.Fatalf("Default() error = %v", err)
+			}
+			select {
+			case got := <-recorder.Events:
+				t.Errorf("unexpected event %q", got)
+			default:
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JobName returns the name of the child job with the given index of the replicated job in
+// the given restart attempt, in the format <jobSet.name>-<replicatedJob.name>-<job-index>
+// with the prefix, suffix and separator of the job naming of the JobSet, if any. With the
+// BlueGreen restart strategy, the jobs of each restart attempt after the first have distinct
+// names suffixed with the attempt, so they can be created while the jobs of the previous
+// attempt still exist.
+func JobName(js *JobSet, rjob *ReplicatedJob, jobIndex int, attempt int) string {
+	sep := NameSeparator(js)
+	name := strings.Join([]string{js.Name, rjob.Name, strconv.Itoa(jobIndex)}, sep)
+	if naming := js.Spec.JobNaming; naming != nil {
+		name = naming.Prefix + name + naming.Suffix
+	}
+	if fp := js.Spec.FailurePolicy; fp != nil && fp.RestartStrategy == RestartStrategyBlueGreen && attempt > 0 {
+		name = fmt.Sprintf("%s%sr%d", name, sep, attempt)
+	}
+	return name
+}
+
+// NameSeparator returns the separator joining the parts of the names of the child jobs and
+// EndpointSlices of the JobSet.
+func NameSeparator(js *JobSet) string {
+	if js.Spec.JobNaming == nil || js.Spec.JobNaming.Separator == "" {
+		return "-"
+	}
+	return js.Spec.JobNaming.Separator
+}
@@ -19,21 +19,27 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha1"
 	"sigs.k8s.io/jobset/pkg/controllers"
+	"sigs.k8s.io/jobset/pkg/metrics"
+	"sigs.k8s.io/jobset/pkg/notifier"
 	"sigs.k8s.io/jobset/pkg/util/cert"
+	"sigs.k8s.io/jobset/pkg/util/predicates"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -47,17 +53,68 @@ func init() {
 
 	utilruntime.Must(jobset.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
+
+	metrics.Register()
 }
 
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var namespace string
+	var podsPendingThreshold time.Duration
+	var imagePullFailureThreshold int
+	var statusUpdateRetries int
+	var notifierURL string
+	var notifierRetries int
+	var controllingOwnerReferences bool
+	var syncPeriod time.Duration
+	var annotateResourceVersion bool
+	var progressEventInterval time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&namespace, "namespace", "",
+		"Namespace that the controller watches to reconcile JobSets. "+
+			"If unset, the controller watches all namespaces.")
+	flag.DurationVar(&podsPendingThreshold, "pods-pending-threshold", 5*time.Minute,
+		"Duration after which pending pods are reported in the PodsPending condition of their JobSet.")
+	flag.IntVar(&imagePullFailureThreshold, "image-pull-failure-threshold", 1,
+		"Number of pods failing to pull their images at which the ImagePullFailure condition is set on their JobSet.")
+	flag.BoolVar(&jobset.DefaultEnableDNSHostnames, "default-enable-dns-hostnames", true,
+		"Value the defaulting webhook sets for enableDNSHostnames on replicated jobs that leave it unset.")
+	flag.BoolVar(&jobset.PodFailurePolicyEnabled, "pod-failure-policy-enabled", true,
+		"Whether the cluster has the JobPodFailurePolicy feature gate enabled. If disabled, "+
+			"JobSets whose job templates set a podFailurePolicy are rejected.")
+	flag.IntVar(&jobset.MaxTotalPods, "max-total-pods", 50000,
+		"Maximum number of pods a JobSet may run at once, summed over its replicated jobs. "+
+			"JobSets exceeding it are rejected. Zero disables the limit.")
+	flag.IntVar(&jobset.MaxReplicatedJobs, "max-replicated-jobs", 100,
+		"Maximum number of replicated jobs a JobSet may define. "+
+			"JobSets exceeding it are rejected. Zero disables the limit.")
+	flag.IntVar(&statusUpdateRetries, "status-update-retries", 5,
+		"Number of times a JobSet status update is attempted when it conflicts with a concurrent update.")
+	flag.BoolVar(&jobset.RecordDefaultingEvents, "record-defaulting-events", false,
+		"Record an event on each created JobSet listing the fields the defaulting webhook set because they were omitted.")
+	flag.StringVar(&notifierURL, "notifier-url", "",
+		"URL to which JobSet state changes (created, completed, failed) are posted as JSON. "+
+			"If unset, no notifications are sent.")
+	flag.IntVar(&notifierRetries, "notifier-retries", 3,
+		"Number of times the delivery of a JobSet state change to the notifier URL is attempted.")
+	flag.BoolVar(&controllingOwnerReferences, "controlling-owner-references", true,
+		"Set JobSets as the controller in the owner references of their child jobs, services and endpoint slices. "+
+			"Disable to let another controller control the children, which are still garbage collected along with their JobSet.")
+	flag.DurationVar(&syncPeriod, "sync-period", 10*time.Hour,
+		"Period after which the informers resync, so all watched JobSets and their children are reconciled "+
+			"again even if events were missed.")
+	flag.BoolVar(&annotateResourceVersion, "annotate-jobset-resource-version", false,
+		"Annotate child jobs with the resourceVersion of their JobSet at the time they were created, "+
+			"to help debug which version of the JobSet spec produced them.")
+	flag.DurationVar(&progressEventInterval, "progress-event-interval", 10*time.Minute,
+		"Interval at which a Progressing event summarizing the completed child jobs is recorded on running JobSets. "+
+			"Zero disables progress events.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -66,30 +123,21 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
-		MetricsBindAddress:     metricsAddr,
-		Port:                   9443,
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "6d4f6a47.x-k8s.io",
-		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
-		// when the Manager ends. This requires the binary to immediately end when the
-		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
-		// speeds up voluntary leader transitions as the new leader don't have to wait
-		// LeaseDuration time first.
-		//
-		// In the default scaffold provided, the program ends immediately after
-		// the manager stops, so would be fine to enable this option. However,
-		// if you are doing or is intended to do any operation such as perform cleanups
-		// after the manager stops then its usage might be unsafe.
-		// LeaderElectionReleaseOnCancel: true,
-	})
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), managerOptions(metricsAddr, probeAddr, namespace, enableLeaderElection, syncPeriod))
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	var jobSetNotifier *notifier.Notifier
+	if notifierURL != "" {
+		jobSetNotifier = notifier.New(notifierURL, notifierRetries)
+		if err := mgr.Add(jobSetNotifier); err != nil {
+			setupLog.Error(err, "unable to add notifier to manager")
+			os.Exit(1)
+		}
+	}
+
 	certsReady := make(chan struct{})
 	if err = cert.CertsManager(mgr, certsReady); err != nil {
 		setupLog.Error(err, "unable to setup cert rotation")
@@ -104,7 +152,7 @@ func main() {
 	// Cert won't be ready until manager starts, so start a goroutine here which
 	// will block until the cert is ready before setting up the controllers.
 	// Controllers who register after manager starts will start directly.
-	go setupControllers(mgr, certsReady)
+	go setupControllers(mgr, certsReady, podsPendingThreshold, imagePullFailureThreshold, statusUpdateRetries, jobSetNotifier, controllingOwnerReferences, annotateResourceVersion, progressEventInterval)
 
 	setupHealthzAndReadyzCheck(mgr)
 
@@ -115,7 +163,39 @@ func main() {
 	}
 }
 
-func setupControllers(mgr ctrl.Manager, certsReady chan struct{}) {
+// managerOptions returns the options of the controller manager.
+func managerOptions(metricsAddr, probeAddr, namespace string, enableLeaderElection bool, syncPeriod time.Duration) ctrl.Options {
+	return ctrl.Options{
+		Scheme:                 scheme,
+		MetricsBindAddress:     metricsAddr,
+		Port:                   9443,
+		HealthProbeBindAddress: probeAddr,
+		LeaderElection:         enableLeaderElection,
+		LeaderElectionID:       "6d4f6a47.x-k8s.io",
+		Namespace:              namespace,
+		SyncPeriod:             &syncPeriod,
+		// Only the pods of JobSets are watched, so only they are cached rather than all
+		// pods of the cluster.
+		NewCache: cache.BuilderWithOptions(cache.Options{
+			SelectorsByObject: cache.SelectorsByObject{
+				&corev1.Pod{}: {Label: predicates.JobSetLabeledSelector()},
+			},
+		}),
+		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
+		// when the Manager ends. This requires the binary to immediately end when the
+		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
+		// speeds up voluntary leader transitions as the new leader don't have to wait
+		// LeaseDuration time first.
+		//
+		// In the default scaffold provided, the program ends immediately after
+		// the manager stops, so would be fine to enable this option. However,
+		// if you are doing or is intended to do any operation such as perform cleanups
+		// after the manager stops then its usage might be unsafe.
+		// LeaderElectionReleaseOnCancel: true,
+	}
+}
+
+func setupControllers(mgr ctrl.Manager, certsReady chan struct{}, podsPendingThreshold time.Duration, imagePullFailureThreshold, statusUpdateRetries int, jobSetNotifier *notifier.Notifier, controllingOwnerReferences, annotateResourceVersion bool, progressEventInterval time.Duration) {
 	// The controllers won't work until the webhooks are operating,
 	// and the webhook won't work until the certs are all in places.
 	setupLog.Info("waiting for the cert generation to complete")
@@ -123,6 +203,13 @@ func setupControllers(mgr ctrl.Manager, certsReady chan struct{}) {
 	setupLog.Info("certs ready")
 
 	jobSetController := controllers.NewJobSetReconciler(mgr.GetClient(), mgr.GetScheme(), mgr.GetEventRecorderFor("jobset"))
+	jobSetController.PodsPendingThreshold = podsPendingThreshold
+	jobSetController.ImagePullFailureThreshold = imagePullFailureThreshold
+	jobSetController.StatusUpdateRetries = statusUpdateRetries
+	jobSetController.Notifier = jobSetNotifier
+	jobSetController.NonControllingOwnerReferences = !controllingOwnerReferences
+	jobSetController.AnnotateResourceVersion = annotateResourceVersion
+	jobSetController.ProgressEventInterval = progressEventInterval
 	if err := jobSetController.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "JobSet")
 		os.Exit(1)
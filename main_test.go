@@ -0,0 +1,30 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManagerOptionsSyncPeriod(t *testing.T) {
+	opts := managerOptions(":8080", ":8081", "", false, 30*time.Minute)
+	if opts.SyncPeriod == nil || *opts.SyncPeriod != 30*time.Minute {
+		t.Errorf("got sync period %v, want %v", opts.SyncPeriod, 30*time.Minute)
+	}
+	if opts.NewCache == nil {
+		t.Errorf("expected the cache to be scoped to the pods of JobSets")
+	}
+}
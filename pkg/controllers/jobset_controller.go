@@ -17,34 +17,110 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
+	"github.com/go-logr/logr"
+	"gopkg.in/inf.v0"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha1"
+	"sigs.k8s.io/jobset/pkg/metrics"
+	"sigs.k8s.io/jobset/pkg/notifier"
 	util "sigs.k8s.io/jobset/pkg/util/collections"
+	"sigs.k8s.io/jobset/pkg/util/predicates"
 )
 
 const (
 	RestartsKey       string = "jobset.sigs.k8s.io/restart-attempt"
 	parallelDeletions int    = 50
+
+	// LabelSchemaVersionKey is the label recording the version of the reserved label
+	// schema applied to a child Job or pod, so children yet to be migrated can be selected.
+	LabelSchemaVersionKey string = "jobset.sigs.k8s.io/label-schema-version"
+	// labelSchemaVersion is the current version of the reserved label schema. It must be
+	// bumped whenever the labels set by labelAndAnnotateObject change, so existing
+	// children get migrated.
+	labelSchemaVersion string = "1"
+
+	// JobSetResourceVersionKey is the annotation recording the resourceVersion of the
+	// JobSet when a child Job was created, for debugging which spec produced it.
+	JobSetResourceVersionKey string = "jobset.sigs.k8s.io/jobset-resource-version"
+
+	// IgnoredFailuresKey is the annotation recording the number of failed pods of a child
+	// Job whose failures were ignored by a rule of the failure policy, so the pods of the
+	// Job are only listed again once more of them fail.
+	IgnoredFailuresKey string = "jobset.sigs.k8s.io/ignored-failures"
+
+	// defaultImagePullFailureThreshold is the number of pods failing to pull their images
+	// at which the ImagePullFailure condition is set, unless configured otherwise.
+	defaultImagePullFailureThreshold = 1
+
+	// defaultPodsPendingThreshold is the duration after which pending pods are reported
+	// in the PodsPending condition, unless configured otherwise.
+	defaultPodsPendingThreshold = 5 * time.Minute
+
+	// capacityRecheckInterval is how often the capacity of the cluster is checked again
+	// while a JobSet is waiting for capacity, since node changes do not trigger reconciles.
+	capacityRecheckInterval = 30 * time.Second
+
+	// quotaRecheckInterval is how often the resource quotas of the namespace are checked
+	// again while a JobSet is suspended for exceeding them.
+	quotaRecheckInterval = 30 * time.Second
+
+	// endpointSliceManagedBy identifies the EndpointSlices managed by the JobSet controller.
+	endpointSliceManagedBy string = "jobset.sigs.k8s.io"
+
+	// maxReconcileErrorMessageLength bounds the length of the message of the
+	// ReconcileError condition.
+	maxReconcileErrorMessageLength int = 1024
+
+	// Reasons of the events recorded for lifecycle transitions which are not reported
+	// by a condition of the JobSet.
+	eventReasonJobCreated             string = "JobCreated"
+	eventReasonSuccessPolicySatisfied string = "SuccessPolicySatisfied"
+	eventReasonFailurePolicyTriggered string = "FailurePolicyTriggered"
+	eventReasonProgressing            string = "Progressing"
 )
 
+// imagePullFailureReasons are the container waiting reasons reported by the
+// kubelet when it cannot pull an image.
+var imagePullFailureReasons = []string{"ErrImagePull", "ImagePullBackOff", "InvalidImageName"}
+
 var (
 	jobOwnerKey = ".metadata.controller"
-	apiGVStr    = jobset.GroupVersion.String()
+	// jobOwnerReferenceKey indexes jobs by their JobSet owner, whether or not it is their
+	// controller, to find the children of JobSets set up as non-controlling owners.
+	jobOwnerReferenceKey = ".metadata.ownerReferences.jobset"
 )
 
 // JobSetReconciler reconciles a JobSet object
@@ -52,6 +128,53 @@ type JobSetReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Record record.EventRecorder
+
+	// PodsPendingThreshold is the duration after which pending pods are reported in the
+	// PodsPending condition of their JobSet. Defaults to 5 minutes.
+	PodsPendingThreshold time.Duration
+
+	// ImagePullFailureThreshold is the number of pods failing to pull their images at which
+	// the ImagePullFailure condition is set on their JobSet. Defaults to 1.
+	ImagePullFailureThreshold int
+
+	// StatusUpdateRetries is the number of times a JobSet status update is attempted when
+	// it conflicts with a concurrent update of the JobSet which left its status unchanged.
+	// Defaults to the client-go default retry steps.
+	StatusUpdateRetries int
+
+	// NonControllingOwnerReferences makes the JobSet a non-controlling owner of its child
+	// jobs, services and endpoint slices, so another controller can be their controller.
+	// The children are still garbage collected along with the JobSet.
+	NonControllingOwnerReferences bool
+
+	// AnnotateResourceVersion annotates child jobs with the resourceVersion of their
+	// JobSet at the time they were created.
+	AnnotateResourceVersion bool
+
+	// Notifier, if set, is notified when the child jobs of a JobSet are first created and
+	// when the JobSet completes or fails.
+	Notifier *notifier.Notifier
+
+	// ProgressEventInterval is how often a Progressing event summarizing the completed child
+	// jobs is recorded on running JobSets, so users watching events of long runs see them
+	// progress. Zero disables progress events.
+	ProgressEventInterval time.Duration
+
+	// progressEvents holds the time the last Progressing event was recorded on each
+	// running JobSet, or it was first seen running, to throttle the events.
+	progressEvents sync.Map
+
+	// log is the logger of the manager, for the event handlers which have no context to
+	// get a logger from.
+	log logr.Logger
+}
+
+// jobSetReconciliation is a single reconciliation of a JobSet. It tracks the status of the
+// JobSet last read or written, from which the status updates of the reconciliation are
+// computed.
+type jobSetReconciliation struct {
+	*JobSetReconciler
+	statusBase jobset.JobSetStatus
 }
 
 type childJobs struct {
@@ -63,12 +186,21 @@ type childJobs struct {
 
 	// Jobs marked for deletion are mutually exclusive with the set of jobs in active, successful, and failed.
 	delete []*batchv1.Job
+
+	// Jobs of replicated jobs removed from the spec while the JobSet was suspended. They
+	// are deleted once the JobSet is resumed, and are not part of any other set.
+	removed []*batchv1.Job
 }
 
 func NewJobSetReconciler(client client.Client, scheme *runtime.Scheme, record record.EventRecorder) *JobSetReconciler {
 	return &JobSetReconciler{Client: client, Scheme: scheme, Record: record}
 }
 
+// newReconciliation starts a reconciliation of the JobSet as read by the reconciler.
+func (r *JobSetReconciler) newReconciliation(js *jobset.JobSet) *jobSetReconciliation {
+	return &jobSetReconciliation{JobSetReconciler: r, statusBase: *js.Status.DeepCopy()}
+}
+
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;watch;update
 //+kubebuilder:rbac:groups=jobset.x-k8s.io,resources=jobsets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=jobset.x-k8s.io,resources=jobsets/status,verbs=get;update;patch
@@ -76,13 +208,24 @@ func NewJobSetReconciler(client client.Client, scheme *runtime.Scheme, record re
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=batch,resources=jobs/status,verbs=get
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=core,resources=resourcequotas,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *JobSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	defer metrics.ReconcileDone(req.Namespace, time.Now())
+
 	// Get JobSet from apiserver.
 	var js jobset.JobSet
 	if err := r.Get(ctx, req.NamespacedName, &js); err != nil {
+		if apierrors.IsNotFound(err) {
+			metrics.SetActive(req.NamespacedName, false)
+			r.progressEvents.Delete(req.NamespacedName)
+		}
 		// we'll ignore not-found errors, since there is nothing we can do here.
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
@@ -91,40 +234,124 @@ func (r *JobSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	ctx = ctrl.LoggerInto(ctx, log)
 	log.V(2).Info("Reconciling JobSet")
 
+	rec := r.newReconciliation(&js)
+	result, reconcileErr := rec.reconcile(ctx, &js)
+	if reconcileErr != nil {
+		// The JobSet may have been updated while reconciling, so record the error on its latest version.
+		if err := r.Get(ctx, req.NamespacedName, &js); err != nil {
+			log.Error(err, "getting jobset to record reconcile error")
+			return ctrl.Result{}, reconcileErr
+		}
+		rec = r.newReconciliation(&js)
+	}
+	metrics.SetActive(req.NamespacedName, !jobSetFinished(&js))
+	if jobSetFinished(&js) {
+		r.progressEvents.Delete(req.NamespacedName)
+	}
+	// Reconcile again when the active deadline of the JobSet is reached, if it is running.
+	if remaining, ok := activeDeadlineRemaining(&js, time.Now()); ok && remaining > 0 && !jobSetFinished(&js) {
+		if result.RequeueAfter == 0 || remaining < result.RequeueAfter {
+			result.RequeueAfter = remaining
+		}
+	}
+	if err := rec.updateReconcileErrorCondition(ctx, &js, reconcileErr); err != nil {
+		log.Error(err, "updating reconcile error condition")
+		if reconcileErr == nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return result, reconcileErr
+}
+
+// reconcile runs a single reconciliation of the given JobSet.
+func (r *jobSetReconciliation) reconcile(ctx context.Context, js *jobset.JobSet) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Migrate children created with an older label schema before categorizing them by their labels.
+	if err := r.migrateChildLabels(ctx, js); err != nil {
+		log.Error(err, "migrating labels of jobs owned by jobset")
+		return ctrl.Result{}, err
+	}
+
+	// Remove duplicate owner references to the JobSet, which confuse garbage collection.
+	if err := r.dedupeChildOwnerReferences(ctx, js); err != nil {
+		log.Error(err, "removing duplicate owner references of jobset children")
+		return ctrl.Result{}, err
+	}
+
 	// Get Jobs owned by JobSet.
-	ownedJobs, err := r.getChildJobs(ctx, &js)
+	ownedJobs, err := r.getChildJobs(ctx, js)
 	if err != nil {
 		log.Error(err, "getting jobs owned by jobset")
 		return ctrl.Result{}, err
 	}
 
-	// If JobSet is already completed or failed, clean up active child jobs.
-	if jobSetFinished(&js) {
-		if err := r.deleteJobs(ctx, ownedJobs.active); err != nil {
+	// Restart a failed JobSet whose container images were updated, e.g. to fix a bad image.
+	restarted, err := r.restartFailedJobSetWithUpdatedImages(ctx, js, ownedJobs)
+	if err != nil {
+		log.Error(err, "restarting failed jobset with updated container images")
+		return ctrl.Result{}, err
+	}
+	if restarted {
+		return ctrl.Result{}, nil
+	}
+
+	// If JobSet is already completed or failed, clean up active child jobs,
+	// and delete the JobSet itself once its TTL after finishing expires.
+	if jobSetFinished(js) {
+		if err := r.deleteJobs(ctx, util.Concat(ownedJobs.active, unfinishedJobs(ownedJobs.failed))); err != nil {
+			log.Error(err, "deleting jobs")
+			return ctrl.Result{}, err
+		}
+		if err := r.ensureCondition(ctx, js, corev1.EventTypeNormal, readyCondition(false)); err != nil {
+			log.Error(err, "updating ready condition")
+			return ctrl.Result{}, err
+		}
+		requeueAfter, err := r.deleteExpiredJobSet(ctx, js)
+		if err != nil {
+			log.Error(err, "deleting expired jobset")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	// Fail the JobSet and delete its child jobs once it has been active past its deadline.
+	if remaining, ok := activeDeadlineRemaining(js, time.Now()); ok && remaining <= 0 {
+		if err := r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
+			Type:    string(jobset.JobSetFailed),
+			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+			Reason:  reasonDeadlineExceeded,
+			Message: fmt.Sprintf("jobset was active longer than its deadline of %d seconds", *js.Spec.ActiveDeadlineSeconds),
+		}); err != nil {
+			log.Error(err, "failing jobset past its active deadline")
+			return ctrl.Result{}, err
+		}
+		if err := r.deleteJobs(ctx, util.Concat(ownedJobs.active, unfinishedJobs(ownedJobs.failed))); err != nil {
 			log.Error(err, "deleting jobs")
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
 	}
 
-	// Delete any jobs marked for deletion.
-	if err := r.deleteJobs(ctx, ownedJobs.delete); err != nil {
+	// Delete any jobs marked for deletion, keeping within the restart disruption budget.
+	if err := r.deleteJobs(ctx, restartDeletions(js, ownedJobs)); err != nil {
 		log.Error(err, "deleting jobs")
 		return ctrl.Result{}, err
 	}
 
 	// If any jobs have failed, execute the JobSet failure policy (if any).
 	if len(ownedJobs.failed) > 0 {
-		if err := r.executeFailurePolicy(ctx, &js, ownedJobs); err != nil {
+		requeueAfter, err := r.executeFailurePolicy(ctx, js, ownedJobs)
+		if err != nil {
 			log.Error(err, "executing failure policy")
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, nil
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
-	// If any jobs have succeeded, execute the JobSet success policy.
-	if len(ownedJobs.successful) > 0 {
-		completed, err := r.executeSuccessPolicy(ctx, &js, ownedJobs)
+	// If any jobs have succeeded or completion was requested, execute the JobSet success policy.
+	if len(ownedJobs.successful) > 0 || js.Annotations[jobset.CompleteNowKey] == "true" {
+		completed, err := r.executeSuccessPolicy(ctx, js, ownedJobs)
 		if err != nil {
 			log.Error(err, "executing success policy")
 			return ctrl.Result{}, err
@@ -134,57 +361,259 @@ func (r *JobSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		}
 	}
 
+	// If requested, wait for enough schedulable capacity before starting the child jobs.
+	waiting, err := r.waitForCapacity(ctx, js, ownedJobs)
+	if err != nil {
+		log.Error(err, "checking cluster capacity")
+		return ctrl.Result{}, err
+	}
+	if waiting {
+		return ctrl.Result{RequeueAfter: capacityRecheckInterval}, nil
+	}
+
+	// Wait for older JobSets sharing the concurrency key to finish, if any.
+	queued, err := r.queueForConcurrencyKey(ctx, js, ownedJobs)
+	if err != nil {
+		log.Error(err, "checking concurrency key")
+		return ctrl.Result{}, err
+	}
+	if queued {
+		return ctrl.Result{}, nil
+	}
+
+	// If requested, hold the child jobs suspended while the namespace quota is exceeded.
+	held, err := r.holdForQuota(ctx, js, ownedJobs)
+	if err != nil {
+		log.Error(err, "checking resource quotas")
+		return ctrl.Result{}, err
+	}
+	if held {
+		return ctrl.Result{RequeueAfter: quotaRecheckInterval}, nil
+	}
+
 	// If job has not failed or succeeded, continue creating any
 	// jobs that are ready to be started.
-	if err := r.createJobs(ctx, &js, ownedJobs); err != nil {
+	if err := r.createJobs(ctx, js, ownedJobs); err != nil {
+		if suspendOnQuotaExceeded(js) && quotaExceededError(err) {
+			if err := r.suspendForQuota(ctx, js, ownedJobs, err.Error()); err != nil {
+				log.Error(err, "suspending jobset for exceeded quota")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: quotaRecheckInterval}, nil
+		}
 		log.Error(err, "creating jobs")
 		return ctrl.Result{}, err
 	}
 
+	// Report a success policy which can no longer be satisfied, rather than waiting forever.
+	if err := r.updateUnsatisfiableSuccessPolicyCondition(ctx, js, ownedJobs); err != nil {
+		log.Error(err, "updating unsatisfiable success policy condition")
+		return ctrl.Result{}, err
+	}
+
+	// Report whether the jobs of a previous restart attempt are still being torn down.
+	if err := r.updateRestartingCondition(ctx, js, ownedJobs); err != nil {
+		log.Error(err, "updating restarting condition")
+		return ctrl.Result{}, err
+	}
+
 	// Handle suspending a jobset or resuming a suspended jobset.
 	jobsetSuspended := js.Spec.Suspend != nil && *js.Spec.Suspend
 	if jobsetSuspended {
-		if err := r.suspendJobSet(ctx, &js, ownedJobs); err != nil {
+		if err := r.suspendJobSet(ctx, js, ownedJobs); err != nil {
 			log.Error(err, "suspending jobset")
 			return ctrl.Result{}, err
 		}
 	} else {
-		if err := r.resumeJobSetIfNecessary(ctx, &js, ownedJobs); err != nil {
+		// Delete the jobs of replicated jobs removed from the spec while the JobSet was suspended.
+		if err := r.deleteJobs(ctx, ownedJobs.removed); err != nil {
+			log.Error(err, "deleting jobs of removed replicated jobs")
+			return ctrl.Result{}, err
+		}
+		if err := r.resumeJobSetIfNecessary(ctx, js, ownedJobs); err != nil {
 			log.Error(err, "resuming jobset")
 			return ctrl.Result{}, err
 		}
 	}
 	// Calculate JobsReady and update statuses for each ReplicatedJob
-	if err := r.calculateAndUpdateReplicatedJobsStatuses(ctx, &js, ownedJobs); err != nil {
+	if err := r.calculateAndUpdateReplicatedJobsStatuses(ctx, js, ownedJobs); err != nil {
 		log.Error(err, "updating replicated jobs statuses")
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	// Advise when not all indices of DNS-enabled jobs run at the same time.
+	if err := r.updateCompletionsExceedParallelismCondition(ctx, js); err != nil {
+		log.Error(err, "updating completions exceed parallelism condition")
+		return ctrl.Result{}, err
+	}
+
+	// Surface a coordinator which no longer identifies a pod, and is therefore not injected.
+	if err := r.updateInvalidCoordinatorCondition(ctx, js); err != nil {
+		log.Error(err, "updating invalid coordinator condition")
+		return ctrl.Result{}, err
+	}
+
+	// Publish the addresses of ready pods in EndpointSlices, if requested.
+	if err := r.syncEndpointSlices(ctx, js); err != nil {
+		log.Error(err, "syncing endpoint slices")
+		return ctrl.Result{}, err
+	}
+
+	// Annotate pods with their stable DNS hostnames, if requested.
+	if err := r.annotatePodHostnames(ctx, js); err != nil {
+		log.Error(err, "annotating pod hostnames")
+		return ctrl.Result{}, err
+	}
+
+	// Surface pods which are stuck pending, e.g. because they are unschedulable.
+	requeueAfter, err := r.updatePodsPendingCondition(ctx, js)
+	if err != nil {
+		log.Error(err, "updating pods pending condition")
+		return ctrl.Result{}, err
+	}
+
+	// Surface pods which are stuck pulling their images.
+	if err := r.updateImagePullFailureCondition(ctx, js); err != nil {
+		log.Error(err, "updating image pull failure condition")
+		return ctrl.Result{}, err
+	}
+
+	// Periodically summarize the progress of long runs, and reconcile again when it is due.
+	if nextProgressEvent := r.recordProgressEvent(js, ownedJobs, time.Now()); nextProgressEvent > 0 && (requeueAfter == 0 || nextProgressEvent < requeueAfter) {
+		requeueAfter = nextProgressEvent
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// recordProgressEvent records a Progressing event on the running JobSet summarizing how many
+// of its child jobs have completed, at most once per ProgressEventInterval. The first event
+// is recorded one interval after the JobSet is first seen running. It returns how long
+// remains until the next event is due, or zero if no event is due, because progress events
+// are disabled or the JobSet is suspended.
+func (r *JobSetReconciler) recordProgressEvent(js *jobset.JobSet, ownedJobs *childJobs, now time.Time) time.Duration {
+	if r.ProgressEventInterval <= 0 || pointer.BoolDeref(js.Spec.Suspend, false) {
+		return 0
+	}
+	key := types.NamespacedName{Namespace: js.Namespace, Name: js.Name}
+	last, seen := r.progressEvents.LoadOrStore(key, now)
+	if !seen {
+		return r.ProgressEventInterval
+	}
+	if next := last.(time.Time).Add(r.ProgressEventInterval); now.Before(next) {
+		return next.Sub(now)
+	}
+	total := 0
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		total += rjob.Replicas
+	}
+	r.Record.Eventf(js, corev1.EventTypeNormal, eventReasonProgressing, "%d/%d jobs completed", len(ownedJobs.successful), total)
+	r.progressEvents.Store(key, now)
+	return r.ProgressEventInterval
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *JobSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.log = mgr.GetLogger()
+	ownerHandler := &handler.EnqueueRequestForOwner{OwnerType: &jobset.JobSet{}, IsController: !r.NonControllingOwnerReferences}
+	jobPredicate := predicates.JobSetOwnedPredicate()
+	if r.NonControllingOwnerReferences {
+		jobPredicate = predicates.JobSetOwnerReferencePredicate()
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&jobset.JobSet{}).
-		Owns(&batchv1.Job{}).
-		Owns(&corev1.Service{}).
+		Watches(&source.Kind{Type: &batchv1.Job{}}, ownerHandler, builder.WithPredicates(jobPredicate)).
+		Watches(&source.Kind{Type: &corev1.Service{}}, ownerHandler).
+		Watches(&source.Kind{Type: &corev1.ServiceAccount{}}, ownerHandler).
+		Watches(&source.Kind{Type: &discoveryv1.EndpointSlice{}}, ownerHandler).
+		Watches(&source.Kind{Type: &corev1.Pod{}}, handler.EnqueueRequestsFromMapFunc(podToJobSet), builder.WithPredicates(predicates.JobSetLabeledPredicate())).
+		Watches(&source.Kind{Type: &jobset.JobSet{}}, handler.EnqueueRequestsFromMapFunc(r.jobSetsSharingConcurrencyKey)).
 		Complete(r)
 }
 
-func SetupIndexes(ctx context.Context, indexer client.FieldIndexer) error {
-	return indexer.IndexField(ctx, &batchv1.Job{}, jobOwnerKey, func(obj client.Object) []string {
-		o := obj.(*batchv1.Job)
-		owner := metav1.GetControllerOf(o)
-		if owner == nil {
-			return nil
+// podToJobSet maps a pod to the JobSet which created it, using the JobSet name label.
+func podToJobSet(obj client.Object) []reconcile.Request {
+	jobSetName, ok := obj.GetLabels()[jobset.JobSetNameKey]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: jobSetName}}}
+}
+
+// jobSetsSharingConcurrencyKey maps a JobSet to the other JobSets sharing its concurrency key,
+// so they are reconciled when it finishes, is suspended or is deleted. Updates map both the
+// old and the new JobSet, so the JobSets sharing its previous key are reconciled as well when
+// its annotation is changed or removed.
+func (r *JobSetReconciler) jobSetsSharingConcurrencyKey(obj client.Object) []reconcile.Request {
+	key, ok := obj.GetAnnotations()[jobset.ConcurrencyKeyKey]
+	if !ok {
+		return nil
+	}
+	var jobSets jobset.JobSetList
+	if err := r.List(context.Background(), &jobSets, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.log.Error(err, "listing jobsets sharing the concurrency key", "jobset", klog.KObj(obj), "concurrencyKey", key)
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, js := range jobSets.Items {
+		if js.Name != obj.GetName() && js.Annotations[jobset.ConcurrencyKeyKey] == key {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: js.Namespace, Name: js.Name}})
 		}
-		// ...make sure it's a JobSet...
-		if owner.APIVersion != apiGVStr || owner.Kind != "JobSet" {
-			return nil
+	}
+	return requests
+}
+
+func SetupIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &batchv1.Job{}, jobOwnerKey, indexJobOwner); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &batchv1.Job{}, jobOwnerReferenceKey, indexJobOwnerReference)
+}
+
+// indexJobOwner returns the name of the JobSet controlling the given job, if any.
+func indexJobOwner(obj client.Object) []string {
+	owner := predicates.JobSetOwnerName(obj.(*batchv1.Job))
+	if owner == "" {
+		return nil
+	}
+	return []string{owner}
+}
+
+// indexJobOwnerReference returns the name of the JobSet owning the given job, if any.
+func indexJobOwnerReference(obj client.Object) []string {
+	owner := predicates.JobSetOwnerReferenceName(obj.(*batchv1.Job))
+	if owner == "" {
+		return nil
+	}
+	return []string{owner}
+}
+
+// setOwnerReference sets the JobSet as the owner of the child object for garbage collection
+// and reconciliation, as its controller unless NonControllingOwnerReferences is set.
+func (r *JobSetReconciler) setOwnerReference(js *jobset.JobSet, obj metav1.Object) error {
+	if r.NonControllingOwnerReferences {
+		return controllerutil.SetOwnerReference(js, obj, r.Scheme)
+	}
+	return ctrl.SetControllerReference(js, obj, r.Scheme)
+}
+
+// ownedBy reports whether the JobSet is an owner of the child object, whether or not it is
+// its controller, so children created with NonControllingOwnerReferences are recognized.
+func ownedBy(obj metav1.Object, js *jobset.JobSet) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == js.UID {
+			return true
 		}
-		return []string{owner.Name}
-	})
+	}
+	return false
+}
+
+// jobOwnerIndexKey returns the index key to look up the child jobs of a JobSet by.
+func (r *JobSetReconciler) jobOwnerIndexKey() string {
+	if r.NonControllingOwnerReferences {
+		return jobOwnerReferenceKey
+	}
+	return jobOwnerKey
 }
 
 // getChildJobs gets jobs owned by the JobSet then categorizes them by status (active, successful, failed).
@@ -194,13 +623,25 @@ func (r *JobSetReconciler) getChildJobs(ctx context.Context, js *jobset.JobSet)
 
 	// Get all active jobs owned by JobSet.
 	var childJobList batchv1.JobList
-	if err := r.List(ctx, &childJobList, client.InNamespace(js.Namespace), client.MatchingFields{jobOwnerKey: js.Name}); err != nil {
+	if err := r.List(ctx, &childJobList, client.InNamespace(js.Namespace), client.MatchingFields{r.jobOwnerIndexKey(): js.Name}); err != nil {
 		return nil, err
 	}
 
-	// Categorize each job into a bucket: active, successful, failed, or delete.
+	// Categorize each job into a bucket: active, successful, failed, delete, or removed.
 	ownedJobs := childJobs{}
 	for i, job := range childJobList.Items {
+		// Jobs whose replicated job is no longer in the spec were created for an older
+		// generation of it. Jobs of the current generation claiming an unknown replicated
+		// job, e.g. because their label was edited, are left alone rather than deleted.
+		if rjobName := job.Labels[jobset.ReplicatedJobNameKey]; !util.Contains(replicatedJobNames(js), rjobName) {
+			if currentGeneration(&job, js) {
+				log.Error(nil, "job of the current jobset generation belongs to an unknown replicatedJob", "job", klog.KObj(&job), "replicatedJob", rjobName)
+				continue
+			}
+			ownedJobs.removed = append(ownedJobs.removed, &childJobList.Items[i])
+			continue
+		}
+
 		// Jobs with jobset.sigs.k8s.io/restart-attempt < jobset.status.restarts are marked for
 		// deletion, as they were part of the previous JobSet run.
 		jobRestarts, err := strconv.Atoi(job.Labels[RestartsKey])
@@ -217,6 +658,19 @@ func (r *JobSetReconciler) getChildJobs(ctx context.Context, js *jobset.JobSet)
 		// Jobs with jobset.sigs.k8s.io/restart-attempt == jobset.status.restarts are part of
 		// the current JobSet run, and marked either active, successful, or failed.
 		_, finishedType := jobFinished(&job)
+		// When the failure policy counts pod failures, a job is considered failed as
+		// soon as any of its pods fail, even if it has not reached its backoffLimit,
+		// unless the pod failures are ignored by a rule of the failure policy. A job
+		// which failed as a whole cannot retry its pods, so it is never ignored.
+		if finishedType == "" && job.Status.Failed > 0 && failureLevel(js, job.Labels[jobset.ReplicatedJobNameKey]) == jobset.FailureLevelPod {
+			ignored, err := r.podFailuresIgnored(ctx, js, &childJobList.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			if !ignored {
+				finishedType = batchv1.JobFailed
+			}
+		}
 		switch finishedType {
 		case "": // active
 			ownedJobs.active = append(ownedJobs.active, &childJobList.Items[i])
@@ -228,14 +682,78 @@ func (r *JobSetReconciler) getChildJobs(ctx context.Context, js *jobset.JobSet)
 	}
 	return &ownedJobs, nil
 }
-func (r *JobSetReconciler) calculateAndUpdateReplicatedJobsStatuses(ctx context.Context, js *jobset.JobSet, jobs *childJobs) error {
+
+func (r *jobSetReconciliation) calculateAndUpdateReplicatedJobsStatuses(ctx context.Context, js *jobset.JobSet, jobs *childJobs) error {
 	status := r.calculateReplicatedJobStatuses(ctx, js, jobs)
-	// Check if status ReplicatedJobsStatus has changed
-	if apiequality.Semantic.DeepEqual(js.Status.ReplicatedJobsStatus, status) {
-		return nil
+	pods, err := r.getChildPods(ctx, js)
+	if err != nil {
+		return err
+	}
+	nodeNames := firstPodNodeNames(pods)
+	for i := range status {
+		status[i].FirstPodNodeName = nodeNames[status[i].Name]
+	}
+	completionPercentage := calculateCompletionPercentage(js, jobs)
+	totalRequests := requestedResources(js)
+	var ready int32
+	for _, rjobStatus := range status {
+		ready += rjobStatus.Ready
+	}
+	// The Ready condition is updated along with the counts it is derived from.
+	condition := readyCondition(allReplicatedJobsReady(js, status))
+	transition := conditionTransition(js, condition)
+	conditionChanged := updateCondition(js, condition)
+	// Check if status ReplicatedJobsStatus, CompletionPercentage, TotalRequests, Ready or the Ready condition has changed
+	if !apiequality.Semantic.DeepEqual(js.Status.ReplicatedJobsStatus, status) || js.Status.CompletionPercentage != completionPercentage ||
+		!apiequality.Semantic.DeepEqual(js.Status.TotalRequests, totalRequests) || js.Status.Ready != ready || conditionChanged {
+		js.Status.ReplicatedJobsStatus = status
+		js.Status.CompletionPercentage = completionPercentage
+		js.Status.TotalRequests = totalRequests
+		js.Status.Ready = ready
+		if err := r.updateJobSetStatus(ctx, js); err != nil {
+			return err
+		}
+	}
+	if conditionChanged && transition {
+		r.recordConditionTransition(ctx, js, corev1.EventTypeNormal, condition)
+	}
+	return nil
+}
+
+// readyCondition returns the Ready condition, set when every replicated job has all of its
+// child jobs ready, and cleared otherwise.
+func readyCondition(ready bool) metav1.Condition {
+	if ready {
+		return metav1.Condition{
+			Type:    string(jobset.JobSetReady),
+			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+			Reason:  "AllJobsReady",
+			Message: "all child jobs are running with all of their pods ready",
+		}
+	}
+	return metav1.Condition{
+		Type:    string(jobset.JobSetReady),
+		Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+		Reason:  "JobsNotReady",
+		Message: "not all child jobs are running with all of their pods ready",
+	}
+}
+
+// allReplicatedJobsReady reports whether every replicated job has as many ready child jobs
+// as it has replicas. A JobSet without any replicas runs no pods, so it is never ready.
+func allReplicatedJobsReady(js *jobset.JobSet, status []jobset.ReplicatedJobStatus) bool {
+	ready := map[string]int32{}
+	for _, rjobStatus := range status {
+		ready[rjobStatus.Name] = rjobStatus.Ready
+	}
+	replicas := 0
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if ready[rjob.Name] < int32(rjob.Replicas) {
+			return false
+		}
+		replicas += rjob.Replicas
 	}
-	js.Status.ReplicatedJobsStatus = status
-	return r.Status().Update(ctx, js)
+	return replicas > 0
 }
 
 func (r *JobSetReconciler) calculateReplicatedJobStatuses(ctx context.Context, js *jobset.JobSet, jobs *childJobs) []jobset.ReplicatedJobStatus {
@@ -253,13 +771,7 @@ func (r *JobSetReconciler) calculateReplicatedJobStatuses(ctx context.Context, j
 
 	// Calculate jobsReady for each Replicated Job
 	for _, job := range jobs.active {
-		ready := pointer.Int32Deref(job.Status.Ready, 0)
-		// parallelism is always set as it is otherwise defaulted by k8s to 1
-		podsCount := *(job.Spec.Parallelism)
-		if job.Spec.Completions != nil && *job.Spec.Completions < podsCount {
-			podsCount = *job.Spec.Completions
-		}
-		if job.Status.Succeeded+ready >= podsCount {
+		if jobReady(job) {
 			if job.Labels != nil && job.Labels[jobset.ReplicatedJobNameKey] != "" {
 				replicatedJobsReady[job.Labels[jobset.ReplicatedJobNameKey]]["ready"]++
 			} else {
@@ -290,95 +802,1285 @@ func (r *JobSetReconciler) calculateReplicatedJobStatuses(ctx context.Context, j
 	return rjStatus
 }
 
-func (r *JobSetReconciler) suspendJobSet(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) error {
-	for _, job := range ownedJobs.active {
-		if !pointer.BoolDeref(job.Spec.Suspend, false) {
-			job.Spec.Suspend = pointer.Bool(true)
-			if err := r.Update(ctx, job); err != nil {
-				return err
-			}
+// firstPodNodeNames returns the names of the nodes the first pod of each replicated job was
+// scheduled on, by replicated job name. The first pod is the one with the lowest job and
+// completion index, the oldest one among pods with the same indexes. Replicated jobs whose
+// first pod is not scheduled yet are left out.
+func firstPodNodeNames(pods []corev1.Pod) map[string]string {
+	first := map[string]*corev1.Pod{}
+	for i := range pods {
+		pod := &pods[i]
+		rjobName := pod.Labels[jobset.ReplicatedJobNameKey]
+		if rjobName == "" {
+			continue
+		}
+		if current, ok := first[rjobName]; !ok || podBefore(pod, current) {
+			first[rjobName] = pod
 		}
 	}
-	return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
-		Type:               string(jobset.JobSetSuspended),
-		Status:             metav1.ConditionStatus(corev1.ConditionTrue),
-		LastTransitionTime: metav1.Now(),
-		Reason:             "SuspendedJobs",
-		Message:            "jobset is suspended",
-	})
+	nodeNames := map[string]string{}
+	for rjobName, pod := range first {
+		if pod.Spec.NodeName != "" {
+			nodeNames[rjobName] = pod.Spec.NodeName
+		}
+	}
+	return nodeNames
+}
+
+// podBefore reports whether pod a comes before pod b, ordering pods by job index, then
+// completion index, then creation time.
+func podBefore(a, b *corev1.Pod) bool {
+	aJobIdx, _ := strconv.Atoi(a.Labels[jobset.JobIndexKey])
+	bJobIdx, _ := strconv.Atoi(b.Labels[jobset.JobIndexKey])
+	if aJobIdx != bJobIdx {
+		return aJobIdx < bJobIdx
+	}
+	aCompletionIdx, _ := strconv.Atoi(a.Annotations[batchv1.JobCompletionIndexAnnotation])
+	bCompletionIdx, _ := strconv.Atoi(b.Annotations[batchv1.JobCompletionIndexAnnotation])
+	if aCompletionIdx != bCompletionIdx {
+		return aCompletionIdx < bCompletionIdx
+	}
+	return a.CreationTimestamp.Before(&b.CreationTimestamp)
 }
 
-func (r *JobSetReconciler) resumeJobSetIfNecessary(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) error {
+// migrateChildLabels updates the reserved labels and annotations of child jobs created with
+// an older label schema version, along with those of their existing pods. Pods created later
+// from the immutable pod template of a migrated job keep the labels of the template.
+func (r *JobSetReconciler) migrateChildLabels(ctx context.Context, js *jobset.JobSet) error {
 	log := ctrl.LoggerFrom(ctx)
 
-	nodeAffinities := map[string]map[string]string{}
-	for _, replicatedJob := range js.Spec.ReplicatedJobs {
-		nodeAffinities[replicatedJob.Name] = replicatedJob.Template.Spec.Template.Spec.NodeSelector
+	// Only the children without the current schema version are listed, so nothing is listed
+	// once all of them are migrated.
+	outdated, err := labels.NewRequirement(LabelSchemaVersionKey, selection.NotEquals, []string{labelSchemaVersion})
+	if err != nil {
+		return err
 	}
+	outdatedSelector := client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*outdated)}
+	var childJobList batchv1.JobList
+	if err := r.List(ctx, &childJobList, client.InNamespace(js.Namespace), client.MatchingFields{r.jobOwnerIndexKey(): js.Name}, outdatedSelector); err != nil {
+		return err
+	}
+	for i := range childJobList.Items {
+		job := &childJobList.Items[i]
+		rjob, jobIdx, ok := replicatedJobForJobName(js, job.Name)
+		if !ok {
+			continue
+		}
 
-	// If JobSpec is unsuspended, ensure all active child Jobs are also
-	// unsuspended and update the suspend condition to true.
-	for _, job := range ownedJobs.active {
-		if pointer.BoolDeref(job.Spec.Suspend, false) {
-			if job.Status.StartTime != nil {
-				job.Status.StartTime = nil
-				if err := r.Status().Update(ctx, job); err != nil {
-					return err
-				}
-			}
-			if job.Labels != nil && job.Labels[jobset.ReplicatedJobNameKey] != "" {
-				// When resuming a job, its nodeSelectors should match that of the replicatedJob template
-				// that it was created from, which may have been updated while it was suspended.
-				job.Spec.Template.Spec.NodeSelector = nodeAffinities[job.Labels[jobset.ReplicatedJobNameKey]]
-			} else {
-				log.Error(nil, "job missing ReplicatedJobName label")
+		// Migrate the pods first, so the job is only marked as migrated once all of its pods are.
+		var podList corev1.PodList
+		if err := r.List(ctx, &podList, client.InNamespace(job.Namespace), client.MatchingLabels{jobset.JobNameKey: job.Name}); err != nil {
+			return err
+		}
+		for j := range podList.Items {
+			pod := &podList.Items[j]
+			if pod.Labels[LabelSchemaVersionKey] == labelSchemaVersion {
+				continue
 			}
-			job.Spec.Suspend = pointer.Bool(false)
-			if err := r.Update(ctx, job); err != nil {
+			patch := client.MergeFrom(pod.DeepCopy())
+			relabelObject(pod, js, rjob, jobIdx)
+			if err := r.Patch(ctx, pod, patch); client.IgnoreNotFound(err) != nil {
 				return err
 			}
 		}
-	}
-	return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
-		Type:               string(jobset.JobSetSuspended),
-		Status:             metav1.ConditionStatus(corev1.ConditionFalse),
-		LastTransitionTime: metav1.Now(),
-		Reason:             "ResumeJobs",
-		Message:            "jobset is resumed",
-	})
-}
-
-func (r *JobSetReconciler) createJobs(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) error {
-	log := ctrl.LoggerFrom(ctx)
 
-	for _, rjob := range js.Spec.ReplicatedJobs {
-		jobs, err := constructJobsFromTemplate(js, &rjob, ownedJobs)
-		if err != nil {
+		relabelObject(job, js, rjob, jobIdx)
+		if err := r.Update(ctx, job); err != nil {
 			return err
 		}
+		log.V(2).Info("migrated job labels", "job", klog.KObj(job), "labelSchemaVersion", labelSchemaVersion)
+	}
+	return nil
+}
 
-		// If pod DNS hostnames are enabled, create a headless service per replicatedjob.
-		if dnsHostnamesEnabled(&rjob) {
-			if err := r.createHeadlessSvcIfNotExist(ctx, js, &rjob); err != nil {
-				return err
-			}
-		}
+// dedupeChildOwnerReferences removes duplicate owner references to the JobSet from its child
+// jobs, services, service accounts and endpoint slices, which buggy mutating webhooks
+// occasionally add.
+func (r *JobSetReconciler) dedupeChildOwnerReferences(ctx context.Context, js *jobset.JobSet) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	var childJobList batchv1.JobList
+	if err := r.List(ctx, &childJobList, client.InNamespace(js.Namespace), client.MatchingFields{r.jobOwnerIndexKey(): js.Name}); err != nil {
+		return err
+	}
+	// The other children all carry the JobSet name label, so only those need to be listed.
+	childLabels := client.MatchingLabels{jobset.JobSetNameKey: js.Name}
+	var serviceList corev1.ServiceList
+	if err := r.List(ctx, &serviceList, client.InNamespace(js.Namespace), childLabels); err != nil {
+		return err
+	}
+	var serviceAccountList corev1.ServiceAccountList
+	if err := r.List(ctx, &serviceAccountList, client.InNamespace(js.Namespace), childLabels); err != nil {
+		return err
+	}
+	var endpointSliceList discoveryv1.EndpointSliceList
+	if err := r.List(ctx, &endpointSliceList, client.InNamespace(js.Namespace), childLabels); err != nil {
+		return err
+	}
+	var children []client.Object
+	for i := range childJobList.Items {
+		children = append(children, &childJobList.Items[i])
+	}
+	for i := range serviceList.Items {
+		children = append(children, &serviceList.Items[i])
+	}
+	for i := range serviceAccountList.Items {
+		children = append(children, &serviceAccountList.Items[i])
+	}
+	for i := range endpointSliceList.Items {
+		children = append(children, &endpointSliceList.Items[i])
+	}
+	for _, child := range children {
+		refs, ok := dedupeOwnerReferences(child.GetOwnerReferences(), js.UID)
+		if !ok {
+			continue
+		}
+		child.SetOwnerReferences(refs)
+		if err := r.Update(ctx, child); err != nil {
+			return err
+		}
+		log.V(2).Info("removed duplicate owner references to jobset", "object", klog.KObj(child))
+	}
+	return nil
+}
+
+// dedupeOwnerReferences returns the owner references with duplicate references to the owner
+// with the given UID removed, keeping its controlling reference if there is one. It reports
+// whether any reference was removed.
+func dedupeOwnerReferences(refs []metav1.OwnerReference, uid types.UID) ([]metav1.OwnerReference, bool) {
+	keep, count := -1, 0
+	for i, ref := range refs {
+		if ref.UID != uid {
+			continue
+		}
+		count++
+		if keep == -1 || (pointer.BoolDeref(ref.Controller, false) && !pointer.BoolDeref(refs[keep].Controller, false)) {
+			keep = i
+		}
+	}
+	if count < 2 {
+		return refs, false
+	}
+	deduped := make([]metav1.OwnerReference, 0, len(refs)-count+1)
+	for i, ref := range refs {
+		if ref.UID != uid || i == keep {
+			deduped = append(deduped, ref)
+		}
+	}
+	return deduped, true
+}
+
+// getChildPods returns the pods belonging to the current run of the JobSet.
+func (r *JobSetReconciler) getChildPods(ctx context.Context, js *jobset.JobSet) ([]corev1.Pod, error) {
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, client.InNamespace(js.Namespace), client.MatchingLabels{
+		jobset.JobSetNameKey: js.Name,
+		RestartsKey:          strconv.Itoa(js.Status.Restarts),
+	}); err != nil {
+		return nil, err
+	}
+	return podList.Items, nil
+}
+
+// updateCompletionsExceedParallelismCondition sets the CompletionsExceedParallelism condition
+// if any replicated job with DNS hostnames enabled has more completions than parallelism, since
+// its pods then run in waves and cannot all discover each other.
+func (r *jobSetReconciliation) updateCompletionsExceedParallelismCondition(ctx context.Context, js *jobset.JobSet) error {
+	var serialized []string
+	for i := range js.Spec.ReplicatedJobs {
+		rjob := &js.Spec.ReplicatedJobs[i]
+		completions := replicatedJobCompletions(js, rjob)
+		if !dnsHostnamesEnabled(rjob) || completions == nil {
+			continue
+		}
+		if *completions > jobParallelism(js, rjob) {
+			serialized = append(serialized, rjob.Name)
+		}
+	}
+	if len(serialized) == 0 {
+		return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+			Type:    string(jobset.JobSetCompletionsExceedParallelism),
+			Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+			Reason:  "AllIndicesRunConcurrently",
+			Message: "all indices of jobs with DNS hostnames enabled run concurrently",
+		})
+	}
+	return r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
+		Type:    string(jobset.JobSetCompletionsExceedParallelism),
+		Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+		Reason:  "IndicesRunSerially",
+		Message: fmt.Sprintf("completions exceed parallelism for replicated jobs %s with DNS hostnames enabled, so not all of their pods run concurrently", strings.Join(serialized, ", ")),
+	})
+}
+
+// updateInvalidCoordinatorCondition sets the InvalidCoordinator condition while the
+// coordinator of the JobSet does not identify one of its pods.
+func (r *jobSetReconciliation) updateInvalidCoordinatorCondition(ctx context.Context, js *jobset.JobSet) error {
+	if js.Spec.Coordinator == nil {
+		return nil
+	}
+	if reason := invalidCoordinatorReason(js); reason != "" {
+		return r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
+			Type:    string(jobset.JobSetInvalidCoordinator),
+			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+			Reason:  "CoordinatorOutOfRange",
+			Message: fmt.Sprintf("%s, so its hostname is not injected into the child jobs", reason),
+		})
+	}
+	return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+		Type:    string(jobset.JobSetInvalidCoordinator),
+		Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+		Reason:  "CoordinatorValid",
+		Message: "the coordinator identifies a pod of the jobset",
+	})
+}
+
+// syncEndpointSlices keeps an EndpointSlice listing the addresses of the ready pods of each
+// replicated job with Network.PublishEndpointSlice enabled up to date.
+func (r *JobSetReconciler) syncEndpointSlices(ctx context.Context, js *jobset.JobSet) error {
+	var rjobs []*jobset.ReplicatedJob
+	for i := range js.Spec.ReplicatedJobs {
+		if endpointSlicePublished(&js.Spec.ReplicatedJobs[i]) {
+			rjobs = append(rjobs, &js.Spec.ReplicatedJobs[i])
+		}
+	}
+	if len(rjobs) == 0 {
+		return nil
+	}
+
+	pods, err := r.getChildPods(ctx, js)
+	if err != nil {
+		return err
+	}
+	for _, rjob := range rjobs {
+		desired, err := r.constructEndpointSlice(js, rjob, pods)
+		if err != nil {
+			return err
+		}
+		var slice discoveryv1.EndpointSlice
+		if err := r.Get(ctx, client.ObjectKeyFromObject(desired), &slice); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			if err := r.Create(ctx, desired); err != nil {
+				return err
+			}
+			continue
+		}
+		// The address type is immutable, so a slice of the other IP family is recreated, e.g.
+		// when it was created before any pod of an IPv6 cluster was ready.
+		if slice.AddressType != desired.AddressType {
+			if err := r.Delete(ctx, &slice, client.Preconditions{UID: &slice.UID}); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			if err := r.Create(ctx, desired); err != nil {
+				return err
+			}
+			continue
+		}
+		if apiequality.Semantic.DeepEqual(slice.Endpoints, desired.Endpoints) && apiequality.Semantic.DeepEqual(slice.Labels, desired.Labels) {
+			continue
+		}
+		slice.Labels = desired.Labels
+		slice.Endpoints = desired.Endpoints
+		if err := r.Update(ctx, &slice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// constructEndpointSlice returns the EndpointSlice listing the addresses of the ready pods of the
+// replicated job. It is associated with the headless service of the replicated job, and has
+// the address type of the primary IP family of the pods, which is that of the cluster.
+func (r *JobSetReconciler) constructEndpointSlice(js *jobset.JobSet, rjob *jobset.ReplicatedJob, pods []corev1.Pod) (*discoveryv1.EndpointSlice, error) {
+	var ready []*corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Labels[jobset.ReplicatedJobNameKey] == rjob.Name && podReady(pod) && net.ParseIP(pod.Status.PodIP) != nil {
+			ready = append(ready, pod)
+		}
+	}
+	addressType := discoveryv1.AddressTypeIPv4
+	if len(ready) > 0 && net.ParseIP(ready[0].Status.PodIP).To4() == nil {
+		addressType = discoveryv1.AddressTypeIPv6
+	}
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      endpointSliceName(js, rjob),
+			Namespace: js.Namespace,
+			Labels: map[string]string{
+				jobset.JobSetNameKey:         js.Name,
+				jobset.ReplicatedJobNameKey:  rjob.Name,
+				discoveryv1.LabelServiceName: GenSubdomain(js, rjob),
+				discoveryv1.LabelManagedBy:   endpointSliceManagedBy,
+			},
+		},
+		AddressType: addressType,
+		Endpoints:   []discoveryv1.Endpoint{},
+	}
+	for _, pod := range ready {
+		if ipv4 := net.ParseIP(pod.Status.PodIP).To4() != nil; ipv4 != (addressType == discoveryv1.AddressTypeIPv4) {
+			continue
+		}
+		endpoint := discoveryv1.Endpoint{
+			Addresses:  []string{pod.Status.PodIP},
+			Conditions: discoveryv1.EndpointConditions{Ready: pointer.Bool(true)},
+			TargetRef: &corev1.ObjectReference{
+				Kind:      "Pod",
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				UID:       pod.UID,
+			},
+		}
+		if pod.Spec.Hostname != "" {
+			endpoint.Hostname = pointer.String(pod.Spec.Hostname)
+		}
+		if pod.Spec.NodeName != "" {
+			endpoint.NodeName = pointer.String(pod.Spec.NodeName)
+		}
+		slice.Endpoints = append(slice.Endpoints, endpoint)
+	}
+	sort.Slice(slice.Endpoints, func(i, j int) bool {
+		return slice.Endpoints[i].TargetRef.Name < slice.Endpoints[j].TargetRef.Name
+	})
+
+	// Set owner reference for garbage collection and reconcilation.
+	if err := r.setOwnerReference(js, slice); err != nil {
+		return nil, err
+	}
+	return slice, nil
+}
+
+// annotatePodHostnames adds the DNS hostname of each pod to its annotations,
+// for replicated jobs with Network.AnnotatePodHostnames enabled. All pods of an indexed Job
+// are created from the same template, so the per-index annotation can only be added once the
+// pods exist.
+func (r *JobSetReconciler) annotatePodHostnames(ctx context.Context, js *jobset.JobSet) error {
+	var rjobNames []string
+	for i := range js.Spec.ReplicatedJobs {
+		if podHostnameAnnotationEnabled(&js.Spec.ReplicatedJobs[i]) {
+			rjobNames = append(rjobNames, js.Spec.ReplicatedJobs[i].Name)
+		}
+	}
+	if len(rjobNames) == 0 {
+		return nil
+	}
+
+	pods, err := r.getChildPods(ctx, js)
+	if err != nil {
+		return err
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if !util.Contains(rjobNames, pod.Labels[jobset.ReplicatedJobNameKey]) {
+			continue
+		}
+		hostname := podHostname(pod)
+		if hostname == "" || pod.Annotations[jobset.PodHostnameKey] == hostname {
+			continue
+		}
+		patch := client.MergeFrom(pod.DeepCopy())
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[jobset.PodHostnameKey] = hostname
+		// A pod deleted in the meantime needs no annotation, but the others still do.
+		if err := r.Patch(ctx, pod, patch); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateImagePullFailureCondition sets the ImagePullFailure condition when the number of pods
+// unable to pull their images reaches the threshold, and clears it once they recover.
+func (r *jobSetReconciliation) updateImagePullFailureCondition(ctx context.Context, js *jobset.JobSet) error {
+	pods, err := r.getChildPods(ctx, js)
+	if err != nil {
+		return err
+	}
+	threshold := r.ImagePullFailureThreshold
+	if threshold <= 0 {
+		threshold = defaultImagePullFailureThreshold
+	}
+	if failing := numPodsFailingImagePull(pods); failing >= threshold {
+		return r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
+			Type:    string(jobset.JobSetImagePullFailure),
+			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+			Reason:  "ImagePullBackOff",
+			Message: fmt.Sprintf("%d pod(s) are unable to pull their container images", failing),
+		})
+	}
+	return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+		Type:    string(jobset.JobSetImagePullFailure),
+		Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+		Reason:  "ImagesPulled",
+		Message: "pods are able to pull their container images",
+	})
+}
+
+// updateReconcileErrorCondition sets the ReconcileError condition of the JobSet to the
+// given reconcile error, or clears it if the reconcile succeeded. Conflicts leave it as is.
+func (r *jobSetReconciliation) updateReconcileErrorCondition(ctx context.Context, js *jobset.JobSet, reconcileErr error) error {
+	if reconcileErr == nil {
+		return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+			Type:    string(jobset.JobSetReconcileError),
+			Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+			Reason:  "ReconcileSucceeded",
+			Message: "jobset reconciled successfully",
+		})
+	}
+	// Conflicts are routine with concurrent updates, and resolved by the retry.
+	if apierrors.IsConflict(reconcileErr) {
+		return nil
+	}
+	condition := metav1.Condition{
+		Type:               string(jobset.JobSetReconcileError),
+		Status:             metav1.ConditionStatus(corev1.ConditionTrue),
+		LastTransitionTime: metav1.Now(),
+		Reason:             "ReconcileFailed",
+		Message:            truncateMessage(reconcileErr.Error(), maxReconcileErrorMessageLength),
+	}
+	// Unlike other conditions, the message and timestamp are refreshed whenever the error changes.
+	found := false
+	for i, c := range js.Status.Conditions {
+		if c.Type != condition.Type {
+			continue
+		}
+		if c.Status == condition.Status && c.Message == condition.Message {
+			return nil
+		}
+		js.Status.Conditions[i] = condition
+		found = true
+	}
+	if !found {
+		js.Status.Conditions = append(js.Status.Conditions, condition)
+	}
+	if err := r.updateJobSetStatus(ctx, js); err != nil {
+		return err
+	}
+	r.Record.Event(js, corev1.EventTypeWarning, condition.Type, condition.Message)
+	return nil
+}
+
+// updatePodsPendingCondition sets the PodsPending condition when pods have been pending for
+// longer than the threshold, and clears it once they are no longer pending. It returns the
+// duration after which a pod which is pending now will exceed the threshold, if any, since
+// no pod event is triggered then.
+func (r *jobSetReconciliation) updatePodsPendingCondition(ctx context.Context, js *jobset.JobSet) (time.Duration, error) {
+	pods, err := r.getChildPods(ctx, js)
+	if err != nil {
+		return 0, err
+	}
+	threshold := r.PodsPendingThreshold
+	if threshold == 0 {
+		threshold = defaultPodsPendingThreshold
+	}
+	now := time.Now()
+	var requeueAfter time.Duration
+	for _, pod := range pods {
+		if remaining := threshold - now.Sub(pod.CreationTimestamp.Time); pod.Status.Phase == corev1.PodPending && remaining >= 0 {
+			if requeueAfter == 0 || remaining < requeueAfter {
+				requeueAfter = remaining + time.Second
+			}
+		}
+	}
+
+	pending := podsPendingLongerThan(pods, threshold, now)
+	if len(pending) > 0 {
+		// Sample the scheduling failure of one of the pending pods, if any.
+		reason, message := "PodsPending", ""
+		for _, pod := range pending {
+			for _, c := range pod.Status.Conditions {
+				if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse && c.Reason != "" {
+					reason, message = c.Reason, fmt.Sprintf(": %s", c.Message)
+					break
+				}
+			}
+			if message != "" {
+				break
+			}
+		}
+		return requeueAfter, r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
+			Type:    string(jobset.JobSetPodsPending),
+			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+			Reason:  reason,
+			Message: fmt.Sprintf("%d pod(s) have been pending for longer than %s%s", len(pending), threshold, message),
+		})
+	}
+	return requeueAfter, r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+		Type:    string(jobset.JobSetPodsPending),
+		Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+		Reason:  "NoPodsPending",
+		Message: "no pods have been pending for longer than the threshold",
+	})
+}
+
+func (r *jobSetReconciliation) suspendJobSet(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) error {
+	for _, job := range ownedJobs.active {
+		if js.Spec.SuspendPolicy == jobset.SuspendPolicyKeepPods && !pointer.BoolDeref(job.Spec.Suspend, false) {
+			if err := r.keepPodsRunning(ctx, job); err != nil {
+				return err
+			}
+			continue
+		}
+		if !pointer.BoolDeref(job.Spec.Suspend, false) {
+			job.Spec.Suspend = pointer.Bool(true)
+			if err := r.Update(ctx, job); err != nil {
+				return err
+			}
+		}
+	}
+	return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+		Type:               string(jobset.JobSetSuspended),
+		Status:             metav1.ConditionStatus(corev1.ConditionTrue),
+		LastTransitionTime: metav1.Now(),
+		Reason:             "SuspendedJobs",
+		Message:            "jobset is suspended",
+	})
+}
+
+// keepPodsRunning holds a running job suspended with the KeepPods policy. The job is not
+// suspended, which would delete its pods. Its parallelism is lowered to its number of active
+// pods instead, and lowered again on later reconciles as its pods finish. The job controller
+// may still replace a pod which finishes before the parallelism is lowered.
+func (r *JobSetReconciler) keepPodsRunning(ctx context.Context, job *batchv1.Job) error {
+	if pointer.Int32Deref(job.Spec.Parallelism, 1) <= job.Status.Active {
+		return nil
+	}
+	job.Spec.Parallelism = pointer.Int32(job.Status.Active)
+	return r.Update(ctx, job)
+}
+
+func (r *jobSetReconciliation) resumeJobSetIfNecessary(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	nodeAffinities := map[string]map[string]string{}
+	tolerations := map[string][]corev1.Toleration{}
+	parallelisms := map[string]int32{}
+	for i, replicatedJob := range js.Spec.ReplicatedJobs {
+		nodeAffinities[replicatedJob.Name] = replicatedJob.Template.Spec.Template.Spec.NodeSelector
+		tolerations[replicatedJob.Name] = replicatedJob.Template.Spec.Template.Spec.Tolerations
+		parallelisms[replicatedJob.Name] = jobParallelism(js, &js.Spec.ReplicatedJobs[i])
+	}
+
+	// Jobs kept running while the JobSet or their replicated job was suspended with the
+	// KeepPods policy get back the parallelism of their replicated job when it is resumed.
+	keepPods := js.Spec.SuspendPolicy == jobset.SuspendPolicyKeepPods
+
+	// If JobSpec is unsuspended, ensure all active child Jobs are also unsuspended, unless
+	// their replicated job is suspended, and update the suspend condition to true.
+	var outdatedJobs []*batchv1.Job
+	for _, job := range ownedJobs.active {
+		if replicatedJobSuspended(js, job.Labels[jobset.ReplicatedJobNameKey]) {
+			if keepPods && !pointer.BoolDeref(job.Spec.Suspend, false) {
+				if err := r.keepPodsRunning(ctx, job); err != nil {
+					return err
+				}
+				continue
+			}
+			if !pointer.BoolDeref(job.Spec.Suspend, false) {
+				job.Spec.Suspend = pointer.Bool(true)
+				if err := r.Update(ctx, job); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if pointer.BoolDeref(job.Spec.Suspend, false) {
+			// The pod template of a job cannot be updated, so jobs whose container images or
+			// total number of pods were updated while they were suspended are deleted, and
+			// recreated from the updated template.
+			if containerImagesUpdated(js, job) || totalPodsEnvOutdated(js, job) {
+				outdatedJobs = append(outdatedJobs, job)
+				continue
+			}
+			if job.Status.StartTime != nil {
+				job.Status.StartTime = nil
+				if err := r.Status().Update(ctx, job); err != nil {
+					return err
+				}
+			}
+			if job.Labels != nil && job.Labels[jobset.ReplicatedJobNameKey] != "" {
+				// When resuming a job, its nodeSelectors and tolerations should match those of the
+				// replicatedJob template that it was created from, which may have been updated while
+				// it was suspended. The same applies to the parallelism, whose override may have been updated.
+				if !currentGeneration(job, js) {
+					job.Spec.Template.Spec.NodeSelector = nodeAffinities[job.Labels[jobset.ReplicatedJobNameKey]]
+					job.Spec.Template.Spec.Tolerations = tolerations[job.Labels[jobset.ReplicatedJobNameKey]]
+					job.Spec.Parallelism = pointer.Int32(parallelisms[job.Labels[jobset.ReplicatedJobNameKey]])
+					// The pod group of a gang scheduled JobSet grows or shrinks with the parallelism.
+					if gangScheduled(js) {
+						annotations := util.CloneMap(job.Spec.Template.Annotations)
+						annotations[jobset.PodGroupMinMemberKey] = strconv.FormatInt(podGroupMinMember(js), 10)
+						job.Spec.Template.Annotations = annotations
+					}
+					setGenerationLabel(job, js)
+					setGenerationLabel(&job.Spec.Template, js)
+				}
+			} else {
+				log.Error(nil, "job missing ReplicatedJobName label")
+			}
+			job.Spec.Suspend = pointer.Bool(false)
+			if err := r.Update(ctx, job); err != nil {
+				return err
+			}
+		} else if parallelism := parallelisms[job.Labels[jobset.ReplicatedJobNameKey]]; keepPods && pointer.Int32Deref(job.Spec.Parallelism, 1) != parallelism {
+			job.Spec.Parallelism = pointer.Int32(parallelism)
+			if err := r.Update(ctx, job); err != nil {
+				return err
+			}
+		}
+	}
+	if err := r.deleteJobs(ctx, outdatedJobs); err != nil {
+		return err
+	}
+	// Account for the time spent suspended, if the JobSet is being resumed.
+	if suspended := meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetSuspended)); suspended != nil && suspended.Status == metav1.ConditionTrue {
+		addSuspendedDuration(js, time.Since(suspended.LastTransitionTime.Time))
+	}
+	return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+		Type:               string(jobset.JobSetSuspended),
+		Status:             metav1.ConditionStatus(corev1.ConditionFalse),
+		LastTransitionTime: metav1.Now(),
+		Reason:             "ResumeJobs",
+		Message:            "jobset is resumed",
+	})
+}
+
+// replicatedJobSuspended reports whether the child jobs of the named replicated job should be
+// suspended, because either the JobSet or the replicated job itself is suspended.
+func replicatedJobSuspended(js *jobset.JobSet, replicatedJobName string) bool {
+	if pointer.BoolDeref(js.Spec.Suspend, false) {
+		return true
+	}
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		if rjob.Name == replicatedJobName {
+			return pointer.BoolDeref(rjob.Suspend, false)
+		}
+	}
+	return false
+}
+
+// addSuspendedDuration adds the given duration to the cumulative suspended duration of the JobSet.
+func addSuspendedDuration(js *jobset.JobSet, d time.Duration) {
+	if js.Status.SuspendedDuration == nil {
+		js.Status.SuspendedDuration = &metav1.Duration{}
+	}
+	js.Status.SuspendedDuration.Duration += d
+}
+
+func (r *jobSetReconciliation) createJobs(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Create the ServiceAccount the pods run under before any of them, if it is managed.
+	if managedServiceAccount(js) {
+		owned, err := r.createServiceAccountIfNotExist(ctx, js)
+		if err != nil {
+			return err
+		}
+		if err := r.updateServiceAccountConflictCondition(ctx, js, owned); err != nil {
+			return err
+		}
+		if !owned {
+			return nil
+		}
+	}
+
+	blocked := blockedReplicatedJobs(js, ownedJobs)
+	created := false
+	unsupported := map[string][]string{}
+	for _, rjob := range replicatedJobsInCreationOrder(js) {
+		// Wait for the replicated jobs this one depends on to be ready.
+		if _, ok := blocked[rjob.Name]; ok {
+			continue
+		}
+		jobs, err := constructJobsFromTemplate(js, &rjob, ownedJobs)
+		if err != nil {
+			return err
+		}
+
+		// If pod DNS hostnames are enabled, create a headless service per replicatedjob.
+		if dnsHostnamesEnabled(&rjob) {
+			if err := r.createHeadlessSvcIfNotExist(ctx, js, &rjob); err != nil {
+				return err
+			}
+		}
 
 		for _, job := range jobs {
-			// Set jobset controller as owner of the job for garbage collection and reconcilation.
-			if err := ctrl.SetControllerReference(js, job, r.Scheme); err != nil {
+			// Set jobset as owner of the job for garbage collection and reconcilation.
+			if err := r.setOwnerReference(js, job); err != nil {
+				return err
+			}
+			if r.AnnotateResourceVersion {
+				job.Annotations[JobSetResourceVersionKey] = js.ResourceVersion
+			}
+
+			// Create the job.
+			// TODO(#18): Deal with the case where the job exists but is not owned by the jobset.
+			requested := gatedJobFieldsSet(&job.Spec)
+			if err := r.Create(ctx, job); err != nil {
+				return err
+			}
+			log.V(2).Info("successfully created job", "job", klog.KObj(job))
+			r.Record.Eventf(js, corev1.EventTypeNormal, eventReasonJobCreated, "created job %s of replicatedJob %s", job.Name, rjob.Name)
+			created = true
+			if dropped := droppedJobFields(requested, job); len(dropped) > 0 {
+				unsupported[rjob.Name] = dropped
+			}
+		}
+	}
+	if created {
+		// Report the creation of the first child jobs of the JobSet.
+		if js.Status.Restarts == 0 && len(ownedJobs.active)+len(ownedJobs.successful)+len(ownedJobs.delete) == 0 {
+			r.notify(ctx, js, notifier.EventCreated)
+		}
+		// The active deadline starts once the first child jobs are created.
+		if js.Status.StartTime == nil {
+			now := metav1.Now()
+			js.Status.StartTime = &now
+			if err := r.updateJobSetStatus(ctx, js); err != nil {
+				return err
+			}
+		}
+		if err := r.updateUnsupportedJobFeaturesCondition(ctx, js, unsupported); err != nil {
+			return err
+		}
+	}
+	return r.updateWaitingForDependenciesCondition(ctx, js, blocked)
+}
+
+// gatedJobFields are the fields of the child jobs which the API server silently drops when
+// the feature gate they belong to is disabled, each with a function reporting whether it
+// is set in a job spec.
+var gatedJobFields = []struct {
+	path  string
+	isSet func(*batchv1.JobSpec) bool
+}{
+	{"podFailurePolicy", func(spec *batchv1.JobSpec) bool { return spec.PodFailurePolicy != nil }},
+	{"template.spec.schedulingGates", func(spec *batchv1.JobSpec) bool { return len(spec.Template.Spec.SchedulingGates) > 0 }},
+	{"template.spec.resourceClaims", func(spec *batchv1.JobSpec) bool { return len(spec.Template.Spec.ResourceClaims) > 0 }},
+	{"template.spec.hostUsers", func(spec *batchv1.JobSpec) bool { return spec.Template.Spec.HostUsers != nil }},
+}
+
+// gatedJobFieldsSet returns the paths of the feature gated fields set in the job spec.
+func gatedJobFieldsSet(spec *batchv1.JobSpec) []string {
+	var fields []string
+	for _, field := range gatedJobFields {
+		if field.isSet(spec) {
+			fields = append(fields, field.path)
+		}
+	}
+	return fields
+}
+
+// droppedJobFields returns the requested feature gated fields which are not set in the job
+// returned by the API server.
+func droppedJobFields(requested []string, job *batchv1.Job) []string {
+	kept := gatedJobFieldsSet(&job.Spec)
+	var dropped []string
+	for _, field := range requested {
+		if !util.Contains(kept, field) {
+			dropped = append(dropped, field)
+		}
+	}
+	return dropped
+}
+
+// updateUnsupportedJobFeaturesCondition sets the UnsupportedJobFeatures condition when the
+// API server dropped feature gated fields from the child jobs it just created, so the jobs
+// run without them, and clears it once jobs are created with all their fields.
+func (r *jobSetReconciliation) updateUnsupportedJobFeaturesCondition(ctx context.Context, js *jobset.JobSet, unsupported map[string][]string) error {
+	if len(unsupported) > 0 {
+		var messages []string
+		for _, rjob := range js.Spec.ReplicatedJobs {
+			if fields, ok := unsupported[rjob.Name]; ok {
+				messages = append(messages, fmt.Sprintf("replicatedJob '%s' uses %s", rjob.Name, strings.Join(fields, ", ")))
+			}
+		}
+		return r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
+			Type:    string(jobset.JobSetUnsupportedJobFeatures),
+			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+			Reason:  "FeatureNotSupported",
+			Message: fmt.Sprintf("%s, which the cluster does not support and dropped from the created jobs", strings.Join(messages, "; ")),
+		})
+	}
+	return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+		Type:    string(jobset.JobSetUnsupportedJobFeatures),
+		Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+		Reason:  "AllFeaturesSupported",
+		Message: "the created jobs kept all of their fields",
+	})
+}
+
+// updateWaitingForDependenciesCondition sets the WaitingForDependencies condition while the
+// creation of some replicated jobs waits for their dependencies, reporting the progress of
+// each unmet dependency, and clears it once all replicated jobs could be created.
+func (r *jobSetReconciliation) updateWaitingForDependenciesCondition(ctx context.Context, js *jobset.JobSet, blocked map[string][]string) error {
+	if len(blocked) > 0 {
+		var waiting []string
+		for _, rjob := range js.Spec.ReplicatedJobs {
+			if unmet, ok := blocked[rjob.Name]; ok {
+				waiting = append(waiting, fmt.Sprintf("%s waiting on %s", rjob.Name, strings.Join(unmet, ", ")))
+			}
+		}
+		return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+			Type:    string(jobset.JobSetWaitingForDependencies),
+			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+			Reason:  "DependenciesNotReady",
+			Message: strings.Join(waiting, "; "),
+		})
+	}
+	return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+		Type:    string(jobset.JobSetWaitingForDependencies),
+		Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+		Reason:  "DependenciesReady",
+		Message: "the dependencies of all replicated jobs are ready",
+	})
+}
+
+// updateUnsatisfiableSuccessPolicyCondition sets the UnsatisfiableSuccessPolicy condition
+// when all child jobs completed without satisfying the success policy, because replicated
+// jobs it targets have no child jobs and cannot be created. It is cleared otherwise.
+func (r *jobSetReconciliation) updateUnsatisfiableSuccessPolicyCondition(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) error {
+	if missing := unsatisfiableSuccessPolicyTargets(js, ownedJobs); len(missing) > 0 {
+		return r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
+			Type:    string(jobset.JobSetUnsatisfiableSuccessPolicy),
+			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+			Reason:  "TargetsNotCreated",
+			Message: fmt.Sprintf("all child jobs completed, but replicatedJobs %s targeted by the success policy have no jobs and cannot be created", strings.Join(missing, ", ")),
+		})
+	}
+	return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+		Type:    string(jobset.JobSetUnsatisfiableSuccessPolicy),
+		Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+		Reason:  "TargetsCreated",
+		Message: "the success policy can still be satisfied",
+	})
+}
+
+// unsatisfiableSuccessPolicyTargets returns the replicated jobs targeted by the success policy
+// which have no child jobs and are blocked on their dependencies, once all other child jobs
+// completed. Their dependencies cannot become ready anymore, so the policy is never satisfied.
+func unsatisfiableSuccessPolicyTargets(js *jobset.JobSet, ownedJobs *childJobs) []string {
+	if js.Spec.SuccessPolicy == nil || js.Spec.SuccessPolicy.Operator == jobset.OperatorNever {
+		return nil
+	}
+	if len(ownedJobs.active) > 0 || len(ownedJobs.failed) > 0 || len(ownedJobs.successful) == 0 {
+		return nil
+	}
+	if successPolicySatisfied(js, ownedJobs.successful) {
+		return nil
+	}
+	created := map[string]bool{}
+	for _, job := range ownedJobs.successful {
+		created[job.Labels[jobset.ReplicatedJobNameKey]] = true
+	}
+	blocked := blockedReplicatedJobs(js, ownedJobs)
+	var missing []string
+	for i := range js.Spec.ReplicatedJobs {
+		rjob := &js.Spec.ReplicatedJobs[i]
+		if _, ok := blocked[rjob.Name]; ok && rjob.Replicas > 0 && !created[rjob.Name] && replicatedJobMatchesSuccessPolicy(js, rjob) {
+			missing = append(missing, rjob.Name)
+		}
+	}
+	return missing
+}
+
+// blockedReplicatedJobs returns the replicated jobs whose dependencies are not all ready,
+// mapped to the progress of each of their unmet dependencies, e.g. "worker (Ready: 3/4)".
+func blockedReplicatedJobs(js *jobset.JobSet, ownedJobs *childJobs) map[string][]string {
+	ready := map[string]int{}
+	for _, job := range ownedJobs.active {
+		if jobReady(job) {
+			ready[job.Labels[jobset.ReplicatedJobNameKey]]++
+		}
+	}
+	for _, job := range ownedJobs.successful {
+		ready[job.Labels[jobset.ReplicatedJobNameKey]]++
+	}
+	replicas := map[string]int{}
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		replicas[rjob.Name] = rjob.Replicas
+	}
+
+	blocked := map[string][]string{}
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		for _, dep := range rjob.DependsOn {
+			if ready[dep] < replicas[dep] {
+				blocked[rjob.Name] = append(blocked[rjob.Name], fmt.Sprintf("%s (Ready: %d/%d)", dep, ready[dep], replicas[dep]))
+			}
+		}
+	}
+	return blocked
+}
+
+// jobReady reports whether all pods the job runs at once are ready, or have succeeded.
+func jobReady(job *batchv1.Job) bool {
+	// parallelism is always set as it is otherwise defaulted by k8s to 1
+	podsCount := pointer.Int32Deref(job.Spec.Parallelism, 1)
+	if job.Spec.Completions != nil && *job.Spec.Completions < podsCount {
+		podsCount = *job.Spec.Completions
+	}
+	return job.Status.Succeeded+pointer.Int32Deref(job.Status.Ready, 0) >= podsCount
+}
+
+// queueForConcurrencyKey reports whether the creation of the child jobs should wait for an
+// older unfinished JobSet sharing the concurrency key of this one, and keeps the Queued
+// condition up to date. Suspended JobSets do not hold the key, since they run no pods. Only
+// JobSets without child jobs of the current run are queued, so a running JobSet is never
+// interrupted, even once an older JobSet sharing the key is resumed.
+func (r *jobSetReconciliation) queueForConcurrencyKey(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) (bool, error) {
+	key, ok := js.Annotations[jobset.ConcurrencyKeyKey]
+	if !ok || len(ownedJobs.active)+len(ownedJobs.successful)+len(ownedJobs.failed) > 0 {
+		return false, nil
+	}
+	var jobSets jobset.JobSetList
+	if err := r.List(ctx, &jobSets, client.InNamespace(js.Namespace)); err != nil {
+		return false, err
+	}
+	for i := range jobSets.Items {
+		other := &jobSets.Items[i]
+		if other.Name == js.Name || other.Annotations[jobset.ConcurrencyKeyKey] != key || jobSetFinished(other) || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if pointer.BoolDeref(other.Spec.Suspend, false) {
+			continue
+		}
+		if !createdBefore(other, js) {
+			continue
+		}
+		return true, r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+			Type:    string(jobset.JobSetQueued),
+			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+			Reason:  "ConcurrencyKeyHeld",
+			Message: fmt.Sprintf("waiting for jobset %s sharing concurrency key %q to finish", other.Name, key),
+		})
+	}
+	return false, r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+		Type:    string(jobset.JobSetQueued),
+		Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+		Reason:  "ConcurrencyKeyAcquired",
+		Message: fmt.Sprintf("no older jobset sharing concurrency key %q is running", key),
+	})
+}
+
+// createdBefore reports whether JobSet a was created before JobSet b, breaking ties by name.
+func createdBefore(a, b *jobset.JobSet) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// waitForCapacity reports whether the creation of the child jobs should wait for more
+// schedulable capacity, and keeps the WaitingForCapacity condition up to date. The check
+// only applies to JobSets opting in via the WaitForCapacityKey annotation, before any of
+// the child jobs of the current run are created.
+func (r *jobSetReconciliation) waitForCapacity(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) (bool, error) {
+	if js.Annotations[jobset.WaitForCapacityKey] != "true" || pointer.BoolDeref(js.Spec.Suspend, false) {
+		return false, nil
+	}
+	if len(ownedJobs.active)+len(ownedJobs.successful)+len(ownedJobs.failed)+len(ownedJobs.delete) > 0 {
+		return false, nil
+	}
+
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes); err != nil {
+		return false, err
+	}
+	requested := requestedResources(js)
+	allocatable := allocatableResources(nodes.Items)
+	for name, quantity := range requested {
+		available := allocatable[name]
+		if quantity.Cmp(available) > 0 {
+			return true, r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+				Type:    string(jobset.JobSetWaitingForCapacity),
+				Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+				Reason:  "InsufficientCapacity",
+				Message: fmt.Sprintf("jobset requests %s of %s, but only %s is allocatable on schedulable nodes", quantity.String(), name, available.String()),
+			})
+		}
+	}
+	return false, r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+		Type:    string(jobset.JobSetWaitingForCapacity),
+		Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+		Reason:  "SufficientCapacity",
+		Message: "cluster has enough schedulable capacity for the jobset",
+	})
+}
+
+// suspendOnQuotaExceeded reports whether the JobSet opted in to being suspended while its
+// namespace quota is exceeded.
+func suspendOnQuotaExceeded(js *jobset.JobSet) bool {
+	return js.Annotations[jobset.SuspendOnQuotaExceededKey] == "true" && !pointer.BoolDeref(js.Spec.Suspend, false)
+}
+
+// holdForQuota reports whether the child jobs should be held suspended because starting
+// them would exceed a resource quota of the namespace, and keeps the QuotaExceeded
+// condition up to date. The check only applies to JobSets opting in via the
+// SuspendOnQuotaExceededKey annotation while none of their child jobs are running, since
+// the quota usage of running pods is already accounted for.
+func (r *jobSetReconciliation) holdForQuota(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) (bool, error) {
+	if !suspendOnQuotaExceeded(js) {
+		return false, nil
+	}
+	for _, job := range ownedJobs.active {
+		if !pointer.BoolDeref(job.Spec.Suspend, false) {
+			return false, nil
+		}
+	}
+
+	var quotas corev1.ResourceQuotaList
+	if err := r.List(ctx, &quotas, client.InNamespace(js.Namespace)); err != nil {
+		return false, err
+	}
+	if msg := quotaShortfall(quotas.Items, pendingQuotaUsage(js, ownedJobs)); msg != "" {
+		return true, r.suspendForQuota(ctx, js, ownedJobs, msg)
+	}
+	return false, r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+		Type:    string(jobset.JobSetQuotaExceeded),
+		Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+		Reason:  "QuotaAvailable",
+		Message: "resource quotas of the namespace have room for the jobset",
+	})
+}
+
+// suspendForQuota suspends the active child jobs and sets the QuotaExceeded condition. The
+// jobs are resumed along with the JobSet once holdForQuota finds room in the quota again.
+func (r *jobSetReconciliation) suspendForQuota(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs, msg string) error {
+	for _, job := range ownedJobs.active {
+		if !pointer.BoolDeref(job.Spec.Suspend, false) {
+			job.Spec.Suspend = pointer.Bool(true)
+			if err := r.Update(ctx, job); err != nil {
 				return err
 			}
+		}
+	}
+	return r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
+		Type:    string(jobset.JobSetQuotaExceeded),
+		Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+		Reason:  "QuotaExceeded",
+		Message: msg,
+	})
+}
+
+// quotaExceededError reports whether the error is an admission rejection by a resource quota.
+func quotaExceededError(err error) bool {
+	return apierrors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota")
+}
+
+// pendingQuotaUsage returns the quota usage added by starting the child jobs of the JobSet
+// which have not succeeded yet, keyed by the quota resource names it counts against.
+func pendingQuotaUsage(js *jobset.JobSet, ownedJobs *childJobs) corev1.ResourceList {
+	successful := map[string]int{}
+	for _, job := range ownedJobs.successful {
+		successful[job.Labels[jobset.ReplicatedJobNameKey]]++
+	}
+	usage := corev1.ResourceList{}
+	var pods, jobs int64
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		jobs += int64(rjob.Replicas)
+		rjobPods := int64(rjob.Replicas-successful[rjob.Name]) * int64(jobParallelism(js, &rjob))
+		pods += rjobPods
+		for name, quantity := range podRequests(&rjob.Template.Spec.Template.Spec) {
+			for _, key := range []corev1.ResourceName{name, corev1.DefaultResourceRequestsPrefix + name} {
+				sum := usage[key]
+				sum.Add(multiplyQuantity(quantity, rjobPods))
+				usage[key] = sum
+			}
+		}
+	}
+	existing := len(ownedJobs.active) + len(ownedJobs.successful) + len(ownedJobs.failed) + len(ownedJobs.delete)
+	usage[corev1.ResourcePods] = *resource.NewQuantity(pods, resource.DecimalSI)
+	usage[corev1.ResourceName("count/jobs.batch")] = *resource.NewQuantity(jobs-int64(existing), resource.DecimalSI)
+	return usage
+}
+
+// quotaShortfall returns a message describing the first quota the usage does not fit in,
+// or an empty string if it fits in all of them.
+func quotaShortfall(quotas []corev1.ResourceQuota, usage corev1.ResourceList) string {
+	for _, quota := range quotas {
+		names := make([]string, 0, len(quota.Status.Hard))
+		for name := range quota.Status.Hard {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			pending, ok := usage[corev1.ResourceName(name)]
+			if !ok || pending.Sign() <= 0 {
+				continue
+			}
+			hard := quota.Status.Hard[corev1.ResourceName(name)]
+			used := quota.Status.Used[corev1.ResourceName(name)]
+			used.Add(pending)
+			if used.Cmp(hard) > 0 {
+				return fmt.Sprintf("jobset needs %s of %s, which exceeds resource quota %s", pending.String(), name, quota.Name)
+			}
+		}
+	}
+	return ""
+}
+
+// requestedResources returns the total resources requested by all pods of the JobSet.
+func requestedResources(js *jobset.JobSet) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		pods := int64(rjob.Replicas) * int64(jobParallelism(js, &rjob))
+		for name, quantity := range podRequests(&rjob.Template.Spec.Template.Spec) {
+			sum := total[name]
+			sum.Add(multiplyQuantity(quantity, pods))
+			total[name] = sum
+		}
+	}
+	return total
+}
+
+// multiplyQuantity returns the quantity multiplied by n, e.g. the requests of a pod by the
+// number of pods, without the overflow of multiplying its integer value.
+func multiplyQuantity(quantity resource.Quantity, n int64) resource.Quantity {
+	product := new(inf.Dec).Mul(quantity.AsDec(), inf.NewDec(n, 0))
+	return *resource.NewDecimalQuantity(*product, quantity.Format)
+}
+
+// totalPods returns the number of pods all child jobs of the JobSet run at once. It depends
+// on the parallelism overrides, which can only be updated while the JobSet is suspended if
+// the total is injected into the containers, and the child jobs with an outdated total are
+// recreated when the JobSet is resumed.
+func totalPods(js *jobset.JobSet) int64 {
+	var total int64
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		total += int64(rjob.Replicas) * int64(jobParallelism(js, &rjob))
+	}
+	return total
+}
+
+// gangScheduled reports whether all pods of the JobSet must be scheduled together.
+func gangScheduled(js *jobset.JobSet) bool {
+	return js.Spec.SchedulingPolicy != nil && js.Spec.SchedulingPolicy.Gang != nil
+}
+
+// podGroupName returns the name of the pod group of a gang scheduled JobSet.
+func podGroupName(js *jobset.JobSet) string {
+	if name := js.Spec.SchedulingPolicy.Gang.PodGroupName; name != "" {
+		return name
+	}
+	return js.Name
+}
+
+// podGroupMinMember returns the number of pods of a gang scheduled JobSet which must be
+// scheduled together, i.e. the pods all of its child jobs run at once. A job runs as many
+// pods at once as its parallelism, unless it needs fewer completions.
+func podGroupMinMember(js *jobset.JobSet) int64 {
+	var total int64
+	for i := range js.Spec.ReplicatedJobs {
+		rjob := &js.Spec.ReplicatedJobs[i]
+		pods := jobParallelism(js, rjob)
+		if completions := replicatedJobCompletions(js, rjob); completions != nil && *completions < pods {
+			pods = *completions
+		}
+		total += int64(rjob.Replicas) * int64(pods)
+	}
+	return total
+}
+
+// addEnvVar adds the environment variable to all containers and init containers of the
+// pod spec which do not set a variable of the same name themselves.
+func addEnvVar(podSpec *corev1.PodSpec, envVar corev1.EnvVar) {
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range containers {
+			if !hasEnvVar(&containers[i], envVar.Name) {
+				containers[i].Env = append(containers[i].Env, envVar)
+			}
+		}
+	}
+}
 
-			// Create the job.
-			// TODO(#18): Deal with the case where the job exists but is not owned by the jobset.
-			if err := r.Create(ctx, job); err != nil {
-				return err
+func hasEnvVar(container *corev1.Container, name string) bool {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// jobParallelism returns the number of pods a child job of the replicated job runs at once.
+func jobParallelism(js *jobset.JobSet, rjob *jobset.ReplicatedJob) int32 {
+	if parallelism, ok := js.Spec.ParallelismOverrides[rjob.Name]; ok {
+		return parallelism
+	}
+	if rjob.Template.Spec.Parallelism == nil && js.Spec.JobDefaults != nil {
+		return pointer.Int32Deref(js.Spec.JobDefaults.Parallelism, 1)
+	}
+	return pointer.Int32Deref(rjob.Template.Spec.Parallelism, 1)
+}
+
+// replicatedJobCompletions returns the completions of the child jobs of the replicated job:
+// those of its template, or the JobSet default if the template leaves them unset.
+func replicatedJobCompletions(js *jobset.JobSet, rjob *jobset.ReplicatedJob) *int32 {
+	if rjob.Template.Spec.Completions == nil && js.Spec.JobDefaults != nil {
+		return js.Spec.JobDefaults.Completions
+	}
+	return rjob.Template.Spec.Completions
+}
+
+// podRequests returns the resources requested by a pod: the sum of the requests of its
+// containers, or the largest request of its init containers if that is higher.
+func podRequests(podSpec *corev1.PodSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, c := range podSpec.Containers {
+		for name, quantity := range c.Resources.Requests {
+			sum := requests[name]
+			sum.Add(quantity)
+			requests[name] = sum
+		}
+	}
+	for _, c := range podSpec.InitContainers {
+		for name, quantity := range c.Resources.Requests {
+			if current, ok := requests[name]; !ok || quantity.Cmp(current) > 0 {
+				requests[name] = quantity.DeepCopy()
 			}
-			log.V(2).Info("successfully created job", "job", klog.KObj(job))
 		}
 	}
-	return nil
+	return requests
+}
+
+// allocatableResources returns the total allocatable resources of the ready, schedulable nodes.
+func allocatableResources(nodes []corev1.Node) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for i := range nodes {
+		if nodes[i].Spec.Unschedulable || !nodeReady(&nodes[i]) {
+			continue
+		}
+		for name, quantity := range nodes[i].Status.Allocatable {
+			sum := total[name]
+			sum.Add(quantity)
+			total[name] = sum
+		}
+	}
+	return total
+}
+
+func nodeReady(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// replicatedJobsInCreationOrder returns the replicated jobs of the JobSet sorted by descending
+// creation priority, preserving the order of the spec for equal priorities.
+func replicatedJobsInCreationOrder(js *jobset.JobSet) []jobset.ReplicatedJob {
+	rjobs := make([]jobset.ReplicatedJob, len(js.Spec.ReplicatedJobs))
+	copy(rjobs, js.Spec.ReplicatedJobs)
+	sort.SliceStable(rjobs, func(i, j int) bool {
+		return rjobs[i].CreationPriority > rjobs[j].CreationPriority
+	})
+	return rjobs
 }
 
 // TODO: look into adopting service and updating the selector
@@ -386,44 +2088,163 @@ func (r *JobSetReconciler) createJobs(ctx context.Context, js *jobset.JobSet, ow
 func (r *JobSetReconciler) createHeadlessSvcIfNotExist(ctx context.Context, js *jobset.JobSet, rjob *jobset.ReplicatedJob) error {
 	log := ctrl.LoggerFrom(ctx)
 
-	// Check if service already exists. Service name is <jobSetName>-<replicatedJobName>.
-	// If the service does not exist, create it.
+	// Check if service already exists. Service name is the subdomain of the pods of the
+	// replicatedJob. If the service does not exist, create it.
 	var headlessSvc corev1.Service
 	subdomain := GenSubdomain(js, rjob)
-	if err := r.Get(ctx, types.NamespacedName{Name: subdomain, Namespace: js.Namespace}, &headlessSvc); err != nil {
-		headlessSvc := corev1.Service{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      subdomain,
-				Namespace: js.Namespace,
-			},
-			Spec: corev1.ServiceSpec{
-				ClusterIP: "None",
-				Selector: map[string]string{
-					jobset.JobSetNameKey:        js.Name,
-					jobset.ReplicatedJobNameKey: rjob.Name,
-				},
-			},
+	err := r.Get(ctx, types.NamespacedName{Name: subdomain, Namespace: js.Namespace}, &headlessSvc)
+	if err == nil {
+		// A service of another owner, e.g. another JobSet using the same custom subdomain,
+		// does not select the pods of this JobSet, so their hostnames would not resolve.
+		if !ownedBy(&headlessSvc, js) {
+			return fmt.Errorf("headless service %s of replicatedJob %s already exists and is not owned by the jobset", subdomain, rjob.Name)
 		}
-
-		// Set controller owner reference for garbage collection and reconcilation.
-		if err := ctrl.SetControllerReference(js, &headlessSvc, r.Scheme); err != nil {
-			return err
+		// Services created by earlier versions lack the JobSet name label the children are
+		// listed by.
+		if _, ok := headlessSvc.Labels[jobset.JobSetNameKey]; !ok {
+			patch := client.MergeFrom(headlessSvc.DeepCopy())
+			if headlessSvc.Labels == nil {
+				headlessSvc.Labels = map[string]string{}
+			}
+			headlessSvc.Labels[jobset.JobSetNameKey] = js.Name
+			return r.Patch(ctx, &headlessSvc, patch)
 		}
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+	headlessSvc = corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      subdomain,
+			Namespace: js.Namespace,
+			Labels:    map[string]string{jobset.JobSetNameKey: js.Name},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "None",
+			Selector:  headlessSvcSelector(js, rjob),
+			// Publish the addresses of pods which are not ready yet unless disabled, so
+			// peers can resolve each other during startup.
+			PublishNotReadyAddresses: !customSubdomain(rjob) || pointer.BoolDeref(rjob.Network.PublishNotReadyAddresses, true),
+		},
+	}
 
-		// Create headless service.
-		if err := r.Create(ctx, &headlessSvc); err != nil {
-			return err
-		}
-		log.V(2).Info("successfully created headless service", "service", klog.KObj(&headlessSvc))
+	// Set owner reference for garbage collection and reconcilation.
+	if err := r.setOwnerReference(js, &headlessSvc); err != nil {
+		return err
+	}
+
+	// Create headless service.
+	if err := r.Create(ctx, &headlessSvc); err != nil {
+		return err
 	}
+	log.V(2).Info("successfully created headless service", "service", klog.KObj(&headlessSvc))
 	return nil
 }
 
+// headlessSvcSelector returns the pod selector of the headless Service of the replicated job.
+// The Service named after the JobSet is shared by all replicated jobs without a custom
+// subdomain and selects all pods of the JobSet. The Service of a custom subdomain selects
+// all pods of its replicated job, unless narrowed by the Network options.
+func headlessSvcSelector(js *jobset.JobSet, rjob *jobset.ReplicatedJob) map[string]string {
+	if !customSubdomain(rjob) {
+		return map[string]string{jobset.JobSetNameKey: js.Name}
+	}
+	selector := map[string]string{}
+	for key, value := range rjob.Network.ServiceSelector {
+		selector[key] = value
+	}
+	selector[jobset.JobSetNameKey] = js.Name
+	selector[jobset.ReplicatedJobNameKey] = rjob.Name
+	return selector
+}
+
+// createServiceAccountIfNotExist creates the ServiceAccount managed for the JobSet, named
+// after it, unless it already exists. It reports whether the ServiceAccount is owned by the
+// JobSet.
+func (r *JobSetReconciler) createServiceAccountIfNotExist(ctx context.Context, js *jobset.JobSet) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var serviceAccount corev1.ServiceAccount
+	err := r.Get(ctx, types.NamespacedName{Name: js.Name, Namespace: js.Namespace}, &serviceAccount)
+	if err == nil {
+		return ownedBy(&serviceAccount, js), nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	serviceAccount = corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      js.Name,
+			Namespace: js.Namespace,
+			Labels:    map[string]string{jobset.JobSetNameKey: js.Name},
+		},
+	}
+
+	// Set owner reference so the ServiceAccount is garbage collected with the JobSet.
+	if err := r.setOwnerReference(js, &serviceAccount); err != nil {
+		return false, err
+	}
+	if err := r.Create(ctx, &serviceAccount); err != nil {
+		return false, err
+	}
+	log.V(2).Info("successfully created service account", "serviceAccount", klog.KObj(&serviceAccount))
+	return true, nil
+}
+
+// updateServiceAccountConflictCondition sets the ServiceAccountConflict condition while the
+// ServiceAccount named after the JobSet is not controlled by it, and clears it otherwise.
+func (r *jobSetReconciliation) updateServiceAccountConflictCondition(ctx context.Context, js *jobset.JobSet, owned bool) error {
+	if !owned {
+		return r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
+			Type:    string(jobset.JobSetServiceAccountConflict),
+			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+			Reason:  "ServiceAccountNotOwned",
+			Message: fmt.Sprintf("service account %s already exists and is not owned by the jobset, so the child jobs are not created", js.Name),
+		})
+	}
+	// Only clear a condition which was set before.
+	if meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetServiceAccountConflict)) == nil {
+		return nil
+	}
+	return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+		Type:    string(jobset.JobSetServiceAccountConflict),
+		Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+		Reason:  "ServiceAccountOwned",
+		Message: "the service account is owned by the jobset",
+	})
+}
+
 // executeSuccessPolicy checks the completed jobs against the jobset success policy
-// and updates the jobset status to completed if the success policy conditions are met.
+// and updates the jobset status to completed if the success policy conditions are met,
+// or if completion was requested with the complete-now annotation.
 // Returns a boolean value indicating if the jobset was completed or not.
-func (r *JobSetReconciler) executeSuccessPolicy(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) (bool, error) {
-	if numJobsMatchingSuccessPolicy(js, ownedJobs.successful) >= numJobsExpectedToSucceed(js) {
+func (r *jobSetReconciliation) executeSuccessPolicy(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) (bool, error) {
+	if js.Annotations[jobset.CompleteNowKey] == "true" {
+		js.Status.CompletionPercentage = calculateCompletionPercentage(js, ownedJobs)
+		if err := r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+			Type:    string(jobset.JobSetCompleted),
+			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+			Reason:  "CompletionRequested",
+			Message: fmt.Sprintf("jobset completed on request via the %s annotation", jobset.CompleteNowKey),
+		}); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	// Jobs never complete the JobSet with the Never operator.
+	if js.Spec.SuccessPolicy.Operator == jobset.OperatorNever {
+		return false, nil
+	}
+	if successPolicySatisfied(js, ownedJobs.successful) {
+		var targets []string
+		for i := range js.Spec.ReplicatedJobs {
+			if replicatedJobMatchesSuccessPolicy(js, &js.Spec.ReplicatedJobs[i]) {
+				targets = append(targets, js.Spec.ReplicatedJobs[i].Name)
+			}
+		}
+		r.Record.Eventf(js, corev1.EventTypeNormal, eventReasonSuccessPolicySatisfied, "success policy with operator %s satisfied by replicatedJobs %s", js.Spec.SuccessPolicy.Operator, strings.Join(targets, ", "))
+		js.Status.CompletionPercentage = calculateCompletionPercentage(js, ownedJobs)
 		if err := r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
 			Type:    string(jobset.JobSetCompleted),
 			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
@@ -432,48 +2253,521 @@ func (r *JobSetReconciler) executeSuccessPolicy(ctx context.Context, js *jobset.
 		}); err != nil {
 			return false, err
 		}
-		return true, nil
+		return true, nil
+	}
+	return false, nil
+}
+
+// executeFailurePolicy executes the failure policy of the JobSet. It returns the duration after
+// which to reconcile again, if failures are being aggregated before being acted upon.
+func (r *jobSetReconciliation) executeFailurePolicy(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) (time.Duration, error) {
+	// A failure matched by a FailJobSet rule fails the JobSet, regardless of its restarts.
+	for _, job := range ownedJobs.failed {
+		rule, exitCode, err := r.matchFailurePolicyRule(ctx, js, job)
+		if err != nil {
+			return 0, err
+		}
+		if rule != nil && rule.Action == jobset.FailurePolicyActionFailJobSet {
+			r.recordFailurePolicyTriggered(js, job)
+			return 0, r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
+				Type:    string(jobset.JobSetFailed),
+				Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+				Reason:  "FailurePolicyRuleMatched",
+				Message: fmt.Sprintf("jobset failed due to job %s failing with exit code %d", job.Name, exitCode),
+			})
+		}
+	}
+	// If no failure policy applies to a failed job, the default failure policy is to mark
+	// the JobSet as failed if any of its jobs have failed.
+	maxRestarts, ok := maxRestartsForFailures(js, ownedJobs.failed)
+	if !ok {
+		r.recordFailurePolicyTriggered(js, ownedJobs.failed[0])
+		return 0, r.failJobSet(ctx, js, ownedJobs.failed)
+	}
+	// Wait for the aggregation window to pass, so further failures are coalesced into the same restart.
+	if remaining := failureAggregationRemaining(js, ownedJobs.failed, time.Now()); remaining > 0 {
+		ctrl.LoggerFrom(ctx).V(2).Info("aggregating job failures before executing failure policy", "remaining", remaining)
+		return remaining, nil
+	}
+	// To reach this point a job must have failed.
+	r.recordFailurePolicyTriggered(js, ownedJobs.failed[0])
+	return 0, r.executeRestartPolicy(ctx, js, ownedJobs, maxRestarts)
+}
+
+// recordFailurePolicyTriggered records an event for the failed job the failure policy acts upon.
+func (r *JobSetReconciler) recordFailurePolicyTriggered(js *jobset.JobSet, job *batchv1.Job) {
+	r.Record.Eventf(js, corev1.EventTypeWarning, eventReasonFailurePolicyTriggered, "failure policy triggered by failed job %s of replicatedJob %s", job.Name, job.Labels[jobset.ReplicatedJobNameKey])
+}
+
+// failurePolicy returns the failure policy applying to the child jobs of the named
+// replicated job: its own override if set, or else the failure policy of the JobSet.
+func failurePolicy(js *jobset.JobSet, replicatedJobName string) *jobset.FailurePolicy {
+	for i := range js.Spec.ReplicatedJobs {
+		if js.Spec.ReplicatedJobs[i].Name == replicatedJobName && js.Spec.ReplicatedJobs[i].FailurePolicy != nil {
+			return js.Spec.ReplicatedJobs[i].FailurePolicy
+		}
+	}
+	return js.Spec.FailurePolicy
+}
+
+// podFailuresIgnored returns whether the failed pods of the running job are ignored by a
+// rule of the failure policy. The number of ignored failures is recorded on the job, so
+// its pods are only listed again once more of them fail.
+func (r *JobSetReconciler) podFailuresIgnored(ctx context.Context, js *jobset.JobSet, job *batchv1.Job) (bool, error) {
+	failed := strconv.Itoa(int(job.Status.Failed))
+	if job.Annotations[IgnoredFailuresKey] == failed {
+		return true, nil
+	}
+	rule, _, err := r.matchFailurePolicyRule(ctx, js, job)
+	if err != nil || rule == nil || rule.Action != jobset.FailurePolicyActionIgnore {
+		return false, err
+	}
+	patch := client.MergeFrom(job.DeepCopy())
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[IgnoredFailuresKey] = failed
+	if err := r.Patch(ctx, job, patch); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// matchFailurePolicyRule returns the first rule of the failure policy of the failed job which
+// matches an exit code of the failed containers of its pods, along with the matched exit code.
+// It returns nil if the failure policy has no rules, or none of them matches.
+func (r *JobSetReconciler) matchFailurePolicyRule(ctx context.Context, js *jobset.JobSet, job *batchv1.Job) (*jobset.FailurePolicyRule, int32, error) {
+	policy := failurePolicy(js, job.Labels[jobset.ReplicatedJobNameKey])
+	if policy == nil || len(policy.Rules) == 0 {
+		return nil, 0, nil
+	}
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, client.InNamespace(job.Namespace), client.MatchingLabels{jobset.JobNameKey: job.Name}); err != nil {
+		return nil, 0, err
+	}
+	exitCodes := failedContainerExitCodes(job, podList.Items)
+	for i := range policy.Rules {
+		for _, exitCode := range policy.Rules[i].OnExitCodes {
+			if exitCodes[exitCode] {
+				return &policy.Rules[i], exitCode, nil
+			}
+		}
+	}
+	return nil, 0, nil
+}
+
+// failedContainerExitCodes returns the non-zero exit codes of the terminated containers of
+// the failed pods of the job. Pods of an earlier job with the same name are left out.
+func failedContainerExitCodes(job *batchv1.Job, pods []corev1.Pod) map[int32]bool {
+	exitCodes := map[int32]bool{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodFailed || !metav1.IsControlledBy(pod, job) {
+			continue
+		}
+		for _, status := range util.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+			if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+				exitCodes[terminated.ExitCode] = true
+			}
+		}
+	}
+	return exitCodes
+}
+
+// maxRestartsForFailures returns the lowest maxRestarts of the failure policies applying to
+// the failed jobs, so the failure of a critical replicated job is never masked by a more
+// tolerant one. It returns false if no failure policy applies to one of the failed jobs.
+func maxRestartsForFailures(js *jobset.JobSet, failedJobs []*batchv1.Job) (int, bool) {
+	maxRestarts := -1
+	for _, job := range failedJobs {
+		policy := failurePolicy(js, job.Labels[jobset.ReplicatedJobNameKey])
+		if policy == nil {
+			return 0, false
+		}
+		if maxRestarts < 0 || policy.MaxRestarts < maxRestarts {
+			maxRestarts = policy.MaxRestarts
+		}
+	}
+	return maxRestarts, maxRestarts >= 0
+}
+
+// deleteExpiredJobSet deletes the finished JobSet if its TTL after finishing has expired,
+// or its immediate cleanup was requested, leaving its child jobs to the garbage collector.
+// Otherwise it returns how long remains until it expires, or zero if it has no TTL.
+func (r *JobSetReconciler) deleteExpiredJobSet(ctx context.Context, js *jobset.JobSet) (time.Duration, error) {
+	if remaining, ok := expirationRemaining(js); !ok || remaining > 0 {
+		return remaining, nil
+	}
+	// The JobSet may have been recreated under the same name, or its TTL extended, since it
+	// was read, so the expiry is checked again against the latest object, which is only
+	// deleted if it has not changed since.
+	var latest jobset.JobSet
+	if err := r.Get(ctx, client.ObjectKeyFromObject(js), &latest); err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+	if latest.UID != js.UID {
+		return 0, nil
+	}
+	if remaining, ok := expirationRemaining(&latest); !ok || remaining > 0 {
+		return remaining, nil
+	}
+	ctrl.LoggerFrom(ctx).V(2).Info("deleting finished jobset")
+	err := r.Delete(ctx, &latest, client.PropagationPolicy(metav1.DeletePropagationBackground), client.Preconditions{
+		UID:             &latest.UID,
+		ResourceVersion: &latest.ResourceVersion,
+	})
+	// A conflict means the JobSet changed after it was read, which triggers another reconcile.
+	if apierrors.IsConflict(err) {
+		return 0, nil
+	}
+	return 0, client.IgnoreNotFound(err)
+}
+
+// expirationRemaining returns how long remains until the finished JobSet expires, which is
+// immediately if its cleanup was requested. It reports false if it never expires.
+func expirationRemaining(js *jobset.JobSet) (time.Duration, bool) {
+	if js.Annotations[jobset.CleanupNowKey] == "true" && jobSetFinished(js) {
+		return 0, true
+	}
+	return ttlAfterFinishedRemaining(js, time.Now())
+}
+
+// ttlAfterFinishedRemaining returns how long remains until the TTL after finishing of the
+// JobSet expires, counted from the transition of its Completed or Failed condition. It
+// reports false if the JobSet has no TTL or has not finished.
+func ttlAfterFinishedRemaining(js *jobset.JobSet, now time.Time) (time.Duration, bool) {
+	ttl := js.Spec.TTLSecondsAfterFinished
+	if ttl == nil {
+		return 0, false
+	}
+	for _, c := range js.Status.Conditions {
+		if (c.Type == string(jobset.JobSetCompleted) || c.Type == string(jobset.JobSetFailed)) && c.Status == metav1.ConditionTrue {
+			return c.LastTransitionTime.Add(time.Duration(*ttl) * time.Second).Sub(now), true
+		}
+	}
+	return 0, false
+}
+
+// reasonDeadlineExceeded is the reason of the Failed condition of a JobSet which was active
+// longer than its active deadline.
+const reasonDeadlineExceeded = "DeadlineExceeded"
+
+// activeDeadlineRemaining returns how long the JobSet may remain active before its active
+// deadline is reached. The active time is counted from the creation of its first child jobs,
+// across restarts, and excludes the time spent suspended. It reports false if the JobSet has
+// no active deadline, has not started or is suspended, since the deadline does not advance
+// until it runs.
+func activeDeadlineRemaining(js *jobset.JobSet, now time.Time) (time.Duration, bool) {
+	deadline := js.Spec.ActiveDeadlineSeconds
+	if deadline == nil || js.Status.StartTime == nil || pointer.BoolDeref(js.Spec.Suspend, false) {
+		return 0, false
+	}
+	active := now.Sub(js.Status.StartTime.Time)
+	if js.Status.SuspendedDuration != nil {
+		active -= js.Status.SuspendedDuration.Duration
+	}
+	// The JobSet may have been resumed without its suspension having been accounted for yet.
+	if suspended := meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetSuspended)); suspended != nil && suspended.Status == metav1.ConditionTrue {
+		active -= now.Sub(suspended.LastTransitionTime.Time)
+	}
+	return time.Duration(*deadline)*time.Second - active, true
+}
+
+// failureAggregationRemaining returns how long remains of the failure aggregation window, which
+// starts at the earliest failure of a failed job. Failures without a known failure time, such
+// as pod failures of jobs which have not failed yet, do not start the window.
+func failureAggregationRemaining(js *jobset.JobSet, failedJobs []*batchv1.Job, now time.Time) time.Duration {
+	if js.Spec.FailurePolicy == nil {
+		return 0
+	}
+	window := js.Spec.FailurePolicy.AggregationWindow
+	if window == nil || window.Duration <= 0 {
+		return 0
+	}
+	var firstFailure *metav1.Time
+	for _, job := range failedJobs {
+		for _, c := range job.Status.Conditions {
+			if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue && (firstFailure == nil || c.LastTransitionTime.Before(firstFailure)) {
+				firstFailure = c.LastTransitionTime.DeepCopy()
+			}
+		}
+	}
+	if firstFailure == nil {
+		return 0
+	}
+	return firstFailure.Add(window.Duration).Sub(now)
+}
+
+func (r *jobSetReconciliation) executeRestartPolicy(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs, maxRestarts int) error {
+	if maxRestarts == 0 {
+		return r.failJobSet(ctx, js, ownedJobs.failed)
+	}
+	return r.restartPolicyRecreateAll(ctx, js, ownedJobs, maxRestarts)
+}
+
+func (r *jobSetReconciliation) restartPolicyRecreateAll(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs, maxRestarts int) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	// If JobSet has reached max number of restarts, mark it as failed and return.
+	if js.Status.RestartsCountTowardsMax >= maxRestarts {
+		return r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
+			Type:    string(jobset.JobSetFailed),
+			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+			Reason:  "ReachedMaxRestarts",
+			Message: "jobset failed due to reaching max number of restarts",
+		})
+	}
+
+	// Delete the headless services which should be recreated on restart. They are
+	// created again along with the jobs of the next run.
+	if err := r.deleteHeadlessSvcsForRestart(ctx, js); err != nil {
+		return err
+	}
+
+	// Increment JobSet restarts. This will trigger reconciliation and result in deletions
+	// of old jobs not part of the current jobSet run.
+	js.Status.Restarts += 1
+	js.Status.RestartsCountTowardsMax += 1
+	updateCondition(js, restartingCondition())
+	if err := r.updateStatus(ctx, js, corev1.EventTypeWarning, "Restarting", fmt.Sprintf("restarting jobset, attempt %d", js.Status.Restarts)); err != nil {
+		return err
+	}
+	metrics.JobSetRestarted(js.Namespace)
+	log.V(2).Info("attempting restart", "restart attempt", js.Status.Restarts)
+	return nil
+}
+
+// restartFailedJobSetWithUpdatedImages restarts the failed JobSet if the container images of its
+// replicated jobs were updated after its child jobs were created, e.g. to fix a bad image. The
+// Failed condition is cleared, and the jobs of the failed attempt are replaced by jobs created
+// from the updated templates, with a fresh budget of restarts. A JobSet which exceeded its
+// active deadline is not restarted, since it would fail again right away. It reports whether
+// the JobSet was restarted.
+func (r *jobSetReconciliation) restartFailedJobSetWithUpdatedImages(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) (bool, error) {
+	failed := meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetFailed))
+	if failed == nil || failed.Status != metav1.ConditionTrue || failed.Reason == reasonDeadlineExceeded {
+		return false, nil
+	}
+	if !containerImagesUpdated(js, util.Concat(ownedJobs.active, ownedJobs.successful, ownedJobs.failed)...) {
+		return false, nil
+	}
+	if err := r.deleteHeadlessSvcsForRestart(ctx, js); err != nil {
+		return false, err
+	}
+	js.Status.Restarts += 1
+	js.Status.RestartsCountTowardsMax = 0
+	updateCondition(js, metav1.Condition{
+		Type:    string(jobset.JobSetFailed),
+		Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+		Reason:  "ContainerImagesUpdated",
+		Message: "jobset is restarted with updated container images",
+	})
+	updateCondition(js, restartingCondition())
+	if err := r.updateStatus(ctx, js, corev1.EventTypeNormal, "Restarting", fmt.Sprintf("restarting failed jobset with updated container images, attempt %d", js.Status.Restarts)); err != nil {
+		return false, err
+	}
+	metrics.JobSetRestarted(js.Namespace)
+	return true, nil
+}
+
+// containerImagesUpdated reports whether the container images of any of the jobs differ
+// from those of the template of their replicated job, which are mutable while the JobSet
+// is failed or suspended.
+func containerImagesUpdated(js *jobset.JobSet, jobs ...*batchv1.Job) bool {
+	templates := map[string]*corev1.PodSpec{}
+	for i := range js.Spec.ReplicatedJobs {
+		templates[js.Spec.ReplicatedJobs[i].Name] = &js.Spec.ReplicatedJobs[i].Template.Spec.Template.Spec
+	}
+	for _, job := range jobs {
+		template, ok := templates[job.Labels[jobset.ReplicatedJobNameKey]]
+		if !ok {
+			continue
+		}
+		if !apiequality.Semantic.DeepEqual(containerImages(&job.Spec.Template.Spec), containerImages(template)) {
+			return true
+		}
+	}
+	return false
+}
+
+// totalPodsEnvOutdated reports whether the total number of pods injected into the containers
+// of the job differs from the current one, because a parallelism override was updated.
+func totalPodsEnvOutdated(js *jobset.JobSet, job *batchv1.Job) bool {
+	name := js.Spec.TotalPodsEnvName
+	if name == "" {
+		return false
+	}
+	var template *corev1.PodSpec
+	for i := range js.Spec.ReplicatedJobs {
+		if js.Spec.ReplicatedJobs[i].Name == job.Labels[jobset.ReplicatedJobNameKey] {
+			template = &js.Spec.ReplicatedJobs[i].Template.Spec.Template.Spec
+		}
+	}
+	if template == nil {
+		return false
+	}
+	// Variables set by the containers themselves are not injected.
+	setByTemplate := map[string]bool{}
+	templateContainers := util.Concat(template.InitContainers, template.Containers)
+	for i := range templateContainers {
+		setByTemplate[templateContainers[i].Name] = hasEnvVar(&templateContainers[i], name)
+	}
+	want := strconv.FormatInt(totalPods(js), 10)
+	for _, container := range util.Concat(job.Spec.Template.Spec.InitContainers, job.Spec.Template.Spec.Containers) {
+		if setByTemplate[container.Name] {
+			continue
+		}
+		for _, env := range container.Env {
+			if env.Name == name && env.Value != want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containerImages returns the images of the init containers and containers of the pod spec.
+func containerImages(podSpec *corev1.PodSpec) []string {
+	var images []string
+	for _, container := range util.Concat(podSpec.InitContainers, podSpec.Containers) {
+		images = append(images, container.Image)
+	}
+	return images
+}
+
+// updateRestartingCondition sets the Restarting condition while jobs of previous restart
+// attempts are being deleted, and clears it once they are gone, at which point the jobs
+// of the current attempt have been recreated.
+func (r *jobSetReconciliation) updateRestartingCondition(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) error {
+	if len(ownedJobs.delete) > 0 {
+		return r.ensureCondition(ctx, js, corev1.EventTypeNormal, restartingCondition())
+	}
+	return r.ensureCondition(ctx, js, corev1.EventTypeNormal, metav1.Condition{
+		Type:    string(jobset.JobSetRestarting),
+		Status:  metav1.ConditionStatus(corev1.ConditionFalse),
+		Reason:  "JobsRecreated",
+		Message: "jobs of the current restart attempt have been recreated",
+	})
+}
+
+func restartingCondition() metav1.Condition {
+	return metav1.Condition{
+		Type:    string(jobset.JobSetRestarting),
+		Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+		Reason:  "DeletingPreviousJobs",
+		Message: "jobs of previous restart attempts are being deleted",
+	}
+}
+
+// restartDeletions returns the jobs of previous restart attempts to delete in this
+// reconciliation. If the failure policy sets maxUnavailable, ready jobs of previous
+// attempts are only deleted while fewer than maxUnavailable child jobs are down, i.e.
+// neither ready nor completed. Finished or unready jobs of previous attempts are always
+// deleted, since they provide no capacity.
+func restartDeletions(js *jobset.JobSet, ownedJobs *childJobs) []*batchv1.Job {
+	// With the BlueGreen restart strategy, the jobs of the attempt being replaced keep running
+	// until all of their replacements are ready. Jobs of older attempts, left over when the
+	// replacement attempt failed before it was ready, are deleted right away, so at most one
+	// previous attempt is kept. This is decided from the jobs found on each reconciliation,
+	// so jobs of previous attempts are still deleted if the controller restarted in the
+	// middle of a restart.
+	if restartStrategy(js) == jobset.RestartStrategyBlueGreen {
+		if replacementJobsReady(js, ownedJobs) {
+			return ownedJobs.delete
+		}
+		var deletions []*batchv1.Job
+		for _, job := range ownedJobs.delete {
+			if attempt, err := strconv.Atoi(job.Labels[RestartsKey]); err != nil || attempt < js.Status.Restarts-1 {
+				deletions = append(deletions, job)
+			}
+		}
+		return deletions
+	}
+	if js.Spec.FailurePolicy == nil || js.Spec.FailurePolicy.MaxUnavailable == nil {
+		return ownedJobs.delete
+	}
+	var deletions, running []*batchv1.Job
+	for _, job := range ownedJobs.delete {
+		// Jobs already being deleted are down, and need not be deleted again.
+		if job.DeletionTimestamp != nil {
+			continue
+		}
+		if finished, _ := jobFinished(job); finished || !jobReady(job) {
+			deletions = append(deletions, job)
+			continue
+		}
+		running = append(running, job)
 	}
-	return false, nil
+	total := 0
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		total += rjob.Replicas
+	}
+	// Recreated jobs only provide capacity once their pods are ready.
+	available := len(ownedJobs.successful) + len(running)
+	for _, job := range ownedJobs.active {
+		if jobReady(job) {
+			available++
+		}
+	}
+	budget := int(*js.Spec.FailurePolicy.MaxUnavailable) - (total - available)
+	// Delete in a stable order, so the same jobs are picked across reconciliations.
+	sort.Slice(running, func(i, j int) bool { return running[i].Name < running[j].Name })
+	for i := 0; i < budget && i < len(running); i++ {
+		deletions = append(deletions, running[i])
+	}
+	return deletions
 }
 
-func (r *JobSetReconciler) executeFailurePolicy(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) error {
-	// If no failure policy is defined, the default failure policy is to mark the JobSet
-	// as failed if any of its jobs have failed.
-	if js.Spec.FailurePolicy == nil {
-		return r.failJobSet(ctx, js)
+// replacementJobsReady reports whether all child jobs of the current attempt exist and are
+// either ready or completed.
+func replacementJobsReady(js *jobset.JobSet, ownedJobs *childJobs) bool {
+	expected := 0
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		expected += rjob.Replicas
 	}
-	// To reach this point a job must have failed.
-	return r.executeRestartPolicy(ctx, js, ownedJobs)
+	ready := len(ownedJobs.successful)
+	for _, job := range ownedJobs.active {
+		if jobReady(job) {
+			ready++
+		}
+	}
+	return ready >= expected
 }
 
-func (r *JobSetReconciler) executeRestartPolicy(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) error {
-	if js.Spec.FailurePolicy.MaxRestarts == 0 {
-		return r.failJobSet(ctx, js)
+// restartStrategy returns the restart strategy of the JobSet, defaulting to Recreate.
+func restartStrategy(js *jobset.JobSet) jobset.RestartStrategy {
+	if js.Spec.FailurePolicy == nil || js.Spec.FailurePolicy.RestartStrategy == "" {
+		return jobset.RestartStrategyRecreate
 	}
-	return r.restartPolicyRecreateAll(ctx, js, ownedJobs)
+	return js.Spec.FailurePolicy.RestartStrategy
 }
 
-func (r *JobSetReconciler) restartPolicyRecreateAll(ctx context.Context, js *jobset.JobSet, ownedJobs *childJobs) error {
+// deleteHeadlessSvcsForRestart deletes the headless services of the replicated jobs
+// with a Recreate service restart policy.
+func (r *JobSetReconciler) deleteHeadlessSvcsForRestart(ctx context.Context, js *jobset.JobSet) error {
 	log := ctrl.LoggerFrom(ctx)
 
-	// If JobSet has reached max number of restarts, mark it as failed and return.
-	if js.Status.Restarts >= js.Spec.FailurePolicy.MaxRestarts {
-		return r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
-			Type:    string(jobset.JobSetFailed),
-			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
-			Reason:  "ReachedMaxRestarts",
-			Message: "jobset failed due to reaching max number of restarts",
-		})
-	}
-
-	// Increment JobSet restarts. This will trigger reconciliation and result in deletions
-	// of old jobs not part of the current jobSet run.
-	js.Status.Restarts += 1
-	if err := r.updateStatus(ctx, js, corev1.EventTypeWarning, "Restarting", fmt.Sprintf("restarting jobset, attempt %d", js.Status.Restarts)); err != nil {
-		return err
+	for i := range js.Spec.ReplicatedJobs {
+		rjob := &js.Spec.ReplicatedJobs[i]
+		if !dnsHostnamesEnabled(rjob) || rjob.Network.ServiceRestartPolicy != jobset.ServiceRestartPolicyRecreate {
+			continue
+		}
+		svc := &corev1.Service{}
+		if err := r.Get(ctx, types.NamespacedName{Name: GenSubdomain(js, rjob), Namespace: js.Namespace}, svc); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		// Leave services of other owners alone.
+		if !ownedBy(svc, js) {
+			continue
+		}
+		if err := r.Delete(ctx, svc, client.Preconditions{UID: &svc.UID}); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		log.V(2).Info("successfully deleted headless service for restart", "service", klog.KObj(svc))
 	}
-	log.V(2).Info("attempting restart", "restart attempt", js.Status.Restarts)
 	return nil
 }
 
@@ -497,28 +2791,113 @@ func (r *JobSetReconciler) deleteJobs(ctx context.Context, jobsForDeletion []*ba
 	return errors.Join(finalErrs...)
 }
 
+// updateJobSetStatus updates the status of the JobSet, retrying on conflicts. A conflict is
+// only retried, with the desired status applied to the latest JobSet, if the status of the
+// latest JobSet is still the one the desired status was computed from, i.e. the JobSet was
+// updated concurrently but not its status. Otherwise the conflict is returned, so that the
+// JobSet is reconciled again from its latest version.
+func (r *jobSetReconciliation) updateJobSetStatus(ctx context.Context, js *jobset.JobSet) error {
+	status := js.Status.DeepCopy()
+	statusChanged := false
+	err := retry.OnError(r.statusUpdateBackoff(), func(err error) bool {
+		return apierrors.IsConflict(err) && !statusChanged
+	}, func() error {
+		err := r.Status().Update(ctx, js)
+		if !apierrors.IsConflict(err) {
+			return err
+		}
+		var latest jobset.JobSet
+		if err := r.Get(ctx, client.ObjectKeyFromObject(js), &latest); err != nil {
+			return err
+		}
+		if !apiequality.Semantic.DeepEqual(latest.Status, r.statusBase) {
+			statusChanged = true
+			return err
+		}
+		latest.DeepCopyInto(js)
+		status.DeepCopyInto(&js.Status)
+		return err
+	})
+	if err == nil {
+		r.statusBase = *js.Status.DeepCopy()
+	}
+	return err
+}
+
+func (r *JobSetReconciler) statusUpdateBackoff() wait.Backoff {
+	backoff := retry.DefaultRetry
+	if r.StatusUpdateRetries > 0 {
+		backoff.Steps = r.StatusUpdateRetries
+	}
+	return backoff
+}
+
 // updateStatus updates the status of a JobSet.
-func (r *JobSetReconciler) updateStatus(ctx context.Context, js *jobset.JobSet, eventType, eventReason, eventMsg string) error {
-	if err := r.Status().Update(ctx, js); err != nil {
+func (r *jobSetReconciliation) updateStatus(ctx context.Context, js *jobset.JobSet, eventType, eventReason, eventMsg string) error {
+	if err := r.updateJobSetStatus(ctx, js); err != nil {
 		return err
 	}
 	r.Record.Eventf(js, eventType, eventReason, eventMsg)
 	return nil
 }
 
-func (r *JobSetReconciler) ensureCondition(ctx context.Context, js *jobset.JobSet, eventType string, condition metav1.Condition) error {
+func (r *jobSetReconciliation) ensureCondition(ctx context.Context, js *jobset.JobSet, eventType string, condition metav1.Condition) error {
+	transition := conditionTransition(js, condition)
 	if !updateCondition(js, condition) {
 		return nil
 	}
-	if err := r.Status().Update(ctx, js); err != nil {
+	if err := r.updateJobSetStatus(ctx, js); err != nil {
 		return err
 	}
-
-	r.Record.Eventf(js, eventType, condition.Type, condition.Reason)
+	// Only transitions are recorded, not refreshed messages of a condition.
+	if transition {
+		r.recordConditionTransition(ctx, js, eventType, condition)
+	}
 	return nil
 }
 
-func (r *JobSetReconciler) failJobSet(ctx context.Context, js *jobset.JobSet) error {
+// conditionTransition reports whether setting the condition changes its status.
+func conditionTransition(js *jobset.JobSet, condition metav1.Condition) bool {
+	existing := meta.FindStatusCondition(js.Status.Conditions, condition.Type)
+	return existing == nil || existing.Status != condition.Status
+}
+
+// recordConditionTransition records an event for the transition of the condition, and
+// updates the metrics and notifies of the JobSet finishing.
+func (r *JobSetReconciler) recordConditionTransition(ctx context.Context, js *jobset.JobSet, eventType string, condition metav1.Condition) {
+	r.Record.Eventf(js, eventType, condition.Type, "%s: %s", condition.Reason, condition.Message)
+	switch jobset.JobSetConditionType(condition.Type) {
+	case jobset.JobSetCompleted:
+		metrics.JobSetCompleted(js.Namespace)
+		r.notify(ctx, js, notifier.EventCompleted)
+	case jobset.JobSetFailed:
+		metrics.JobSetFailed(js.Namespace)
+		r.notify(ctx, js, notifier.EventFailed)
+	}
+}
+
+// notify queues the event for delivery by the configured notifier, if any. Delivery is
+// asynchronous and best effort, so failures are logged rather than failing the reconciliation.
+func (r *JobSetReconciler) notify(ctx context.Context, js *jobset.JobSet, event notifier.Event) {
+	if r.Notifier == nil {
+		return
+	}
+	if err := r.Notifier.Enqueue(js, event); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "notifying jobset state change", "event", event)
+	}
+}
+
+// failJobSet marks the JobSet as failed due to the given failed jobs. If a job failed because
+// its backoffLimit was exceeded, the condition reports it with a distinct reason.
+func (r *jobSetReconciliation) failJobSet(ctx context.Context, js *jobset.JobSet, failedJobs []*batchv1.Job) error {
+	if job := backoffLimitExceededJob(failedJobs); job != nil {
+		return r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
+			Type:    string(jobset.JobSetFailed),
+			Status:  metav1.ConditionStatus(corev1.ConditionTrue),
+			Reason:  jobReasonBackoffLimitExceeded,
+			Message: fmt.Sprintf("jobset failed due to job %s exceeding its backoff limit", job.Name),
+		})
+	}
 	return r.ensureCondition(ctx, js, corev1.EventTypeWarning, metav1.Condition{
 		Type:    string(jobset.JobSetFailed),
 		Status:  metav1.ConditionStatus(corev1.ConditionTrue),
@@ -527,6 +2906,23 @@ func (r *JobSetReconciler) failJobSet(ctx context.Context, js *jobset.JobSet) er
 	})
 }
 
+// jobReasonBackoffLimitExceeded is the reason of the Failed condition of a job whose pods
+// failed more often than its backoffLimit allows.
+const jobReasonBackoffLimitExceeded = "BackoffLimitExceeded"
+
+// backoffLimitExceededJob returns the first of the jobs which failed because its
+// backoffLimit was exceeded, or nil if there is none.
+func backoffLimitExceededJob(jobs []*batchv1.Job) *batchv1.Job {
+	for _, job := range jobs {
+		for _, c := range job.Status.Conditions {
+			if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue && c.Reason == jobReasonBackoffLimitExceeded {
+				return job
+			}
+		}
+	}
+	return nil
+}
+
 func updateCondition(js *jobset.JobSet, condition metav1.Condition) bool {
 	condition.LastTransitionTime = metav1.Now()
 	for i, val := range js.Status.Conditions {
@@ -534,6 +2930,12 @@ func updateCondition(js *jobset.JobSet, condition metav1.Condition) bool {
 			js.Status.Conditions[i] = condition
 			// Condition found but different status so we should update
 			return true
+		} else if condition.Type == val.Type && (condition.Reason != val.Reason || condition.Message != val.Message) {
+			// Same status but outdated details, e.g. progress counts, so we should update
+			// without moving the transition time
+			condition.LastTransitionTime = val.LastTransitionTime
+			js.Status.Conditions[i] = condition
+			return true
 		} else if condition.Type == val.Type && condition.Status == val.Status {
 			// Duplicate condition so no update
 			return false
@@ -549,7 +2951,7 @@ func updateCondition(js *jobset.JobSet, condition metav1.Condition) bool {
 func constructJobsFromTemplate(js *jobset.JobSet, rjob *jobset.ReplicatedJob, ownedJobs *childJobs) ([]*batchv1.Job, error) {
 	var jobs []*batchv1.Job
 	for jobIdx := 0; jobIdx < rjob.Replicas; jobIdx++ {
-		jobName := genJobName(js, rjob, jobIdx)
+		jobName := GenJobName(js, rjob, jobIdx)
 		if create := shouldCreateJob(jobName, ownedJobs); !create {
 			continue
 		}
@@ -567,32 +2969,226 @@ func constructJob(js *jobset.JobSet, rjob *jobset.ReplicatedJob, jobIdx int) (*b
 		ObjectMeta: metav1.ObjectMeta{
 			Labels:      util.CloneMap(rjob.Template.Labels),
 			Annotations: util.CloneMap(rjob.Template.Annotations),
-			Name:        genJobName(js, rjob, jobIdx),
+			Name:        GenJobName(js, rjob, jobIdx),
 			Namespace:   js.Namespace,
 		},
 		Spec: *rjob.Template.Spec.DeepCopy(),
 	}
+	// Merge the JobSet pod annotations into the pod template, keeping its own annotations.
+	addPodAnnotations(&job.Spec.Template, js.Spec.PodAnnotations)
+
+	// Propagate the selected labels and annotations of the JobSet to both job and pod
+	// template spec, before the JobSet labels and annotations are set so they always win.
+	propagateMetadata(job, js)
+	propagateMetadata(&job.Spec.Template, js)
+
 	// Label and annotate both job and pod template spec.
 	labelAndAnnotateObject(job, js, rjob, jobIdx)
 	labelAndAnnotateObject(&job.Spec.Template, js, rjob, jobIdx)
+	setGenerationLabel(job, js)
+	setGenerationLabel(&job.Spec.Template, js)
+
+	// Label both job and pod template spec with the workload type, if set.
+	if js.Spec.WorkloadType != "" {
+		setWorkloadTypeLabel(job, js.Spec.WorkloadType)
+		setWorkloadTypeLabel(&job.Spec.Template, js.Spec.WorkloadType)
+	}
+
+	// Annotate the pods with the pod group of the JobSet, if gang scheduling is requested.
+	if gangScheduled(js) {
+		annotations := util.CloneMap(job.Spec.Template.Annotations)
+		annotations[jobset.PodGroupNameKey] = podGroupName(js)
+		annotations[jobset.PodGroupMinMemberKey] = strconv.FormatInt(podGroupMinMember(js), 10)
+		job.Spec.Template.Annotations = annotations
+	}
+
+	// Inject the total number of pods of the JobSet into all containers, if requested.
+	if js.Spec.TotalPodsEnvName != "" {
+		addEnvVar(&job.Spec.Template.Spec, corev1.EnvVar{
+			Name:  js.Spec.TotalPodsEnvName,
+			Value: strconv.FormatInt(totalPods(js), 10),
+		})
+	}
 
-	// If enableDNSHostnames is set, update job spec to set subdomain as
-	// job name (a headless service with same name as job will be created later).
+	// Inject the stable DNS hostname of the coordinator pod into all containers, if set and
+	// the pod exists.
+	if js.Spec.Coordinator != nil && invalidCoordinatorReason(js) == "" {
+		addEnvVar(&job.Spec.Template.Spec, corev1.EnvVar{
+			Name:  jobset.CoordinatorEnvName,
+			Value: coordinatorHostname(js),
+		})
+	}
+
+	// If enableDNSHostnames is set, update job spec to set the subdomain
+	// (a headless service with the same name will be created later).
 	if dnsHostnamesEnabled(rjob) {
 		job.Spec.Template.Spec.Subdomain = GenSubdomain(js, rjob)
 	}
 
+	// Set the DNS policy and parameters of the replicated job on pod templates that do not
+	// set their own.
+	if rjob.Network != nil {
+		if rjob.Network.DNSPolicy != "" && job.Spec.Template.Spec.DNSPolicy == "" {
+			job.Spec.Template.Spec.DNSPolicy = rjob.Network.DNSPolicy
+		}
+		if rjob.Network.DNSConfig != nil && job.Spec.Template.Spec.DNSConfig == nil {
+			job.Spec.Template.Spec.DNSConfig = rjob.Network.DNSConfig.DeepCopy()
+		}
+	}
+
+	// Apply the JobSet defaults for the completions and parallelism unset in the template.
+	if defaults := js.Spec.JobDefaults; defaults != nil {
+		if job.Spec.Completions == nil && defaults.Completions != nil {
+			job.Spec.Completions = pointer.Int32(*defaults.Completions)
+		}
+		if job.Spec.Parallelism == nil && defaults.Parallelism != nil {
+			job.Spec.Parallelism = pointer.Int32(*defaults.Parallelism)
+		}
+	}
+
+	// Apply the parallelism override for the replicated job, if any.
+	if parallelism, ok := js.Spec.ParallelismOverrides[rjob.Name]; ok {
+		job.Spec.Parallelism = pointer.Int32(parallelism)
+	}
+
+	// Run the pods under the ServiceAccount managed for the JobSet, if requested.
+	if managedServiceAccount(js) {
+		job.Spec.Template.Spec.ServiceAccountName = js.Name
+	}
+
+	// Set the JobSet scheduler name on pod templates that do not set their own.
+	if js.Spec.SchedulerName != "" && job.Spec.Template.Spec.SchedulerName == "" {
+		job.Spec.Template.Spec.SchedulerName = js.Spec.SchedulerName
+	}
+
+	// Add the shared volumes of the JobSet and of the replicated job to the pod template spec.
+	addSharedVolumes(&job.Spec.Template.Spec, js.Spec.SharedVolumes)
+	addSharedVolumes(&job.Spec.Template.Spec, rjob.SharedVolumes)
+
+	// Apply the host namespaces of the replicated job to the pod template spec.
+	if rjob.HostNamespaces != nil {
+		setHostNamespaces(&job.Spec.Template.Spec, rjob.HostNamespaces)
+	}
+
+	// Pin the job to the topology domain derived from its index, if requested.
+	if rjob.IndexNodeAffinity != nil {
+		setIndexNodeAffinity(&job.Spec.Template.Spec, rjob.IndexNodeAffinity, jobIdx)
+	}
+
 	// If this job should be exclusive per topology, set the pod affinities/anti-affinities accordingly.
-	if topologyDomain, ok := js.Annotations[jobset.ExclusiveKey]; ok {
+	// The exclusive placement of the replicated job takes precedence over the JobSet annotation.
+	if rjob.ExclusivePlacement != nil {
+		setExclusiveAffinities(job, rjob.ExclusivePlacement.TopologyKey)
+	} else if topologyDomain, ok := js.Annotations[jobset.ExclusiveKey]; ok {
 		setExclusiveAffinities(job, topologyDomain)
 	}
 	// if Suspend is set, then we assume all jobs will be suspended also.
-	jobsetSuspended := js.Spec.Suspend != nil && *js.Spec.Suspend
-	job.Spec.Suspend = pointer.Bool(jobsetSuspended)
+	job.Spec.Suspend = pointer.Bool(replicatedJobSuspended(js, rjob.Name))
 
 	return job, nil
 }
 
+// addPodAnnotations adds the given annotations to the pod template, unless it sets them itself.
+func addPodAnnotations(template *corev1.PodTemplateSpec, podAnnotations map[string]string) {
+	if len(podAnnotations) == 0 {
+		return
+	}
+	annotations := util.CloneMap(template.Annotations)
+	for key, value := range podAnnotations {
+		if _, ok := annotations[key]; !ok {
+			annotations[key] = value
+		}
+	}
+	template.Annotations = annotations
+}
+
+// propagateMetadata copies the labels and annotations of the JobSet selected by its
+// PropagatedLabels and PropagatedAnnotations to the object, keeping its own values.
+func propagateMetadata(obj metav1.Object, js *jobset.JobSet) {
+	if len(js.Spec.PropagatedLabels) > 0 {
+		obj.SetLabels(mergeSelected(obj.GetLabels(), js.Labels, js.Spec.PropagatedLabels))
+	}
+	if len(js.Spec.PropagatedAnnotations) > 0 {
+		obj.SetAnnotations(mergeSelected(obj.GetAnnotations(), js.Annotations, js.Spec.PropagatedAnnotations))
+	}
+}
+
+// mergeSelected returns a copy of dst with the entries of src for the given keys added,
+// unless dst already has them.
+func mergeSelected(dst, src map[string]string, keys []string) map[string]string {
+	merged := util.CloneMap(dst)
+	for _, key := range keys {
+		value, ok := src[key]
+		if _, exists := merged[key]; ok && !exists {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// Adds an emptyDir volume to the pod spec for each shared volume, and mounts it
+// into all init containers and containers of the pod.
+func addSharedVolumes(podSpec *corev1.PodSpec, sharedVolumes []jobset.SharedVolume) {
+	for _, sv := range sharedVolumes {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: sv.Name,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium:    sv.Medium,
+					SizeLimit: sv.SizeLimit,
+				},
+			},
+		})
+		mount := corev1.VolumeMount{Name: sv.Name, MountPath: sv.MountPath}
+		for i := range podSpec.InitContainers {
+			podSpec.InitContainers[i].VolumeMounts = append(podSpec.InitContainers[i].VolumeMounts, mount)
+		}
+		for i := range podSpec.Containers {
+			podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, mount)
+		}
+	}
+}
+
+// Overrides the host namespace settings of the pod spec with those which are set.
+func setHostNamespaces(podSpec *corev1.PodSpec, hostNamespaces *jobset.HostNamespaces) {
+	if hostNamespaces.IPC != nil {
+		podSpec.HostIPC = *hostNamespaces.IPC
+	}
+	if hostNamespaces.PID != nil {
+		podSpec.HostPID = *hostNamespaces.PID
+	}
+	if hostNamespaces.Network != nil {
+		podSpec.HostNetwork = *hostNamespaces.Network
+	}
+}
+
+// setIndexNodeAffinity requires the pods to run on nodes in the topology domain assigned to
+// the job index. The requirement is added to every existing required node selector term, so
+// it narrows the node affinity of the pod template rather than replacing it.
+func setIndexNodeAffinity(podSpec *corev1.PodSpec, affinity *jobset.IndexNodeAffinity, jobIdx int) {
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      affinity.Key,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{affinity.Values[jobIdx%len(affinity.Values)]},
+	}
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	if podSpec.Affinity.NodeAffinity == nil {
+		podSpec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	if podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	selector := podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(selector.NodeSelectorTerms) == 0 {
+		selector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	for i := range selector.NodeSelectorTerms {
+		selector.NodeSelectorTerms[i].MatchExpressions = append(selector.NodeSelectorTerms[i].MatchExpressions, requirement)
+	}
+}
+
 // Appends pod affinity/anti-affinity terms to the job pod template spec,
 // ensuring that exclusively one job runs per topology domain and that all pods
 // from each job land on the same topology domain.
@@ -653,6 +3249,60 @@ func shouldCreateJob(jobName string, ownedJobs *childJobs) bool {
 	return true
 }
 
+// setGenerationLabel labels the object with the current generation of the JobSet spec.
+func setGenerationLabel(obj metav1.Object, js *jobset.JobSet) {
+	labels := util.CloneMap(obj.GetLabels())
+	labels[jobset.JobSetGenerationKey] = strconv.FormatInt(js.Generation, 10)
+	obj.SetLabels(labels)
+}
+
+// setWorkloadTypeLabel labels the object with the workload type of the JobSet.
+func setWorkloadTypeLabel(obj metav1.Object, workloadType jobset.WorkloadType) {
+	labels := util.CloneMap(obj.GetLabels())
+	labels[jobset.WorkloadTypeKey] = string(workloadType)
+	obj.SetLabels(labels)
+}
+
+// replicatedJobNames returns the names of the replicated jobs in the JobSet spec.
+func replicatedJobNames(js *jobset.JobSet) []string {
+	names := make([]string, 0, len(js.Spec.ReplicatedJobs))
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		names = append(names, rjob.Name)
+	}
+	return names
+}
+
+// currentGeneration reports whether the object was created from, or last brought in line with,
+// the current generation of the JobSet spec.
+func currentGeneration(obj metav1.Object, js *jobset.JobSet) bool {
+	return obj.GetLabels()[jobset.JobSetGenerationKey] == strconv.FormatInt(js.Generation, 10)
+}
+
+// relabelObject applies the current reserved labels and annotations to an existing child
+// object, preserving the restart attempt it was created for.
+func relabelObject(obj metav1.Object, js *jobset.JobSet, rjob *jobset.ReplicatedJob, jobIdx int) {
+	restarts, hasRestarts := obj.GetLabels()[RestartsKey]
+	labelAndAnnotateObject(obj, js, rjob, jobIdx)
+	if hasRestarts {
+		labels := obj.GetLabels()
+		labels[RestartsKey] = restarts
+		obj.SetLabels(labels)
+	}
+}
+
+// replicatedJobForJobName returns the replicated job and job index the named child job was created for.
+func replicatedJobForJobName(js *jobset.JobSet, jobName string) (*jobset.ReplicatedJob, int, bool) {
+	for i := range js.Spec.ReplicatedJobs {
+		rjob := &js.Spec.ReplicatedJobs[i]
+		for jobIdx := 0; jobIdx < rjob.Replicas; jobIdx++ {
+			if GenJobName(js, rjob, jobIdx) == jobName {
+				return rjob, jobIdx, true
+			}
+		}
+	}
+	return nil, 0, false
+}
+
 func labelAndAnnotateObject(obj metav1.Object, js *jobset.JobSet, rjob *jobset.ReplicatedJob, jobIdx int) {
 	labels := util.CloneMap(obj.GetLabels())
 	labels[jobset.JobSetNameKey] = js.Name
@@ -660,6 +3310,7 @@ func labelAndAnnotateObject(obj metav1.Object, js *jobset.JobSet, rjob *jobset.R
 	labels[RestartsKey] = strconv.Itoa(js.Status.Restarts)
 	labels[jobset.ReplicatedJobReplicas] = strconv.Itoa(rjob.Replicas)
 	labels[jobset.JobIndexKey] = strconv.Itoa(jobIdx)
+	labels[LabelSchemaVersionKey] = labelSchemaVersion
 
 	annotations := util.CloneMap(obj.GetAnnotations())
 	annotations[jobset.JobSetNameKey] = js.Name
@@ -671,6 +3322,46 @@ func labelAndAnnotateObject(obj metav1.Object, js *jobset.JobSet, rjob *jobset.R
 	obj.SetAnnotations(annotations)
 }
 
+// numPodsFailingImagePull returns the number of pods with at least one container
+// waiting on an image pull failure.
+func numPodsFailingImagePull(pods []corev1.Pod) int {
+	total := 0
+	for _, pod := range pods {
+		for _, status := range util.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+			if status.State.Waiting != nil && util.Contains(imagePullFailureReasons, status.State.Waiting.Reason) {
+				total += 1
+				break
+			}
+		}
+	}
+	return total
+}
+
+// truncateMessage shortens the message to at most maxLength bytes, marking
+// truncated messages with a trailing ellipsis. It never splits a UTF-8 encoded rune.
+func truncateMessage(message string, maxLength int) string {
+	if len(message) <= maxLength {
+		return message
+	}
+	const ellipsis = "..."
+	cut := maxLength - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return message[:cut] + ellipsis
+}
+
+// podsPendingLongerThan returns the pods which have been pending for longer than the threshold.
+func podsPendingLongerThan(pods []corev1.Pod, threshold time.Duration, now time.Time) []corev1.Pod {
+	var pending []corev1.Pod
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodPending && now.Sub(pod.CreationTimestamp.Time) > threshold {
+			pending = append(pending, pod)
+		}
+	}
+	return pending
+}
+
 func jobFinished(job *batchv1.Job) (bool, batchv1.JobConditionType) {
 	for _, c := range job.Status.Conditions {
 		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
@@ -680,12 +3371,60 @@ func jobFinished(job *batchv1.Job) (bool, batchv1.JobConditionType) {
 	return false, ""
 }
 
-func genJobName(js *jobset.JobSet, rjob *jobset.ReplicatedJob, jobIndex int) string {
-	return fmt.Sprintf("%s-%s-%d", js.Name, rjob.Name, jobIndex)
+// unfinishedJobs returns the jobs which have not reached a terminal condition yet.
+func unfinishedJobs(jobs []*batchv1.Job) []*batchv1.Job {
+	var unfinished []*batchv1.Job
+	for _, job := range jobs {
+		if finished, _ := jobFinished(job); !finished {
+			unfinished = append(unfinished, job)
+		}
+	}
+	return unfinished
+}
+
+// failureLevel returns the level at which the failure policy applying to the child jobs of the
+// named replicated job counts failures.
+func failureLevel(js *jobset.JobSet, replicatedJobName string) jobset.FailureLevel {
+	for i := range js.Spec.ReplicatedJobs {
+		if rjob := &js.Spec.ReplicatedJobs[i]; rjob.Name == replicatedJobName && rjob.FailurePolicy != nil && rjob.FailurePolicy.Level != "" {
+			return rjob.FailurePolicy.Level
+		}
+	}
+	if js.Spec.FailurePolicy == nil || js.Spec.FailurePolicy.Level == "" {
+		return jobset.FailureLevelJob
+	}
+	return js.Spec.FailurePolicy.Level
+}
+
+// GenJobName returns the name of the child job with the given index of the replicated job
+// in the current restart attempt of the JobSet. The name only depends on the JobSet spec and
+// its restart attempt, so other controllers can use it to predict the names of the child jobs.
+func GenJobName(js *jobset.JobSet, rjob *jobset.ReplicatedJob, jobIndex int) string {
+	return jobset.JobName(js, rjob, jobIndex, js.Status.Restarts)
 }
 
+// GenSubdomain returns the subdomain of the pods of the replicated job, which is also the
+// name of their headless service. It defaults to the name of the JobSet.
 func GenSubdomain(js *jobset.JobSet, rjob *jobset.ReplicatedJob) string {
-	return fmt.Sprintf("%s-%s", js.Name, rjob.Name)
+	if customSubdomain(rjob) {
+		return rjob.Network.Subdomain
+	}
+	return js.Name
+}
+
+// customSubdomain reports whether the replicated job has a subdomain, and thus a headless
+// service, of its own rather than the one shared by the JobSet.
+func customSubdomain(rjob *jobset.ReplicatedJob) bool {
+	return rjob.Network != nil && rjob.Network.Subdomain != ""
+}
+
+// endpointSliceName returns the name of the EndpointSlice of the replicated job: the name of
+// its headless service if it has its own, or <jobSet.name>-<replicatedJob.name> otherwise.
+func endpointSliceName(js *jobset.JobSet, rjob *jobset.ReplicatedJob) string {
+	if customSubdomain(rjob) {
+		return rjob.Network.Subdomain
+	}
+	return js.Name + jobset.NameSeparator(js) + rjob.Name
 }
 
 func jobSetFinished(js *jobset.JobSet) bool {
@@ -697,10 +3436,102 @@ func jobSetFinished(js *jobset.JobSet) bool {
 	return false
 }
 
+// managedServiceAccount reports whether the JobSet opted in to running its pods under a
+// ServiceAccount managed by the controller.
+func managedServiceAccount(js *jobset.JobSet) bool {
+	return js.Annotations[jobset.ManagedServiceAccountKey] == "true"
+}
+
 func dnsHostnamesEnabled(rjob *jobset.ReplicatedJob) bool {
 	return rjob.Network.EnableDNSHostnames != nil && *rjob.Network.EnableDNSHostnames
 }
 
+func endpointSlicePublished(rjob *jobset.ReplicatedJob) bool {
+	return rjob.Network != nil && pointer.BoolDeref(rjob.Network.PublishEndpointSlice, false)
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func podHostnameAnnotationEnabled(rjob *jobset.ReplicatedJob) bool {
+	return rjob.Network != nil && pointer.BoolDeref(rjob.Network.AnnotatePodHostnames, false)
+}
+
+// coordinatorHostname returns the hostname of the coordinator pod within its subdomain,
+// i.e. of the pod with completion index 0 of the coordinator job.
+func coordinatorHostname(js *jobset.JobSet) string {
+	for i := range js.Spec.ReplicatedJobs {
+		rjob := &js.Spec.ReplicatedJobs[i]
+		if rjob.Name == js.Spec.Coordinator.ReplicatedJob {
+			return fmt.Sprintf("%s-0.%s", GenJobName(js, rjob, js.Spec.Coordinator.JobIndex), GenSubdomain(js, rjob))
+		}
+	}
+	return ""
+}
+
+// invalidCoordinatorReason returns why the coordinator does not identify a pod of the JobSet,
+// or an empty string if it does. The webhook validates the coordinator, but the replicas,
+// completions and parallelism of its replicated job can still change afterwards.
+func invalidCoordinatorReason(js *jobset.JobSet) string {
+	coordinator := js.Spec.Coordinator
+	for i := range js.Spec.ReplicatedJobs {
+		rjob := &js.Spec.ReplicatedJobs[i]
+		if rjob.Name != coordinator.ReplicatedJob {
+			continue
+		}
+		if coordinator.JobIndex >= rjob.Replicas {
+			return fmt.Sprintf("jobIndex %d of the coordinator is out of range for replicatedJob %s with %d replicas", coordinator.JobIndex, rjob.Name, rjob.Replicas)
+		}
+		if completions := replicatedJobCompletions(js, rjob); completions != nil && *completions < 1 {
+			return fmt.Sprintf("replicatedJob %s of the coordinator has no completion index 0 with %d completions", rjob.Name, *completions)
+		}
+		if parallelism := jobParallelism(js, rjob); parallelism < 1 {
+			return fmt.Sprintf("replicatedJob %s of the coordinator runs no pods with parallelism %d", rjob.Name, parallelism)
+		}
+		return ""
+	}
+	return fmt.Sprintf("replicatedJob %s of the coordinator does not exist", coordinator.ReplicatedJob)
+}
+
+// ExpectedPodHostnames returns the hostnames, qualified by their subdomain, of all pods of the
+// JobSet with stable hostnames. These are the pods of replicated jobs with DNS hostnames
+// enabled, one per completion index of each job. The hostnames of pods not selected by the
+// headless service of their replicated job, because its Network.ServiceSelector narrows it,
+// are listed as well, even though they do not resolve.
+func ExpectedPodHostnames(js *jobset.JobSet) []string {
+	var hostnames []string
+	for i := range js.Spec.ReplicatedJobs {
+		rjob := &js.Spec.ReplicatedJobs[i]
+		if rjob.Network == nil || !dnsHostnamesEnabled(rjob) {
+			continue
+		}
+		completions := int(pointer.Int32Deref(replicatedJobCompletions(js, rjob), 1))
+		for jobIdx := 0; jobIdx < rjob.Replicas; jobIdx++ {
+			jobName := GenJobName(js, rjob, jobIdx)
+			for podIdx := 0; podIdx < completions; podIdx++ {
+				hostnames = append(hostnames, fmt.Sprintf("%s-%d.%s", jobName, podIdx, GenSubdomain(js, rjob)))
+			}
+		}
+	}
+	return hostnames
+}
+
+// podHostname returns the hostname of the pod qualified by its subdomain, i.e.
+// <hostname>.<subdomain>, which resolves from pods in the namespace of the JobSet, or an
+// empty string if the pod has no stable hostname.
+func podHostname(pod *corev1.Pod) string {
+	if pod.Spec.Hostname == "" || pod.Spec.Subdomain == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s.%s", pod.Spec.Hostname, pod.Spec.Subdomain)
+}
+
 func jobMatchesSuccessPolicy(js *jobset.JobSet, job *batchv1.Job) bool {
 	return len(js.Spec.SuccessPolicy.TargetReplicatedJobs) == 0 || util.Contains(js.Spec.SuccessPolicy.TargetReplicatedJobs, job.ObjectMeta.Labels[jobset.ReplicatedJobNameKey])
 }
@@ -709,6 +3540,59 @@ func replicatedJobMatchesSuccessPolicy(js *jobset.JobSet, rjob *jobset.Replicate
 	return len(js.Spec.SuccessPolicy.TargetReplicatedJobs) == 0 || util.Contains(js.Spec.SuccessPolicy.TargetReplicatedJobs, rjob.Name)
 }
 
+// calculateCompletionPercentage returns the percentage of the expected completions of all
+// child jobs of the current run which have succeeded.
+func calculateCompletionPercentage(js *jobset.JobSet, jobs *childJobs) int32 {
+	var expected, succeeded int64
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		expected += int64(rjob.Replicas) * int64(pointer.Int32Deref(replicatedJobCompletions(js, &rjob), 1))
+	}
+	if expected == 0 {
+		return 0
+	}
+	for _, job := range jobs.successful {
+		succeeded += int64(jobCompletions(&job.Spec))
+	}
+	for _, job := range util.Concat(jobs.active, jobs.failed) {
+		jobSucceeded := job.Status.Succeeded
+		if completions := jobCompletions(&job.Spec); jobSucceeded > completions {
+			jobSucceeded = completions
+		}
+		succeeded += int64(jobSucceeded)
+	}
+	if succeeded >= expected {
+		return 100
+	}
+	return int32(succeeded * 100 / expected)
+}
+
+// jobCompletions returns the number of successful pods a job needs to complete.
+func jobCompletions(spec *batchv1.JobSpec) int32 {
+	// Without completions set, a job completes once any of its pods succeeds.
+	return pointer.Int32Deref(spec.Completions, 1)
+}
+
+// successPolicySatisfied reports whether the successful jobs satisfy the success policy. With
+// minSucceeded set, it is satisfied once that many jobs of any targeted replicated job succeeded.
+func successPolicySatisfied(js *jobset.JobSet, successful []*batchv1.Job) bool {
+	minSucceeded := js.Spec.SuccessPolicy.MinSucceeded
+	if minSucceeded == nil {
+		return numJobsMatchingSuccessPolicy(js, successful) >= numJobsExpectedToSucceed(js)
+	}
+	succeeded := map[string]int32{}
+	for _, job := range successful {
+		if !jobMatchesSuccessPolicy(js, job) {
+			continue
+		}
+		rjobName := job.Labels[jobset.ReplicatedJobNameKey]
+		succeeded[rjobName]++
+		if succeeded[rjobName] >= *minSucceeded {
+			return true
+		}
+	}
+	return false
+}
+
 func numJobsMatchingSuccessPolicy(js *jobset.JobSet, jobs []*batchv1.Job) int {
 	total := 0
 	for _, job := range jobs {
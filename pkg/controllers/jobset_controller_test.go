@@ -15,18 +15,45 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/go-cmp/cmp/cmpopts"
 
+	"github.com/go-logr/logr/funcr"
 	"github.com/google/go-cmp/cmp"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha1"
+	"sigs.k8s.io/jobset/pkg/notifier"
+	util "sigs.k8s.io/jobset/pkg/util/collections"
 	testutils "sigs.k8s.io/jobset/pkg/util/testing"
 )
 
@@ -280,6 +307,7 @@ func TestConstructJobsFromTemplate(t *testing.T) {
 		jobName           = "test-job"
 		ns                = "default"
 		topologyDomain    = "test-topology-domain"
+		sizeLimit         = resource.MustParse("1Gi")
 	)
 
 	tests := []struct {
@@ -539,7 +567,212 @@ func TestConstructJobsFromTemplate(t *testing.T) {
 					replicas:          1,
 					jobIdx:            0}).
 					Suspend(false).
-					Subdomain("test-jobset-replicated-job").Obj(),
+					Subdomain("test-jobset").Obj(),
+			},
+		},
+		{
+			name: "shared volumes are added to the pod spec and mounted in all containers",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+					Job(testutils.MakeJobTemplate(jobName, ns).
+						PodSpec(corev1.PodSpec{
+							InitContainers: []corev1.Container{{Name: "init"}},
+							Containers:     []corev1.Container{{Name: "first"}, {Name: "second"}},
+						}).Obj()).
+					Replicas(1).
+					SharedVolumes(jobset.SharedVolume{
+						Name:      "dshm",
+						MountPath: "/dev/shm",
+						Medium:    corev1.StorageMediumMemory,
+						SizeLimit: &sizeLimit,
+					}).
+					Obj()).
+				Obj(),
+			ownedJobs: &childJobs{},
+			want: []*batchv1.Job{
+				makeJob(&makeJobArgs{
+					jobSetName:        jobSetName,
+					replicatedJobName: replicatedJobName,
+					jobName:           "test-jobset-replicated-job-0",
+					ns:                ns,
+					replicas:          1,
+					jobIdx:            0}).
+					PodSpec(corev1.PodSpec{
+						InitContainers: []corev1.Container{
+							{Name: "init", VolumeMounts: []corev1.VolumeMount{{Name: "dshm", MountPath: "/dev/shm"}}},
+						},
+						Containers: []corev1.Container{
+							{Name: "first", VolumeMounts: []corev1.VolumeMount{{Name: "dshm", MountPath: "/dev/shm"}}},
+							{Name: "second", VolumeMounts: []corev1.VolumeMount{{Name: "dshm", MountPath: "/dev/shm"}}},
+						},
+						Volumes: []corev1.Volume{
+							{
+								Name: "dshm",
+								VolumeSource: corev1.VolumeSource{
+									EmptyDir: &corev1.EmptyDirVolumeSource{
+										Medium:    corev1.StorageMediumMemory,
+										SizeLimit: &sizeLimit,
+									},
+								},
+							},
+						},
+					}).
+					Suspend(false).Obj(),
+			},
+		},
+		{
+			name: "parallelism override",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				ParallelismOverrides(map[string]int32{replicatedJobName: 4}).
+				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+					Replicas(1).
+					Obj()).
+				ReplicatedJob(testutils.MakeReplicatedJob("not-overridden").
+					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+					Replicas(1).
+					Obj()).
+				Obj(),
+			ownedJobs: &childJobs{},
+			want: []*batchv1.Job{
+				makeJob(&makeJobArgs{
+					jobSetName:        jobSetName,
+					replicatedJobName: replicatedJobName,
+					jobName:           "test-jobset-replicated-job-0",
+					ns:                ns,
+					replicas:          1,
+					jobIdx:            0}).
+					Parallelism(4).
+					Suspend(false).Obj(),
+				makeJob(&makeJobArgs{
+					jobSetName:        jobSetName,
+					replicatedJobName: "not-overridden",
+					jobName:           "test-jobset-not-overridden-0",
+					ns:                ns,
+					replicas:          1,
+					jobIdx:            0}).
+					Suspend(false).Obj(),
+			},
+		},
+		{
+			name: "index node affinity",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+					Replicas(3).
+					IndexNodeAffinity("topology.kubernetes.io/zone", "zone-a", "zone-b").
+					Obj()).
+				Obj(),
+			ownedJobs: &childJobs{},
+			want: []*batchv1.Job{
+				makeJob(&makeJobArgs{
+					jobSetName:        jobSetName,
+					replicatedJobName: replicatedJobName,
+					jobName:           "test-jobset-replicated-job-0",
+					ns:                ns,
+					replicas:          3,
+					jobIdx:            0}).
+					PodSpec(corev1.PodSpec{Affinity: zoneAffinity("zone-a")}).
+					Suspend(false).Obj(),
+				makeJob(&makeJobArgs{
+					jobSetName:        jobSetName,
+					replicatedJobName: replicatedJobName,
+					jobName:           "test-jobset-replicated-job-1",
+					ns:                ns,
+					replicas:          3,
+					jobIdx:            1}).
+					PodSpec(corev1.PodSpec{Affinity: zoneAffinity("zone-b")}).
+					Suspend(false).Obj(),
+				makeJob(&makeJobArgs{
+					jobSetName:        jobSetName,
+					replicatedJobName: replicatedJobName,
+					jobName:           "test-jobset-replicated-job-2",
+					ns:                ns,
+					replicas:          3,
+					jobIdx:            2}).
+					PodSpec(corev1.PodSpec{Affinity: zoneAffinity("zone-a")}).
+					Suspend(false).Obj(),
+			},
+		},
+		{
+			name: "workload type",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				WorkloadType(jobset.WorkloadTypeTraining).
+				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+					Replicas(1).
+					Obj()).
+				Obj(),
+			ownedJobs: &childJobs{},
+			want: []*batchv1.Job{
+				makeJob(&makeJobArgs{
+					jobSetName:        jobSetName,
+					replicatedJobName: replicatedJobName,
+					jobName:           "test-jobset-replicated-job-0",
+					ns:                ns,
+					replicas:          1,
+					jobIdx:            0,
+					workloadType:      jobset.WorkloadTypeTraining}).
+					Suspend(false).Obj(),
+			},
+		},
+		{
+			name: "scheduler name",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				SchedulerName("gang-scheduler").
+				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+					Replicas(1).
+					Obj()).
+				ReplicatedJob(testutils.MakeReplicatedJob("custom-scheduler").
+					Job(testutils.MakeJobTemplate(jobName, ns).
+						PodSpec(corev1.PodSpec{SchedulerName: "custom-scheduler"}).Obj()).
+					Replicas(1).
+					Obj()).
+				Obj(),
+			ownedJobs: &childJobs{},
+			want: []*batchv1.Job{
+				makeJob(&makeJobArgs{
+					jobSetName:        jobSetName,
+					replicatedJobName: replicatedJobName,
+					jobName:           "test-jobset-replicated-job-0",
+					ns:                ns,
+					replicas:          1,
+					jobIdx:            0}).
+					PodSpec(corev1.PodSpec{SchedulerName: "gang-scheduler"}).
+					Suspend(false).Obj(),
+				makeJob(&makeJobArgs{
+					jobSetName:        jobSetName,
+					replicatedJobName: "custom-scheduler",
+					jobName:           "test-jobset-custom-scheduler-0",
+					ns:                ns,
+					replicas:          1,
+					jobIdx:            0}).
+					PodSpec(corev1.PodSpec{SchedulerName: "custom-scheduler"}).
+					Suspend(false).Obj(),
+			},
+		},
+		{
+			name: "host namespaces",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+					Job(testutils.MakeJobTemplate(jobName, ns).
+						PodSpec(corev1.PodSpec{HostPID: true}).Obj()).
+					Replicas(1).
+					HostNamespaces(&jobset.HostNamespaces{IPC: pointer.Bool(true), PID: pointer.Bool(false)}).
+					Obj()).
+				Obj(),
+			ownedJobs: &childJobs{},
+			want: []*batchv1.Job{
+				makeJob(&makeJobArgs{
+					jobSetName:        jobSetName,
+					replicatedJobName: replicatedJobName,
+					jobName:           "test-jobset-replicated-job-0",
+					ns:                ns,
+					replicas:          1,
+					jobIdx:            0}).
+					PodSpec(corev1.PodSpec{HostIPC: true}).
+					Suspend(false).Obj(),
 			},
 		},
 		{
@@ -562,7 +795,7 @@ func TestConstructJobsFromTemplate(t *testing.T) {
 					replicas:          1,
 					jobIdx:            0}).
 					Suspend(true).
-					Subdomain("test-jobset-replicated-job").Obj(),
+					Subdomain("test-jobset").Obj(),
 			},
 		},
 		{
@@ -585,7 +818,7 @@ func TestConstructJobsFromTemplate(t *testing.T) {
 					replicas:          1,
 					jobIdx:            0}).
 					Suspend(false).
-					Subdomain("test-jobset-replicated-job").Obj(),
+					Subdomain("test-jobset").Obj(),
 			},
 		},
 	}
@@ -609,127 +842,512 @@ func TestConstructJobsFromTemplate(t *testing.T) {
 	}
 }
 
-func TestUpdateConditions(t *testing.T) {
+// creationRecordingClient records the names of the jobs created through it.
+type creationRecordingClient struct {
+	client.Client
+	createdJobs []string
+}
+
+func (c *creationRecordingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*batchv1.Job); ok {
+		c.createdJobs = append(c.createdJobs, obj.GetName())
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestCreateJobsInPriorityOrder(t *testing.T) {
 	var (
-		jobSetName        = "test-jobset"
-		replicatedJobName = "replicated-job"
-		jobName           = "test-job"
-		ns                = "default"
+		jobSetName = "test-jobset"
+		ns         = "default"
 	)
-
 	tests := []struct {
-		name           string
-		js             *jobset.JobSet
-		conditions     []metav1.Condition
-		newCondition   metav1.Condition
-		expectedUpdate bool
+		name string
+		js   *jobset.JobSet
+		want []string
 	}{
 		{
-			name: "no condition",
-			js: testutils.MakeJobSet(jobSetName, ns).
-				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
-					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
-					Replicas(1).
-					Obj()).Obj(),
-			newCondition:   metav1.Condition{},
-			conditions:     []metav1.Condition{},
-			expectedUpdate: false,
-		},
-		{
-			name: "do not update if false",
-			js: testutils.MakeJobSet(jobSetName, ns).
-				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
-					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
-					Replicas(1).
-					Obj()).Obj(),
-			newCondition:   metav1.Condition{Status: metav1.ConditionFalse, Type: string(jobset.JobSetSuspended), Reason: "JobsResumed"},
-			conditions:     []metav1.Condition{},
-			expectedUpdate: false,
-		},
-		{
-			name: "update if condition is true",
-			js: testutils.MakeJobSet(jobSetName, ns).
-				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
-					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
-					Replicas(1).
-					Obj()).Obj(),
-			newCondition:   metav1.Condition{Status: metav1.ConditionTrue, Type: string(jobset.JobSetSuspended), Reason: "JobsResumed"},
-			conditions:     []metav1.Condition{},
-			expectedUpdate: true,
-		},
-
-		{
-			name: "suspended",
+			name: "equal priorities follow spec order",
 			js: testutils.MakeJobSet(jobSetName, ns).
-				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
-					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+				ReplicatedJob(testutils.MakeReplicatedJob("workers").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					Replicas(2).
+					Obj()).
+				ReplicatedJob(testutils.MakeReplicatedJob("driver").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
 					Replicas(1).
 					Obj()).Obj(),
-			newCondition:   metav1.Condition{Status: metav1.ConditionTrue, Type: string(jobset.JobSetSuspended), Reason: "JobsSuspended"},
-			conditions:     []metav1.Condition{},
-			expectedUpdate: true,
+			want: []string{"test-jobset-workers-0", "test-jobset-workers-1", "test-jobset-driver-0"},
 		},
 		{
-			name: "resumed",
+			name: "higher priority groups are created first",
 			js: testutils.MakeJobSet(jobSetName, ns).
-				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
-					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+				ReplicatedJob(testutils.MakeReplicatedJob("workers").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					Replicas(2).
+					Obj()).
+				ReplicatedJob(testutils.MakeReplicatedJob("driver").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
 					Replicas(1).
-					Obj()).Obj(),
-			newCondition:   metav1.Condition{Type: string(jobset.JobSetSuspended), Reason: "JobsResumed", Status: metav1.ConditionStatus(corev1.ConditionFalse)},
-			conditions:     []metav1.Condition{{Type: string(jobset.JobSetSuspended), Reason: "JobsSuspended", Status: metav1.ConditionStatus(corev1.ConditionTrue)}},
-			expectedUpdate: true,
-		},
-		{
-			name: "duplicateComplete",
-			js: testutils.MakeJobSet(jobSetName, ns).
-				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
-					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+					CreationPriority(10).
+					Obj()).
+				ReplicatedJob(testutils.MakeReplicatedJob("monitor").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
 					Replicas(1).
+					CreationPriority(-1).
 					Obj()).Obj(),
-			newCondition:   metav1.Condition{Type: string(jobset.JobSetCompleted), Message: "Jobs completed", Reason: "JobsCompleted", Status: metav1.ConditionTrue},
-			conditions:     []metav1.Condition{{Type: string(jobset.JobSetCompleted), Message: "Jobs completed", Reason: "JobsCompleted", Status: metav1.ConditionTrue}},
-			expectedUpdate: false,
+			want: []string{"test-jobset-driver-0", "test-jobset-workers-0", "test-jobset-workers-1", "test-jobset-monitor-0"},
 		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			jsWithConditions := tc.js
-			jsWithConditions.Status.Conditions = tc.conditions
-			gotUpdate := updateCondition(jsWithConditions, tc.newCondition)
-			if gotUpdate != tc.expectedUpdate {
-				t.Errorf("updateCondition return mismatch")
+			scheme := testScheme(t)
+			c := &creationRecordingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.js).Build()}
+			r := JobSetReconciler{Client: c, Scheme: scheme, Record: record.NewFakeRecorder(10)}
+			if err := r.newReconciliation(tc.js).createJobs(context.TODO(), tc.js, &childJobs{}); err != nil {
+				t.Fatalf("createJobs() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, c.createdJobs); diff != "" {
+				t.Errorf("unexpected job creation order (-want +got):\n%s", diff)
 			}
 		})
 	}
 }
 
-func TestCalculateReplicatedJobStatuses(t *testing.T) {
+func TestCreateJobsWithDependencies(t *testing.T) {
 	var (
 		jobSetName = "test-jobset"
 		ns         = "default"
 	)
-	tests := []struct {
-		name     string
-		js       *jobset.JobSet
-		jobs     childJobs
-		expected []jobset.ReplicatedJobStatus
-	}{
-		{
-			name: "partial jobs are ready, no succeeded jobs",
-			js: testutils.MakeJobSet(jobSetName, ns).
-				ReplicatedJob(testutils.MakeReplicatedJob("replicated-job-1").
-					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
-					Replicas(1).
-					Obj()).
-				ReplicatedJob(testutils.MakeReplicatedJob("replicated-job-2").
-					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
-					Replicas(3).
-					Obj()).Obj(),
-			jobs: childJobs{
-				active: []*batchv1.Job{
-					makeJob(&makeJobArgs{
-						jobSetName:        jobSetName,
+	js := testutils.MakeJobSet(jobSetName, ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("coordinator").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(1).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(2).
+			DependsOn("coordinator").
+			Obj()).Obj()
+	scheme := testScheme(t)
+	c := &creationRecordingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(js).Build()}
+	r := JobSetReconciler{Client: c, Scheme: scheme, Record: record.NewFakeRecorder(10)}
+
+	// Only the coordinator is created while it is not ready.
+	if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"test-jobset-coordinator-0"}, c.createdJobs); diff != "" {
+		t.Errorf("unexpected jobs created (-want +got):\n%s", diff)
+	}
+	cond := meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetWaitingForDependencies))
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Fatalf("expected condition %s to be true, got %v", jobset.JobSetWaitingForDependencies, cond)
+	}
+	if want := "workers waiting on coordinator (Ready: 0/1)"; cond.Message != want {
+		t.Errorf("unexpected condition message %q, want %q", cond.Message, want)
+	}
+
+	// The workers are created once the coordinator is ready.
+	coordinator := makeJob(&makeJobArgs{
+		jobSetName:        jobSetName,
+		replicatedJobName: "coordinator",
+		jobName:           "test-jobset-coordinator-0",
+		ns:                ns,
+		replicas:          1,
+	}).Ready(1).Obj()
+	c.createdJobs = nil
+	if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{active: []*batchv1.Job{coordinator}}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"test-jobset-workers-0", "test-jobset-workers-1"}, c.createdJobs); diff != "" {
+		t.Errorf("unexpected jobs created (-want +got):\n%s", diff)
+	}
+	cond = meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetWaitingForDependencies))
+	if cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Errorf("expected condition %s to be false, got %v", jobset.JobSetWaitingForDependencies, cond)
+	}
+}
+
+func TestAnnotateResourceVersion(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	for _, annotate := range []bool{true, false} {
+		t.Run(fmt.Sprintf("annotate %t", annotate), func(t *testing.T) {
+			scheme := testScheme(t)
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(testutils.MakeJobSet(jobSetName, ns).
+				ReplicatedJob(testutils.MakeReplicatedJob("workers").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					Replicas(2).
+					Obj()).Obj()).Build()
+			r := JobSetReconciler{Client: c, Scheme: scheme, Record: record.NewFakeRecorder(10), AnnotateResourceVersion: annotate}
+			// Get the JobSet back from the client, which sets its resourceVersion.
+			var js jobset.JobSet
+			if err := c.Get(context.TODO(), types.NamespacedName{Name: jobSetName, Namespace: ns}, &js); err != nil {
+				t.Fatalf("getting jobset: %v", err)
+			}
+			// Recording the start time updates the status, and thereby the resourceVersion.
+			resourceVersion := js.ResourceVersion
+
+			if err := r.newReconciliation(&js).createJobs(context.TODO(), &js, &childJobs{}); err != nil {
+				t.Fatalf("createJobs() error = %v", err)
+			}
+			var jobs batchv1.JobList
+			if err := c.List(context.TODO(), &jobs, client.InNamespace(ns)); err != nil {
+				t.Fatalf("listing jobs: %v", err)
+			}
+			if len(jobs.Items) != 2 {
+				t.Fatalf("got %d jobs, want 2", len(jobs.Items))
+			}
+			for _, job := range jobs.Items {
+				got, ok := job.Annotations[JobSetResourceVersionKey]
+				if ok != annotate {
+					t.Errorf("job %s has annotation %s: %t, want %t", job.Name, JobSetResourceVersionKey, ok, annotate)
+				}
+				if annotate && got != resourceVersion {
+					t.Errorf("job %s annotated with resourceVersion %q, want %q", job.Name, got, resourceVersion)
+				}
+			}
+		})
+	}
+}
+
+func TestManagedServiceAccount(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	js := testutils.MakeJobSet(jobSetName, ns).
+		SetAnnotations(map[string]string{jobset.ManagedServiceAccountKey: "true"}).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(2).
+			Obj()).Obj()
+	js.UID = "uid"
+	scheme := testScheme(t)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(js).Build()
+	r := JobSetReconciler{Client: c, Scheme: scheme, Record: record.NewFakeRecorder(10)}
+
+	if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+	// Later reconciles find the existing ServiceAccount.
+	if owned, err := r.createServiceAccountIfNotExist(context.TODO(), js); err != nil || !owned {
+		t.Fatalf("createServiceAccountIfNotExist() = %t, %v, want true, nil", owned, err)
+	}
+
+	var serviceAccount corev1.ServiceAccount
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: jobSetName, Namespace: ns}, &serviceAccount); err != nil {
+		t.Fatalf("getting service account: %v", err)
+	}
+	// The ServiceAccount is owned by the JobSet, so it is garbage collected along with it.
+	if !metav1.IsControlledBy(&serviceAccount, js) {
+		t.Errorf("expected service account to be controlled by the jobset, got owner references %v", serviceAccount.OwnerReferences)
+	}
+
+	var jobs batchv1.JobList
+	if err := c.List(context.TODO(), &jobs, client.InNamespace(ns)); err != nil {
+		t.Fatalf("listing jobs: %v", err)
+	}
+	if len(jobs.Items) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs.Items))
+	}
+	for _, job := range jobs.Items {
+		if got := job.Spec.Template.Spec.ServiceAccountName; got != jobSetName {
+			t.Errorf("job %s: expected service account %q, got %q", job.Name, jobSetName, got)
+		}
+	}
+}
+
+func TestManagedServiceAccountOfAnotherOwner(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		SetAnnotations(map[string]string{jobset.ManagedServiceAccountKey: "true"}).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(2).
+			Obj()).Obj()
+	js.UID = "uid"
+	// A ServiceAccount named after the JobSet which it does not own.
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test-jobset", Namespace: ns}}
+	scheme := testScheme(t)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(js, serviceAccount).Build()
+	r := JobSetReconciler{Client: c, Scheme: scheme, Record: record.NewFakeRecorder(10)}
+
+	if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+	var jobs batchv1.JobList
+	if err := c.List(context.TODO(), &jobs, client.InNamespace(ns)); err != nil {
+		t.Fatalf("listing jobs: %v", err)
+	}
+	if len(jobs.Items) != 0 {
+		t.Errorf("expected no jobs to run under the foreign service account, got %d", len(jobs.Items))
+	}
+	if !meta.IsStatusConditionTrue(js.Status.Conditions, string(jobset.JobSetServiceAccountConflict)) {
+		t.Errorf("expected condition %s to be true, got conditions %v", jobset.JobSetServiceAccountConflict, js.Status.Conditions)
+	}
+
+	// Once the ServiceAccount is gone, the jobs are created and the condition is cleared.
+	if err := c.Delete(context.TODO(), serviceAccount); err != nil {
+		t.Fatalf("deleting service account: %v", err)
+	}
+	if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+	if err := c.List(context.TODO(), &jobs, client.InNamespace(ns)); err != nil {
+		t.Fatalf("listing jobs: %v", err)
+	}
+	if len(jobs.Items) != 2 {
+		t.Errorf("expected 2 jobs, got %d", len(jobs.Items))
+	}
+	if !meta.IsStatusConditionFalse(js.Status.Conditions, string(jobset.JobSetServiceAccountConflict)) {
+		t.Errorf("expected condition %s to be false, got conditions %v", jobset.JobSetServiceAccountConflict, js.Status.Conditions)
+	}
+}
+
+func TestManagedServiceAccountNonControllingOwnerReferences(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		SetAnnotations(map[string]string{jobset.ManagedServiceAccountKey: "true"}).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(2).
+			Obj()).Obj()
+	js.UID = "uid"
+	scheme := testScheme(t)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(js).Build()
+	r := JobSetReconciler{Client: c, Scheme: scheme, Record: record.NewFakeRecorder(10), NonControllingOwnerReferences: true}
+
+	if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+	var job batchv1.Job
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "test-jobset-workers-1", Namespace: ns}, &job); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if err := c.Delete(context.TODO(), &job); err != nil {
+		t.Fatalf("deleting job: %v", err)
+	}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "test-jobset-workers-0", Namespace: ns}, &job); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+
+	// The second reconcile finds the ServiceAccount created by the first one, which the
+	// JobSet owns without being its controller, and recreates the deleted job.
+	if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{active: []*batchv1.Job{&job}}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+	if meta.IsStatusConditionTrue(js.Status.Conditions, string(jobset.JobSetServiceAccountConflict)) {
+		t.Errorf("expected condition %s not to be true, got conditions %v", jobset.JobSetServiceAccountConflict, js.Status.Conditions)
+	}
+	var jobs batchv1.JobList
+	if err := c.List(context.TODO(), &jobs, client.InNamespace(ns)); err != nil {
+		t.Fatalf("listing jobs: %v", err)
+	}
+	if len(jobs.Items) != 2 {
+		t.Errorf("expected 2 jobs, got %d", len(jobs.Items))
+	}
+}
+
+// podFailurePolicyDroppingClient creates jobs like an API server with the JobPodFailurePolicy
+// feature gate disabled, which drops the pod failure policy of the jobs.
+type podFailurePolicyDroppingClient struct {
+	client.Client
+}
+
+func (c *podFailurePolicyDroppingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if job, ok := obj.(*batchv1.Job); ok {
+		job.Spec.PodFailurePolicy = nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestUnsupportedJobFeaturesCondition(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	podFailurePolicy := &batchv1.PodFailurePolicy{
+		Rules: []batchv1.PodFailurePolicyRule{{
+			Action:          batchv1.PodFailurePolicyActionIgnore,
+			OnPodConditions: []batchv1.PodFailurePolicyOnPodConditionsPattern{{Type: corev1.DisruptionTarget}},
+		}},
+	}
+	workers := testutils.MakeJobTemplate("test-job", ns).Obj()
+	workers.Spec.PodFailurePolicy = podFailurePolicy
+	js := testutils.MakeJobSet(jobSetName, ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(workers).
+			Replicas(2).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("driver").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(1).
+			Obj()).Obj()
+	scheme := testScheme(t)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(js).Build()
+
+	// The condition is set when the cluster drops the pod failure policy of the workers.
+	r := JobSetReconciler{Client: &podFailurePolicyDroppingClient{Client: c}, Scheme: scheme, Record: record.NewFakeRecorder(10)}
+	if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+	cond := meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetUnsupportedJobFeatures))
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Fatalf("expected condition %s to be true, got %v", jobset.JobSetUnsupportedJobFeatures, cond)
+	}
+	if want := "replicatedJob 'workers' uses podFailurePolicy, which the cluster does not support and dropped from the created jobs"; cond.Message != want {
+		t.Errorf("unexpected condition message %q, want %q", cond.Message, want)
+	}
+
+	// The condition is cleared once the jobs are recreated by a cluster supporting the feature.
+	if err := c.DeleteAllOf(context.TODO(), &batchv1.Job{}, client.InNamespace(ns)); err != nil {
+		t.Fatalf("deleting jobs: %v", err)
+	}
+	r.Client = c
+	if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+	cond = meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetUnsupportedJobFeatures))
+	if cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Errorf("expected condition %s to be false, got %v", jobset.JobSetUnsupportedJobFeatures, cond)
+	}
+}
+
+func TestUpdateConditions(t *testing.T) {
+	var (
+		jobSetName        = "test-jobset"
+		replicatedJobName = "replicated-job"
+		jobName           = "test-job"
+		ns                = "default"
+	)
+
+	tests := []struct {
+		name           string
+		js             *jobset.JobSet
+		conditions     []metav1.Condition
+		newCondition   metav1.Condition
+		expectedUpdate bool
+	}{
+		{
+			name: "no condition",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+					Replicas(1).
+					Obj()).Obj(),
+			newCondition:   metav1.Condition{},
+			conditions:     []metav1.Condition{},
+			expectedUpdate: false,
+		},
+		{
+			name: "do not update if false",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+					Replicas(1).
+					Obj()).Obj(),
+			newCondition:   metav1.Condition{Status: metav1.ConditionFalse, Type: string(jobset.JobSetSuspended), Reason: "JobsResumed"},
+			conditions:     []metav1.Condition{},
+			expectedUpdate: false,
+		},
+		{
+			name: "update if condition is true",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+					Replicas(1).
+					Obj()).Obj(),
+			newCondition:   metav1.Condition{Status: metav1.ConditionTrue, Type: string(jobset.JobSetSuspended), Reason: "JobsResumed"},
+			conditions:     []metav1.Condition{},
+			expectedUpdate: true,
+		},
+
+		{
+			name: "suspended",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+					Replicas(1).
+					Obj()).Obj(),
+			newCondition:   metav1.Condition{Status: metav1.ConditionTrue, Type: string(jobset.JobSetSuspended), Reason: "JobsSuspended"},
+			conditions:     []metav1.Condition{},
+			expectedUpdate: true,
+		},
+		{
+			name: "resumed",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+					Replicas(1).
+					Obj()).Obj(),
+			newCondition:   metav1.Condition{Type: string(jobset.JobSetSuspended), Reason: "JobsResumed", Status: metav1.ConditionStatus(corev1.ConditionFalse)},
+			conditions:     []metav1.Condition{{Type: string(jobset.JobSetSuspended), Reason: "JobsSuspended", Status: metav1.ConditionStatus(corev1.ConditionTrue)}},
+			expectedUpdate: true,
+		},
+		{
+			name: "duplicateComplete",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+					Replicas(1).
+					Obj()).Obj(),
+			newCondition:   metav1.Condition{Type: string(jobset.JobSetCompleted), Message: "Jobs completed", Reason: "JobsCompleted", Status: metav1.ConditionTrue},
+			conditions:     []metav1.Condition{{Type: string(jobset.JobSetCompleted), Message: "Jobs completed", Reason: "JobsCompleted", Status: metav1.ConditionTrue}},
+			expectedUpdate: false,
+		},
+		{
+			name: "message changed",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+					Job(testutils.MakeJobTemplate(jobName, ns).Obj()).
+					Replicas(1).
+					Obj()).Obj(),
+			newCondition:   metav1.Condition{Type: string(jobset.JobSetWaitingForDependencies), Message: "workers waiting on coordinator (Ready: 3/4)", Reason: "DependenciesNotReady", Status: metav1.ConditionTrue},
+			conditions:     []metav1.Condition{{Type: string(jobset.JobSetWaitingForDependencies), Message: "workers waiting on coordinator (Ready: 2/4)", Reason: "DependenciesNotReady", Status: metav1.ConditionTrue}},
+			expectedUpdate: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jsWithConditions := tc.js
+			jsWithConditions.Status.Conditions = tc.conditions
+			gotUpdate := updateCondition(jsWithConditions, tc.newCondition)
+			if gotUpdate != tc.expectedUpdate {
+				t.Errorf("updateCondition return mismatch")
+			}
+		})
+	}
+}
+
+func TestCalculateReplicatedJobStatuses(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	tests := []struct {
+		name     string
+		js       *jobset.JobSet
+		jobs     childJobs
+		expected []jobset.ReplicatedJobStatus
+	}{
+		{
+			name: "partial jobs are ready, no succeeded jobs",
+			js: testutils.MakeJobSet(jobSetName, ns).
+				ReplicatedJob(testutils.MakeReplicatedJob("replicated-job-1").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					Replicas(1).
+					Obj()).
+				ReplicatedJob(testutils.MakeReplicatedJob("replicated-job-2").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					Replicas(3).
+					Obj()).Obj(),
+			jobs: childJobs{
+				active: []*batchv1.Job{
+					makeJob(&makeJobArgs{
+						jobSetName:        jobSetName,
 						replicatedJobName: "replicated-job-1",
 						jobName:           "test-jobset-replicated-job-1-test-job-0",
 						ns:                ns,
@@ -954,37 +1572,4292 @@ func TestCalculateReplicatedJobStatuses(t *testing.T) {
 	}
 }
 
-type makeJobArgs struct {
-	jobSetName        string
-	replicatedJobName string
-	jobName           string
-	ns                string
-	replicas          int
-	jobIdx            int
-	restarts          int
+func TestReadyCondition(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	js := testutils.MakeJobSet(jobSetName, ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("driver").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(1).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(2).
+			Obj()).Obj()
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	job := func(rjobName string, jobIdx int, ready int32) *batchv1.Job {
+		return makeJob(&makeJobArgs{
+			jobSetName:        jobSetName,
+			replicatedJobName: rjobName,
+			jobName:           fmt.Sprintf("%s-%s-%d", jobSetName, rjobName, jobIdx),
+			ns:                ns,
+			replicas:          2,
+			jobIdx:            jobIdx,
+		}).Ready(ready).Obj()
+	}
+
+	for _, tc := range []struct {
+		jobs       []*batchv1.Job
+		wantReady  int32
+		wantStatus metav1.ConditionStatus
+	}{
+		// Not all workers are ready yet, so the condition is not added.
+		{jobs: []*batchv1.Job{job("driver", 0, 1), job("workers", 0, 1), job("workers", 1, 0)}, wantReady: 2},
+		{jobs: []*batchv1.Job{job("driver", 0, 1), job("workers", 0, 1), job("workers", 1, 1)}, wantReady: 3, wantStatus: metav1.ConditionTrue},
+		{jobs: []*batchv1.Job{job("driver", 0, 0), job("workers", 0, 1), job("workers", 1, 1)}, wantReady: 2, wantStatus: metav1.ConditionFalse},
+	} {
+		resourceVersion, _ := strconv.Atoi(js.ResourceVersion)
+		if err := r.newReconciliation(js).calculateAndUpdateReplicatedJobsStatuses(context.TODO(), js, &childJobs{active: tc.jobs}); err != nil {
+			t.Fatalf("calculateAndUpdateReplicatedJobsStatuses() error = %v", err)
+		}
+		// The counts and the condition are written in a single status update.
+		if got, _ := strconv.Atoi(js.ResourceVersion); got-resourceVersion != 1 {
+			t.Errorf("got %d status updates, want 1", got-resourceVersion)
+		}
+		if js.Status.Ready != tc.wantReady {
+			t.Errorf("got %d ready jobs, want %d", js.Status.Ready, tc.wantReady)
+		}
+		cond := meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetReady))
+		if tc.wantStatus == "" {
+			if cond != nil {
+				t.Errorf("expected no condition %s, got %v", jobset.JobSetReady, cond)
+			}
+		} else if cond == nil || cond.Status != tc.wantStatus {
+			t.Errorf("expected condition %s to be %s, got %v", jobset.JobSetReady, tc.wantStatus, cond)
+		}
+	}
 }
 
-func makeJob(args *makeJobArgs) *testutils.JobWrapper {
-	jobWrapper := testutils.MakeJob(args.jobName, args.ns).
-		JobLabels(map[string]string{
-			jobset.JobSetNameKey:         args.jobSetName,
-			jobset.ReplicatedJobNameKey:  args.replicatedJobName,
-			jobset.ReplicatedJobReplicas: strconv.Itoa(args.replicas),
-			jobset.JobIndexKey:           strconv.Itoa(args.jobIdx),
-			RestartsKey:                  strconv.Itoa(args.restarts),
-		}).
-		JobAnnotations(map[string]string{
-			jobset.JobSetNameKey:         args.jobSetName,
-			jobset.ReplicatedJobNameKey:  args.replicatedJobName,
-			jobset.ReplicatedJobReplicas: strconv.Itoa(args.replicas),
-			jobset.JobIndexKey:           strconv.Itoa(args.jobIdx),
-		}).
-		PodLabels(map[string]string{
-			jobset.JobSetNameKey:         args.jobSetName,
-			jobset.ReplicatedJobNameKey:  args.replicatedJobName,
-			jobset.ReplicatedJobReplicas: strconv.Itoa(args.replicas),
+func TestReadyConditionWithoutReplicas(t *testing.T) {
+	js := testutils.MakeJobSet("test-jobset", "default").
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", "default").Obj()).
+			Replicas(0).
+			Obj()).Obj()
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	if err := r.newReconciliation(js).calculateAndUpdateReplicatedJobsStatuses(context.TODO(), js, &childJobs{}); err != nil {
+		t.Fatalf("calculateAndUpdateReplicatedJobsStatuses() error = %v", err)
+	}
+	// A JobSet running no pods is not ready.
+	if meta.IsStatusConditionTrue(js.Status.Conditions, string(jobset.JobSetReady)) {
+		t.Errorf("expected condition %s not to be true, got %v", jobset.JobSetReady, js.Status.Conditions)
+	}
+}
+
+func TestExecuteSuccessPolicy(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	testCases := []struct {
+		name          string
+		operator      jobset.Operator
+		completeNow   bool
+		wantCompleted bool
+		wantReason    string
+	}{
+		{
+			name:          "all jobs succeeded",
+			operator:      jobset.OperatorAll,
+			wantCompleted: true,
+			wantReason:    "AllJobsCompleted",
+		},
+		{
+			name:     "never operator stays running after all jobs succeeded",
+			operator: jobset.OperatorNever,
+		},
+		{
+			name:          "never operator completed on request",
+			operator:      jobset.OperatorNever,
+			completeNow:   true,
+			wantCompleted: true,
+			wantReason:    "CompletionRequested",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			js := testutils.MakeJobSet(jobSetName, ns).
+				SuccessPolicy(&jobset.SuccessPolicy{Operator: tc.operator}).
+				ReplicatedJob(testutils.MakeReplicatedJob("workers").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					Replicas(1).
+					Obj()).Obj()
+			if tc.completeNow {
+				js.Annotations = map[string]string{jobset.CompleteNowKey: "true"}
+			}
+			r := JobSetReconciler{
+				Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build(),
+				Record: record.NewFakeRecorder(10),
+			}
+			job := makeJob(&makeJobArgs{
+				jobSetName:        jobSetName,
+				replicatedJobName: "workers",
+				jobName:           "test-jobset-workers-0",
+				ns:                ns,
+				replicas:          1,
+				jobIdx:            0,
+			}).Obj()
+			completed, err := r.newReconciliation(js).executeSuccessPolicy(context.TODO(), js, &childJobs{successful: []*batchv1.Job{job}})
+			if err != nil {
+				t.Fatalf("executeSuccessPolicy() error = %v", err)
+			}
+			if completed != tc.wantCompleted {
+				t.Errorf("got completed %t, want %t", completed, tc.wantCompleted)
+			}
+			cond := meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetCompleted))
+			if !tc.wantCompleted {
+				if cond != nil {
+					t.Errorf("expected no condition %s, got %v", jobset.JobSetCompleted, cond)
+				}
+				return
+			}
+			if cond == nil || cond.Reason != tc.wantReason {
+				t.Errorf("expected condition %s with reason %s, got %v", jobset.JobSetCompleted, tc.wantReason, cond)
+			}
+		})
+	}
+}
+
+func TestSuccessPolicyMinSucceeded(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	js := testutils.MakeJobSet(jobSetName, ns).
+		SuccessPolicy(&jobset.SuccessPolicy{Operator: jobset.OperatorAny, TargetReplicatedJobs: []string{"workers"}, MinSucceeded: pointer.Int32(2)}).
+		ReplicatedJob(testutils.MakeReplicatedJob("driver").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(2).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(4).
+			Obj()).Obj()
+	job := func(rjobName string, idx int) *batchv1.Job {
+		return makeJob(&makeJobArgs{
+			jobSetName:        jobSetName,
+			replicatedJobName: rjobName,
+			jobName:           fmt.Sprintf("%s-%s-%d", jobSetName, rjobName, idx),
+			ns:                ns,
+			replicas:          1,
+			jobIdx:            idx,
+		}).Obj()
+	}
+	testCases := []struct {
+		name       string
+		successful []*batchv1.Job
+		want       bool
+	}{
+		{
+			name:       "fewer workers succeeded",
+			successful: []*batchv1.Job{job("workers", 0)},
+		},
+		{
+			name:       "jobs of untargeted replicated job do not count",
+			successful: []*batchv1.Job{job("driver", 0), job("driver", 1), job("workers", 0)},
+		},
+		{
+			name:       "enough workers succeeded",
+			successful: []*batchv1.Job{job("workers", 0), job("workers", 3)},
+			want:       true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := successPolicySatisfied(js, tc.successful); got != tc.want {
+				t.Errorf("got success policy satisfied %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRequestedResources(t *testing.T) {
+	ns := "default"
+	gpu := corev1.ResourceName("nvidia.com/gpu")
+	container := func(requests corev1.ResourceList) corev1.Container {
+		return corev1.Container{Name: "c", Resources: corev1.ResourceRequirements{Requests: requests}}
+	}
+	js := testutils.MakeJobSet("test-jobset", ns).
+		ParallelismOverrides(map[string]int32{"workers": 3}).
+		// The init container requests more CPU than the containers, so it determines the pod CPU request.
+		ReplicatedJob(testutils.MakeReplicatedJob("driver").
+			Job(testutils.MakeJobTemplate("test-job", ns).
+				PodSpec(corev1.PodSpec{
+					InitContainers: []corev1.Container{container(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")})},
+					Containers: []corev1.Container{
+						container(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("512Mi")}),
+						container(corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")}),
+					},
+				}).Obj()).
+			Replicas(1).
+			Obj()).
+		// 2 jobs with 3 pods each, as the parallelism is overridden.
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).
+				Parallelism(4).
+				PodSpec(corev1.PodSpec{
+					Containers: []corev1.Container{container(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), gpu: resource.MustParse("1")})},
+				}).Obj()).
+			Replicas(2).
+			Obj()).Obj()
+
+	want := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("8"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+		gpu:                   resource.MustParse("6"),
+	}
+	got := requestedResources(js)
+	if len(got) != len(want) {
+		t.Fatalf("got requests %v, want %v", got, want)
+	}
+	for name, quantity := range want {
+		if gotQuantity := got[name]; gotQuantity.Cmp(quantity) != 0 {
+			t.Errorf("%s: got %s, want %s", name, gotQuantity.String(), quantity.String())
+		}
+	}
+
+	// The aggregate is reported in the JobSet status.
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	if err := r.newReconciliation(js).calculateAndUpdateReplicatedJobsStatuses(context.TODO(), js, &childJobs{}); err != nil {
+		t.Fatalf("calculateAndUpdateReplicatedJobsStatuses() error = %v", err)
+	}
+	if !apiequality.Semantic.DeepEqual(js.Status.TotalRequests, want) {
+		t.Errorf("got status total requests %v, want %v", js.Status.TotalRequests, want)
+	}
+}
+
+func TestMultiplyQuantity(t *testing.T) {
+	tests := []struct {
+		quantity string
+		n        int64
+		want     string
+	}{
+		{quantity: "500m", n: 3, want: "1500m"},
+		{quantity: "512Mi", n: 0, want: "0"},
+		// The milli value of the product overflows an int64.
+		{quantity: "1Ti", n: 50000, want: "50000Ti"},
+	}
+	for _, tc := range tests {
+		got := multiplyQuantity(resource.MustParse(tc.quantity), tc.n)
+		if want := resource.MustParse(tc.want); got.Cmp(want) != 0 {
+			t.Errorf("%s * %d: got %s, want %s", tc.quantity, tc.n, got.String(), want.String())
+		}
+	}
+}
+
+func TestUnsatisfiableSuccessPolicyCondition(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	makeJobSet := func(coordinatorReplicas int) *jobset.JobSet {
+		return testutils.MakeJobSet(jobSetName, ns).
+			SuccessPolicy(&jobset.SuccessPolicy{Operator: jobset.OperatorAll, TargetReplicatedJobs: []string{"workers"}}).
+			ReplicatedJob(testutils.MakeReplicatedJob("coordinator").
+				Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+				Replicas(coordinatorReplicas).
+				Obj()).
+			ReplicatedJob(testutils.MakeReplicatedJob("workers").
+				Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+				Replicas(2).
+				DependsOn("coordinator").
+				Obj()).Obj()
+	}
+	job := func(rjobName string, idx int) *testutils.JobWrapper {
+		return makeJob(&makeJobArgs{
+			jobSetName:        jobSetName,
+			replicatedJobName: rjobName,
+			jobName:           fmt.Sprintf("%s-%s-%d", jobSetName, rjobName, idx),
+			ns:                ns,
+			replicas:          1,
+		})
+	}
+	tests := []struct {
+		name          string
+		js            *jobset.JobSet
+		ownedJobs     *childJobs
+		wantCondition bool
+	}{
+		{
+			name: "targets blocked after all other jobs completed",
+			js:   makeJobSet(2),
+			ownedJobs: &childJobs{
+				successful: []*batchv1.Job{job("coordinator", 0).Obj()},
+			},
+			wantCondition: true,
+		},
+		{
+			name: "jobs still active",
+			js:   makeJobSet(2),
+			ownedJobs: &childJobs{
+				active:     []*batchv1.Job{job("coordinator", 1).Obj()},
+				successful: []*batchv1.Job{job("coordinator", 0).Obj()},
+			},
+		},
+		{
+			name: "targets can still be created",
+			js:   makeJobSet(1),
+			ownedJobs: &childJobs{
+				successful: []*batchv1.Job{job("coordinator", 0).Obj()},
+			},
+		},
+		{
+			name: "success policy satisfied",
+			js:   makeJobSet(1),
+			ownedJobs: &childJobs{
+				successful: []*batchv1.Job{job("coordinator", 0).Obj(), job("workers", 0).Obj(), job("workers", 1).Obj()},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := JobSetReconciler{
+				Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(tc.js).Build(),
+				Record: record.NewFakeRecorder(10),
+			}
+			if err := r.newReconciliation(tc.js).updateUnsatisfiableSuccessPolicyCondition(context.TODO(), tc.js, tc.ownedJobs); err != nil {
+				t.Fatalf("updateUnsatisfiableSuccessPolicyCondition() error = %v", err)
+			}
+			got := meta.IsStatusConditionTrue(tc.js.Status.Conditions, string(jobset.JobSetUnsatisfiableSuccessPolicy))
+			if got != tc.wantCondition {
+				t.Errorf("got condition %v, want %v", got, tc.wantCondition)
+			}
+		})
+	}
+}
+
+func TestUpdateCompletionsExceedParallelismCondition(t *testing.T) {
+	ns := "default"
+	makeRJob := func(name string, dns bool, completions, parallelism int32) jobset.ReplicatedJob {
+		return testutils.MakeReplicatedJob(name).
+			Job(testutils.MakeJobTemplate("test-job", ns).
+				Completions(completions).
+				Parallelism(parallelism).Obj()).
+			EnableDNSHostnames(dns).
+			Replicas(1).
+			Obj()
+	}
+	tests := []struct {
+		name          string
+		js            *jobset.JobSet
+		wantCondition bool
+	}{
+		{
+			name: "completions equal parallelism",
+			js: testutils.MakeJobSet("test-jobset", ns).
+				ReplicatedJob(makeRJob("workers", true, 4, 4)).Obj(),
+		},
+		{
+			name: "completions exceed parallelism with DNS hostnames",
+			js: testutils.MakeJobSet("test-jobset", ns).
+				ReplicatedJob(makeRJob("workers", true, 8, 4)).Obj(),
+			wantCondition: true,
+		},
+		{
+			name: "completions exceed parallelism without DNS hostnames",
+			js: testutils.MakeJobSet("test-jobset", ns).
+				ReplicatedJob(makeRJob("workers", false, 8, 4)).Obj(),
+		},
+		{
+			name: "parallelism override covers completions",
+			js: testutils.MakeJobSet("test-jobset", ns).
+				ParallelismOverrides(map[string]int32{"workers": 8}).
+				ReplicatedJob(makeRJob("workers", true, 8, 4)).Obj(),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := JobSetReconciler{
+				Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(tc.js).Build(),
+				Record: record.NewFakeRecorder(10),
+			}
+			if err := r.newReconciliation(tc.js).updateCompletionsExceedParallelismCondition(context.TODO(), tc.js); err != nil {
+				t.Fatalf("updateCompletionsExceedParallelismCondition() error = %v", err)
+			}
+			got := meta.IsStatusConditionTrue(tc.js.Status.Conditions, string(jobset.JobSetCompletionsExceedParallelism))
+			if got != tc.wantCondition {
+				t.Errorf("got condition %v, want %v", got, tc.wantCondition)
+			}
+		})
+	}
+}
+
+func TestCalculateCompletionPercentage(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	// 2 jobs with 4 completions each, and 2 jobs without completions.
+	js := testutils.MakeJobSet(jobSetName, ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("indexed").
+			Job(testutils.MakeJobTemplate("test-job", ns).Completions(4).Obj()).
+			Replicas(2).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("single").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(2).
+			Obj()).Obj()
+	childJob := func(rjobName string, jobIdx int) *testutils.JobWrapper {
+		job := makeJob(&makeJobArgs{
+			jobSetName:        jobSetName,
+			replicatedJobName: rjobName,
+			jobName:           fmt.Sprintf("%s-%s-%d", jobSetName, rjobName, jobIdx),
+			ns:                ns,
+			replicas:          2,
+			jobIdx:            jobIdx,
+		})
+		if rjobName == "indexed" {
+			job = job.Completions(4)
+		}
+		return job
+	}
+
+	tests := []struct {
+		name string
+		jobs childJobs
+		want int32
+	}{
+		{
+			name: "no jobs created",
+			want: 0,
+		},
+		{
+			name: "no pods succeeded",
+			jobs: childJobs{
+				active: []*batchv1.Job{
+					childJob("indexed", 0).Obj(),
+					childJob("indexed", 1).Obj(),
+				},
+			},
+			want: 0,
+		},
+		{
+			name: "partial completions of active jobs",
+			jobs: childJobs{
+				active: []*batchv1.Job{
+					childJob("indexed", 0).Succeeded(2).Obj(),
+					childJob("indexed", 1).Succeeded(1).Obj(),
+					childJob("single", 0).Obj(),
+				},
+			},
+			want: 30,
+		},
+		{
+			name: "successful jobs count all of their completions",
+			jobs: childJobs{
+				active: []*batchv1.Job{
+					childJob("indexed", 1).Succeeded(3).Obj(),
+				},
+				successful: []*batchv1.Job{
+					childJob("indexed", 0).Obj(),
+					childJob("single", 0).Obj(),
+					childJob("single", 1).Obj(),
+				},
+			},
+			want: 90,
+		},
+		{
+			name: "all jobs successful",
+			jobs: childJobs{
+				successful: []*batchv1.Job{
+					childJob("indexed", 0).Obj(),
+					childJob("indexed", 1).Obj(),
+					childJob("single", 0).Obj(),
+					childJob("single", 1).Obj(),
+				},
+			},
+			want: 100,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := calculateCompletionPercentage(js, &tc.jobs); got != tc.want {
+				t.Errorf("calculateCompletionPercentage() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNumPodsFailingImagePull(t *testing.T) {
+	var (
+		ns = "default"
+	)
+	waiting := func(reason string) []corev1.ContainerStatus {
+		return []corev1.ContainerStatus{
+			{
+				Name:  "test-container",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+			},
+		}
+	}
+	tests := []struct {
+		name string
+		pods []corev1.Pod
+		want int
+	}{
+		{
+			name: "no pods",
+			want: 0,
+		},
+		{
+			name: "running pods",
+			pods: []corev1.Pod{
+				*testutils.MakePod("pod-0", ns).Phase(corev1.PodRunning).Obj(),
+				*testutils.MakePod("pod-1", ns).Phase(corev1.PodRunning).Obj(),
+			},
+			want: 0,
+		},
+		{
+			name: "pods waiting for other reasons",
+			pods: []corev1.Pod{
+				*testutils.MakePod("pod-0", ns).ContainerStatuses(waiting("ContainerCreating")).Obj(),
+				*testutils.MakePod("pod-1", ns).ContainerStatuses(waiting("CrashLoopBackOff")).Obj(),
+			},
+			want: 0,
+		},
+		{
+			name: "some pods failing to pull images",
+			pods: []corev1.Pod{
+				*testutils.MakePod("pod-0", ns).ContainerStatuses(waiting("ErrImagePull")).Obj(),
+				*testutils.MakePod("pod-1", ns).ContainerStatuses(waiting("ImagePullBackOff")).Obj(),
+				*testutils.MakePod("pod-2", ns).Phase(corev1.PodRunning).Obj(),
+			},
+			want: 2,
+		},
+		{
+			name: "pod counted once with multiple failing containers",
+			pods: []corev1.Pod{
+				*testutils.MakePod("pod-0", ns).ContainerStatuses(append(waiting("ErrImagePull"), waiting("ImagePullBackOff")...)).Obj(),
+			},
+			want: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := numPodsFailingImagePull(tc.pods); got != tc.want {
+				t.Errorf("numPodsFailingImagePull() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUpdateImagePullFailureCondition(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	podLabels := map[string]string{
+		jobset.JobSetNameKey: jobSetName,
+		RestartsKey:          "0",
+	}
+	pullFailure := []corev1.ContainerStatus{
+		{
+			Name:  "test-container",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+		},
+	}
+
+	js := testutils.MakeJobSet(jobSetName, ns).Obj()
+	pod := testutils.MakePod("test-jobset-replicated-job-0-0", ns).Labels(podLabels).ContainerStatuses(pullFailure).Obj()
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, pod).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	ctx := context.TODO()
+
+	// A single failing pod is below a threshold of 2 pods.
+	r.ImagePullFailureThreshold = 2
+	if err := r.newReconciliation(js).updateImagePullFailureCondition(ctx, js); err != nil {
+		t.Fatalf("updateImagePullFailureCondition() error = %v", err)
+	}
+	if meta.IsStatusConditionTrue(js.Status.Conditions, string(jobset.JobSetImagePullFailure)) {
+		t.Errorf("expected %s condition not to be true below the threshold, got conditions: %v", jobset.JobSetImagePullFailure, js.Status.Conditions)
+	}
+
+	// It reaches the default threshold of 1 pod.
+	r.ImagePullFailureThreshold = 0
+	if err := r.newReconciliation(js).updateImagePullFailureCondition(ctx, js); err != nil {
+		t.Fatalf("updateImagePullFailureCondition() error = %v", err)
+	}
+	if !meta.IsStatusConditionTrue(js.Status.Conditions, string(jobset.JobSetImagePullFailure)) {
+		t.Errorf("expected %s condition to be true, got conditions: %v", jobset.JobSetImagePullFailure, js.Status.Conditions)
+	}
+
+	// Once the image is pulled, the condition should be cleared.
+	pod.Status.ContainerStatuses = nil
+	pod.Status.Phase = corev1.PodRunning
+	if err := r.Update(ctx, pod); err != nil {
+		t.Fatalf("updating pod: %v", err)
+	}
+	if err := r.newReconciliation(js).updateImagePullFailureCondition(ctx, js); err != nil {
+		t.Fatalf("updateImagePullFailureCondition() error = %v", err)
+	}
+	if !meta.IsStatusConditionFalse(js.Status.Conditions, string(jobset.JobSetImagePullFailure)) {
+		t.Errorf("expected %s condition to be false, got conditions: %v", jobset.JobSetImagePullFailure, js.Status.Conditions)
+	}
+}
+
+func TestAnnotatePodHostnames(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	podLabels := func(rjobName string) map[string]string {
+		return map[string]string{
+			jobset.JobSetNameKey:        jobSetName,
+			jobset.ReplicatedJobNameKey: rjobName,
+			RestartsKey:                 "0",
+		}
+	}
+
+	js := testutils.MakeJobSet(jobSetName, ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("annotated").
+			Job(testutils.MakeJobTemplate("job", ns).Obj()).
+			EnableDNSHostnames(true).
+			AnnotatePodHostnames(true).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("not-annotated").
+			Job(testutils.MakeJobTemplate("job", ns).Obj()).
+			EnableDNSHostnames(true).
+			Obj()).
+		Obj()
+	pods := []*corev1.Pod{
+		testutils.MakePod("test-jobset-annotated-0-0-abcde", ns).
+			Labels(podLabels("annotated")).
+			Hostname("test-jobset-annotated-0-0", "test-jobset-annotated").Obj(),
+		testutils.MakePod("test-jobset-annotated-0-1-abcde", ns).
+			Labels(podLabels("annotated")).
+			Hostname("test-jobset-annotated-0-1", "test-jobset-annotated").Obj(),
+		testutils.MakePod("test-jobset-not-annotated-0-0-abcde", ns).
+			Labels(podLabels("not-annotated")).
+			Hostname("test-jobset-not-annotated-0-0", "test-jobset-not-annotated").Obj(),
+	}
+	want := map[string]string{
+		"test-jobset-annotated-0-0-abcde":     "test-jobset-annotated-0-0.test-jobset-annotated",
+		"test-jobset-annotated-0-1-abcde":     "test-jobset-annotated-0-1.test-jobset-annotated",
+		"test-jobset-not-annotated-0-0-abcde": "",
+	}
+
+	builder := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js)
+	for _, pod := range pods {
+		builder = builder.WithObjects(pod)
+	}
+	// The first pod is deleted while it is annotated, which must not keep the others from
+	// being annotated.
+	deleted := "test-jobset-annotated-0-0-abcde"
+	r := JobSetReconciler{Client: &deletedPodClient{Client: builder.Build(), deleted: deleted}, Record: record.NewFakeRecorder(10)}
+	ctx := context.TODO()
+	want[deleted] = ""
+
+	if err := r.annotatePodHostnames(ctx, js); err != nil {
+		t.Fatalf("annotatePodHostnames() error = %v", err)
+	}
+	for name, wantHostname := range want {
+		var pod corev1.Pod
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, &pod); err != nil {
+			t.Fatalf("getting pod %s: %v", name, err)
+		}
+		if got := pod.Annotations[jobset.PodHostnameKey]; got != wantHostname {
+			t.Errorf("pod %s: got hostname annotation %q, want %q", name, got, wantHostname)
+		}
+	}
+}
+
+// deletedPodClient patches pods like an API server on which the given pod was deleted after
+// it was listed.
+type deletedPodClient struct {
+	client.Client
+	deleted string
+}
+
+func (c *deletedPodClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if _, ok := obj.(*corev1.Pod); ok && obj.GetName() == c.deleted {
+		return apierrors.NewNotFound(corev1.Resource("pods"), obj.GetName())
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestFirstPodNodeName(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	podLabels := func(rjobName string, jobIdx int) map[string]string {
+		return map[string]string{
+			jobset.JobSetNameKey:        jobSetName,
+			jobset.ReplicatedJobNameKey: rjobName,
+			jobset.JobIndexKey:          strconv.Itoa(jobIdx),
+			RestartsKey:                 "0",
+		}
+	}
+	completionIndex := func(idx int) map[string]string {
+		return map[string]string{batchv1.JobCompletionIndexAnnotation: strconv.Itoa(idx)}
+	}
+
+	js := testutils.MakeJobSet(jobSetName, ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("driver").
+			Job(testutils.MakeJobTemplate("job", ns).Obj()).
+			Replicas(1).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("job", ns).Obj()).
+			Replicas(2).
+			Obj()).
+		Obj()
+	pods := []*corev1.Pod{
+		testutils.MakePod("test-jobset-driver-0-0-abcde", ns).
+			Labels(podLabels("driver", 0)).Annotations(completionIndex(0)).Obj(),
+		testutils.MakePod("test-jobset-workers-0-1-abcde", ns).
+			Labels(podLabels("workers", 0)).Annotations(completionIndex(1)).Obj(),
+		testutils.MakePod("test-jobset-workers-0-0-abcde", ns).
+			Labels(podLabels("workers", 0)).Annotations(completionIndex(0)).Obj(),
+		testutils.MakePod("test-jobset-workers-1-0-abcde", ns).
+			Labels(podLabels("workers", 1)).Annotations(completionIndex(0)).Obj(),
+	}
+	// The later pods of the workers are scheduled first.
+	pods[1].Spec.NodeName = "node-b"
+	pods[3].Spec.NodeName = "node-c"
+
+	builder := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js)
+	for _, pod := range pods {
+		builder = builder.WithObjects(pod)
+	}
+	r := JobSetReconciler{Client: builder.Build(), Record: record.NewFakeRecorder(10)}
+	ctx := context.TODO()
+
+	gotNodeNames := func() map[string]string {
+		t.Helper()
+		if err := r.newReconciliation(js).calculateAndUpdateReplicatedJobsStatuses(ctx, js, &childJobs{}); err != nil {
+			t.Fatalf("calculateAndUpdateReplicatedJobsStatuses() error = %v", err)
+		}
+		nodeNames := map[string]string{}
+		for _, status := range js.Status.ReplicatedJobsStatus {
+			nodeNames[status.Name] = status.FirstPodNodeName
+		}
+		return nodeNames
+	}
+	want := map[string]string{"driver": "", "workers": ""}
+	if diff := cmp.Diff(want, gotNodeNames()); diff != "" {
+		t.Errorf("unexpected first pod node names before scheduling (-want/+got): %s", diff)
+	}
+
+	// The first pods of the driver and the workers are scheduled.
+	for name, nodeName := range map[string]string{
+		"test-jobset-driver-0-0-abcde":  "node-a",
+		"test-jobset-workers-0-0-abcde": "node-d",
+	} {
+		var pod corev1.Pod
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, &pod); err != nil {
+			t.Fatalf("getting pod %s: %v", name, err)
+		}
+		pod.Spec.NodeName = nodeName
+		if err := r.Update(ctx, &pod); err != nil {
+			t.Fatalf("updating pod %s: %v", name, err)
+		}
+	}
+	want = map[string]string{"driver": "node-a", "workers": "node-d"}
+	if diff := cmp.Diff(want, gotNodeNames()); diff != "" {
+		t.Errorf("unexpected first pod node names after scheduling (-want/+got): %s", diff)
+	}
+}
+
+func TestSyncEndpointSlices(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	podLabels := map[string]string{
+		jobset.JobSetNameKey:        jobSetName,
+		jobset.ReplicatedJobNameKey: "workers",
+		RestartsKey:                 "0",
+	}
+	readyPod := func(name, ip string, ready bool) *corev1.Pod {
+		pod := testutils.MakePod(name, ns).Labels(podLabels).Phase(corev1.PodRunning).Obj()
+		pod.Status.PodIP = ip
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}
+		return pod
+	}
+
+	js := testutils.MakeJobSet(jobSetName, ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("job", ns).Obj()).
+			EnableDNSHostnames(true).
+			PublishEndpointSlice(true).
+			Obj()).
+		Obj()
+	notReadyPod := readyPod("test-jobset-workers-0-2-abcde", "10.0.0.3", false)
+	builder := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		js,
+		readyPod("test-jobset-workers-0-0-abcde", "10.0.0.1", true),
+		readyPod("test-jobset-workers-0-1-abcde", "10.0.0.2", true),
+		notReadyPod,
+	)
+	r := JobSetReconciler{Client: builder.Build(), Scheme: testScheme(t), Record: record.NewFakeRecorder(10)}
+	ctx := context.TODO()
+
+	wantAddresses := func(want ...string) {
+		t.Helper()
+		if err := r.syncEndpointSlices(ctx, js); err != nil {
+			t.Fatalf("syncEndpointSlices() error = %v", err)
+		}
+		var slice discoveryv1.EndpointSlice
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "test-jobset-workers"}, &slice); err != nil {
+			t.Fatalf("getting endpoint slice: %v", err)
+		}
+		var got []string
+		for _, endpoint := range slice.Endpoints {
+			got = append(got, endpoint.Addresses...)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("unexpected endpoint addresses (-want/+got): %s", diff)
+		}
+		if got := slice.Labels[discoveryv1.LabelManagedBy]; got != endpointSliceManagedBy {
+			t.Errorf("got managed-by label %q, want %q", got, endpointSliceManagedBy)
+		}
+		if got := slice.Labels[discoveryv1.LabelServiceName]; got != jobSetName {
+			t.Errorf("got service-name label %q, want the headless service %q", got, jobSetName)
+		}
+	}
+
+	wantAddresses("10.0.0.1", "10.0.0.2")
+
+	notReadyPod.Status.Conditions[0].Status = corev1.ConditionTrue
+	if err := r.Update(ctx, notReadyPod); err != nil {
+		t.Fatalf("updating pod: %v", err)
+	}
+	wantAddresses("10.0.0.1", "10.0.0.2", "10.0.0.3")
+}
+
+func TestSyncEndpointSlicesIPv6(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	js := testutils.MakeJobSet(jobSetName, ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("job", ns).Obj()).
+			EnableDNSHostnames(true).
+			PublishEndpointSlice(true).
+			Obj()).
+		Obj()
+	pod := testutils.MakePod("test-jobset-workers-0-0-abcde", ns).
+		Labels(map[string]string{
+			jobset.JobSetNameKey:        jobSetName,
+			jobset.ReplicatedJobNameKey: "workers",
+			RestartsKey:                 "0",
+		}).
+		Phase(corev1.PodRunning).Obj()
+	pod.Status.PodIP = "fd00::1"
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}}
+	r := JobSetReconciler{Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, pod).Build(), Scheme: testScheme(t), Record: record.NewFakeRecorder(10)}
+	ctx := context.TODO()
+
+	// Before any pod is ready, the IP family is unknown and the slice is empty.
+	if err := r.syncEndpointSlices(ctx, js); err != nil {
+		t.Fatalf("syncEndpointSlices() error = %v", err)
+	}
+	pod.Status.Conditions[0].Status = corev1.ConditionTrue
+	if err := r.Update(ctx, pod); err != nil {
+		t.Fatalf("updating pod: %v", err)
+	}
+	if err := r.syncEndpointSlices(ctx, js); err != nil {
+		t.Fatalf("syncEndpointSlices() error = %v", err)
+	}
+	var slice discoveryv1.EndpointSlice
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "test-jobset-workers"}, &slice); err != nil {
+		t.Fatalf("getting endpoint slice: %v", err)
+	}
+	if slice.AddressType != discoveryv1.AddressTypeIPv6 {
+		t.Errorf("got address type %s, want %s", slice.AddressType, discoveryv1.AddressTypeIPv6)
+	}
+	if len(slice.Endpoints) != 1 || !cmp.Equal(slice.Endpoints[0].Addresses, []string{"fd00::1"}) {
+		t.Errorf("got endpoints %v, want the address of the ready pod", slice.Endpoints)
+	}
+}
+
+func TestUpdatePodsPendingCondition(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	podLabels := map[string]string{
+		jobset.JobSetNameKey: jobSetName,
+		RestartsKey:          "0",
+	}
+
+	js := testutils.MakeJobSet(jobSetName, ns).Obj()
+	stuckPod := testutils.MakePod("test-jobset-replicated-job-0-0", ns).Labels(podLabels).Phase(corev1.PodPending).Obj()
+	stuckPod.CreationTimestamp = metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	stuckPod.Status.Conditions = []corev1.PodCondition{{
+		Type:    corev1.PodScheduled,
+		Status:  corev1.ConditionFalse,
+		Reason:  corev1.PodReasonUnschedulable,
+		Message: "0/3 nodes are available: 3 Insufficient cpu.",
+	}}
+	recentPod := testutils.MakePod("test-jobset-replicated-job-0-1", ns).Labels(podLabels).Phase(corev1.PodPending).Obj()
+	recentPod.CreationTimestamp = metav1.NewTime(time.Now())
+	r := JobSetReconciler{
+		Client:               fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, stuckPod, recentPod).Build(),
+		Record:               record.NewFakeRecorder(10),
+		PodsPendingThreshold: 5 * time.Minute,
+	}
+	ctx := context.TODO()
+
+	requeueAfter, err := r.newReconciliation(js).updatePodsPendingCondition(ctx, js)
+	if err != nil {
+		t.Fatalf("updatePodsPendingCondition() error = %v", err)
+	}
+	// The recent pod should be checked again once it exceeds the threshold.
+	if requeueAfter <= 0 || requeueAfter > 5*time.Minute+time.Second {
+		t.Errorf("expected requeue within the threshold, got %s", requeueAfter)
+	}
+	condition := meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetPodsPending))
+	wantMessage := "1 pod(s) have been pending for longer than 5m0s: 0/3 nodes are available: 3 Insufficient cpu."
+	if condition == nil || condition.Status != metav1.ConditionTrue || condition.Reason != corev1.PodReasonUnschedulable || condition.Message != wantMessage {
+		t.Errorf("expected true %s condition with reason %s and message %q, got: %v", jobset.JobSetPodsPending, corev1.PodReasonUnschedulable, wantMessage, condition)
+	}
+
+	// Once the pod is scheduled and running, the condition should be cleared.
+	stuckPod.Status.Phase = corev1.PodRunning
+	stuckPod.Status.Conditions = nil
+	if err := r.Update(ctx, stuckPod); err != nil {
+		t.Fatalf("updating pod: %v", err)
+	}
+	if _, err := r.newReconciliation(js).updatePodsPendingCondition(ctx, js); err != nil {
+		t.Fatalf("updatePodsPendingCondition() error = %v", err)
+	}
+	if !meta.IsStatusConditionFalse(js.Status.Conditions, string(jobset.JobSetPodsPending)) {
+		t.Errorf("expected %s condition to be false, got conditions: %v", jobset.JobSetPodsPending, js.Status.Conditions)
+	}
+}
+
+func TestRestartServiceRestartPolicy(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	tests := []struct {
+		name            string
+		policy          jobset.ServiceRestartPolicy
+		wantSvcPersists bool
+	}{
+		{
+			name:            "default policy reuses the service",
+			wantSvcPersists: true,
+		},
+		{
+			name:            "reuse policy preserves the service",
+			policy:          jobset.ServiceRestartPolicyReuse,
+			wantSvcPersists: true,
+		},
+		{
+			name:            "recreate policy deletes the service",
+			policy:          jobset.ServiceRestartPolicyRecreate,
+			wantSvcPersists: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			js := testutils.MakeJobSet(jobSetName, ns).
+				FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 1}).
+				ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					EnableDNSHostnames(true).
+					ServiceRestartPolicy(tc.policy).
+					Replicas(1).
+					Obj()).Obj()
+			js.UID = "jobset-uid"
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            GenSubdomain(js, &js.Spec.ReplicatedJobs[0]),
+					Namespace:       ns,
+					OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(js, jobset.GroupVersion.WithKind("JobSet"))},
+				},
+			}
+			r := JobSetReconciler{
+				Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, svc).Build(),
+				Record: record.NewFakeRecorder(10),
+			}
+			ctx := context.TODO()
+
+			if err := r.newReconciliation(js).restartPolicyRecreateAll(ctx, js, &childJobs{}, js.Spec.FailurePolicy.MaxRestarts); err != nil {
+				t.Fatalf("restartPolicyRecreateAll() error = %v", err)
+			}
+			if js.Status.Restarts != 1 {
+				t.Errorf("expected jobset to restart, got %d restarts", js.Status.Restarts)
+			}
+			err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: svc.Name}, &corev1.Service{})
+			if tc.wantSvcPersists && err != nil {
+				t.Errorf("expected service to persist across the restart, got error: %v", err)
+			}
+			if !tc.wantSvcPersists && !apierrors.IsNotFound(err) {
+				t.Errorf("expected service to be deleted on restart, got error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFailureAggregationWindow(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	js := testutils.MakeJobSet(jobSetName, ns).
+		FailurePolicy(&jobset.FailurePolicy{
+			MaxRestarts:       3,
+			AggregationWindow: &metav1.Duration{Duration: time.Minute},
+		}).
+		ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(3).
+			Obj()).Obj()
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	ctx := context.TODO()
+	failedJobs := func(failedAgo ...time.Duration) *childJobs {
+		jobs := &childJobs{}
+		for i, ago := range failedAgo {
+			job := makeJob(&makeJobArgs{
+				jobSetName:        jobSetName,
+				replicatedJobName: "replicated-job",
+				jobName:           fmt.Sprintf("test-jobset-replicated-job-%d", i),
+				ns:                ns,
+				replicas:          3,
+				jobIdx:            i,
+			}).Obj()
+			job.Status.Conditions = []batchv1.JobCondition{{
+				Type:               batchv1.JobFailed,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-ago)),
+			}}
+			jobs.failed = append(jobs.failed, job)
+		}
+		return jobs
+	}
+
+	// Failures within the window are held back until the window passes.
+	for _, jobs := range []*childJobs{
+		failedJobs(10 * time.Second),
+		failedJobs(20*time.Second, 10*time.Second),
+		failedJobs(30*time.Second, 20*time.Second, 0),
+	} {
+		requeueAfter, err := r.newReconciliation(js).executeFailurePolicy(ctx, js, jobs)
+		if err != nil {
+			t.Fatalf("executeFailurePolicy() error = %v", err)
+		}
+		if requeueAfter <= 0 || requeueAfter > time.Minute {
+			t.Errorf("got requeue after %v, want the remainder of the window", requeueAfter)
+		}
+		if js.Status.Restarts != 0 {
+			t.Fatalf("expected no restart within the aggregation window, got %d restarts", js.Status.Restarts)
+		}
+	}
+
+	// Once the window has passed since the first failure, all failures result in a single restart.
+	requeueAfter, err := r.newReconciliation(js).executeFailurePolicy(ctx, js, failedJobs(70*time.Second, 60*time.Second, 40*time.Second))
+	if err != nil {
+		t.Fatalf("executeFailurePolicy() error = %v", err)
+	}
+	if requeueAfter != 0 {
+		t.Errorf("got requeue after %v, want none", requeueAfter)
+	}
+	if js.Status.Restarts != 1 {
+		t.Errorf("expected a single restart, got %d restarts", js.Status.Restarts)
+	}
+}
+
+func TestDeleteExpiredJobSet(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	finished := func(ttl *int32, finishedAgo time.Duration) *jobset.JobSet {
+		js := testutils.MakeJobSet(jobSetName, ns).
+			ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+				Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+				Replicas(1).
+				Obj()).Obj()
+		js.Spec.TTLSecondsAfterFinished = ttl
+		js.Status.Conditions = []metav1.Condition{{
+			Type:               string(jobset.JobSetCompleted),
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-finishedAgo)),
+		}}
+		return js
+	}
+	cleanupNow := func(js *jobset.JobSet) *jobset.JobSet {
+		js.Annotations = map[string]string{jobset.CleanupNowKey: "true"}
+		return js
+	}
+	tests := []struct {
+		name        string
+		js          *jobset.JobSet
+		stored      func(*jobset.JobSet)
+		wantDeleted bool
+		wantRequeue bool
+	}{
+		{
+			name: "no ttl",
+			js:   finished(nil, time.Hour),
+		},
+		{
+			name:        "ttl not expired",
+			js:          finished(pointer.Int32(60), 10*time.Second),
+			wantRequeue: true,
+		},
+		{
+			name:        "ttl expired",
+			js:          finished(pointer.Int32(60), 2*time.Minute),
+			wantDeleted: true,
+		},
+		{
+			name:        "zero ttl",
+			js:          finished(pointer.Int32(0), 0),
+			wantDeleted: true,
+		},
+		{
+			name:        "cleanup requested before ttl expired",
+			js:          cleanupNow(finished(pointer.Int32(60), 10*time.Second)),
+			wantDeleted: true,
+		},
+		{
+			name:        "cleanup requested without ttl",
+			js:          cleanupNow(finished(nil, 0)),
+			wantDeleted: true,
+		},
+		{
+			name: "cleanup requested before finishing",
+			js: func() *jobset.JobSet {
+				js := cleanupNow(finished(nil, 0))
+				js.Status.Conditions = nil
+				return js
+			}(),
+		},
+		{
+			name: "ttl extended since read",
+			js:   finished(pointer.Int32(60), 2*time.Minute),
+			stored: func(js *jobset.JobSet) {
+				js.Spec.TTLSecondsAfterFinished = pointer.Int32(170)
+			},
+			wantRequeue: true,
+		},
+		{
+			name: "recreated since read",
+			js:   finished(pointer.Int32(60), 2*time.Minute),
+			stored: func(js *jobset.JobSet) {
+				js.UID = "recreated"
+				js.Status.Conditions = nil
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stored := tc.js.DeepCopy()
+			if tc.stored != nil {
+				tc.stored(stored)
+			}
+			c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(stored).Build()
+			r := JobSetReconciler{Client: c, Record: record.NewFakeRecorder(10)}
+			requeueAfter, err := r.deleteExpiredJobSet(context.TODO(), tc.js)
+			if err != nil {
+				t.Fatalf("deleteExpiredJobSet() error = %v", err)
+			}
+			if tc.wantRequeue && (requeueAfter <= 40*time.Second || requeueAfter > 50*time.Second) {
+				t.Errorf("got requeue after %v, want the remainder of the ttl", requeueAfter)
+			}
+			if !tc.wantRequeue && requeueAfter != 0 {
+				t.Errorf("got requeue after %v, want none", requeueAfter)
+			}
+			err = c.Get(context.TODO(), types.NamespacedName{Name: jobSetName, Namespace: ns}, &jobset.JobSet{})
+			if deleted := apierrors.IsNotFound(err); deleted != tc.wantDeleted {
+				t.Errorf("got jobset deleted %t, want %t (get error: %v)", deleted, tc.wantDeleted, err)
+			}
+		})
+	}
+}
+
+func TestActiveDeadlineRemaining(t *testing.T) {
+	now := time.Now()
+	makeJobSet := func(deadline *int64, startedAgo time.Duration) *jobset.JobSet {
+		js := testutils.MakeJobSet("test-jobset", "default").
+			ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+				Job(testutils.MakeJobTemplate("test-job", "default").Obj()).
+				Replicas(1).
+				Obj()).Obj()
+		js.Spec.ActiveDeadlineSeconds = deadline
+		js.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+		startTime := metav1.NewTime(now.Add(-startedAgo))
+		js.Status.StartTime = &startTime
+		return js
+	}
+	tests := []struct {
+		name          string
+		js            *jobset.JobSet
+		wantOk        bool
+		wantRemaining time.Duration
+	}{
+		{
+			name: "no deadline",
+			js:   makeJobSet(nil, time.Hour),
+		},
+		{
+			name:          "deadline not reached",
+			js:            makeJobSet(pointer.Int64(60), 10*time.Second),
+			wantOk:        true,
+			wantRemaining: 50 * time.Second,
+		},
+		{
+			name:          "deadline exceeded",
+			js:            makeJobSet(pointer.Int64(60), 2*time.Minute),
+			wantOk:        true,
+			wantRemaining: -time.Minute,
+		},
+		{
+			name: "not started, e.g. while queued",
+			js: func() *jobset.JobSet {
+				js := makeJobSet(pointer.Int64(60), 2*time.Minute)
+				js.Status.StartTime = nil
+				return js
+			}(),
+		},
+		{
+			name: "suspended",
+			js: func() *jobset.JobSet {
+				js := makeJobSet(pointer.Int64(60), 2*time.Minute)
+				js.Spec.Suspend = pointer.Bool(true)
+				return js
+			}(),
+		},
+		{
+			name: "time spent suspended is excluded",
+			js: func() *jobset.JobSet {
+				js := makeJobSet(pointer.Int64(60), 2*time.Minute)
+				js.Status.SuspendedDuration = &metav1.Duration{Duration: time.Minute}
+				return js
+			}(),
+			wantOk: true,
+		},
+		{
+			name: "resumed before the suspension was accounted for",
+			js: func() *jobset.JobSet {
+				js := makeJobSet(pointer.Int64(60), 2*time.Minute)
+				js.Status.SuspendedDuration = &metav1.Duration{Duration: 30 * time.Second}
+				js.Status.Conditions = []metav1.Condition{{
+					Type:               string(jobset.JobSetSuspended),
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(now.Add(-40 * time.Second)),
+				}}
+				return js
+			}(),
+			wantOk:        true,
+			wantRemaining: 10 * time.Second,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			remaining, ok := activeDeadlineRemaining(tc.js, now)
+			if ok != tc.wantOk {
+				t.Fatalf("got ok %t, want %t", ok, tc.wantOk)
+			}
+			if remaining != tc.wantRemaining {
+				t.Errorf("got remaining %v, want %v", remaining, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestFailedConditionReason(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	failedJob := func(reason string) *batchv1.Job {
+		job := makeJob(&makeJobArgs{
+			jobSetName:        jobSetName,
+			replicatedJobName: "replicated-job",
+			jobName:           "test-jobset-replicated-job-0",
+			ns:                ns,
+			replicas:          1,
+		}).Obj()
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type:   batchv1.JobFailed,
+			Status: corev1.ConditionTrue,
+			Reason: reason,
+		}}
+		return job
+	}
+	tests := []struct {
+		name        string
+		job         *batchv1.Job
+		wantReason  string
+		wantMessage string
+	}{
+		{
+			name:        "backoff limit exceeded",
+			job:         failedJob("BackoffLimitExceeded"),
+			wantReason:  "BackoffLimitExceeded",
+			wantMessage: "jobset failed due to job test-jobset-replicated-job-0 exceeding its backoff limit",
+		},
+		{
+			name:        "deadline exceeded",
+			job:         failedJob("DeadlineExceeded"),
+			wantReason:  "FailedJobs",
+			wantMessage: "jobset failed due to one or more job failures",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			js := testutils.MakeJobSet(jobSetName, ns).
+				ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					Replicas(1).
+					Obj()).Obj()
+			r := JobSetReconciler{
+				Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build(),
+				Record: record.NewFakeRecorder(10),
+			}
+			if _, err := r.newReconciliation(js).executeFailurePolicy(context.TODO(), js, &childJobs{failed: []*batchv1.Job{tc.job}}); err != nil {
+				t.Fatalf("executeFailurePolicy() error = %v", err)
+			}
+			cond := meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetFailed))
+			if cond == nil || cond.Status != metav1.ConditionTrue {
+				t.Fatalf("expected condition %s to be true, got %v", jobset.JobSetFailed, cond)
+			}
+			if cond.Reason != tc.wantReason || cond.Message != tc.wantMessage {
+				t.Errorf("got reason %q and message %q, want %q and %q", cond.Reason, cond.Message, tc.wantReason, tc.wantMessage)
+			}
+		})
+	}
+}
+
+func TestReplicatedJobFailurePolicy(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	failedJob := func(replicatedJobName string) *batchv1.Job {
+		job := makeJob(&makeJobArgs{
+			jobSetName:        jobSetName,
+			replicatedJobName: replicatedJobName,
+			jobName:           fmt.Sprintf("test-jobset-%s-0", replicatedJobName),
+			ns:                ns,
+			replicas:          1,
+			jobIdx:            0,
+		}).Obj()
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+		return job
+	}
+	testCases := []struct {
+		name          string
+		failurePolicy *jobset.FailurePolicy
+		failedJobs    []*batchv1.Job
+		wantRestarts  int
+		wantFailed    bool
+	}{
+		{
+			name:         "worker failure restarts with the override",
+			failedJobs:   []*batchv1.Job{failedJob("workers")},
+			wantRestarts: 1,
+		},
+		{
+			name:       "driver failure fails without any failure policy",
+			failedJobs: []*batchv1.Job{failedJob("driver")},
+			wantFailed: true,
+		},
+		{
+			name:          "driver failure uses the failure policy of the jobset",
+			failurePolicy: &jobset.FailurePolicy{MaxRestarts: 2},
+			failedJobs:    []*batchv1.Job{failedJob("driver")},
+			wantRestarts:  1,
+		},
+		{
+			name:          "lowest max restarts applies to failures of several replicated jobs",
+			failurePolicy: &jobset.FailurePolicy{MaxRestarts: 0},
+			failedJobs:    []*batchv1.Job{failedJob("workers"), failedJob("driver")},
+			wantFailed:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			js := testutils.MakeJobSet(jobSetName, ns).
+				FailurePolicy(tc.failurePolicy).
+				ReplicatedJob(testutils.MakeReplicatedJob("driver").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					Replicas(1).
+					Obj()).
+				ReplicatedJob(testutils.MakeReplicatedJob("workers").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					Replicas(1).
+					FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 5}).
+					Obj()).Obj()
+			r := JobSetReconciler{
+				Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build(),
+				Record: record.NewFakeRecorder(10),
+			}
+			if _, err := r.newReconciliation(js).executeFailurePolicy(context.TODO(), js, &childJobs{failed: tc.failedJobs}); err != nil {
+				t.Fatalf("executeFailurePolicy() error = %v", err)
+			}
+			if js.Status.Restarts != tc.wantRestarts {
+				t.Errorf("got %d restarts, want %d", js.Status.Restarts, tc.wantRestarts)
+			}
+			if got := meta.IsStatusConditionTrue(js.Status.Conditions, string(jobset.JobSetFailed)); got != tc.wantFailed {
+				t.Errorf("got failed %v, want %v", got, tc.wantFailed)
+			}
+		})
+	}
+}
+
+func TestFailurePolicyRules(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	testCases := []struct {
+		name         string
+		exitCode     int32
+		podFailure   bool
+		wantActive   []string
+		wantRestarts int
+		wantReason   string
+	}{
+		{
+			name:         "restart rule restarts the jobset",
+			exitCode:     42,
+			wantRestarts: 1,
+		},
+		{
+			name:       "fail rule fails the jobset without restarting",
+			exitCode:   1,
+			wantReason: "FailurePolicyRuleMatched",
+		},
+		{
+			name:       "ignore rule leaves the job with a failed pod active",
+			exitCode:   2,
+			podFailure: true,
+			wantActive: []string{"test-jobset-replicated-job-0"},
+		},
+		{
+			name:         "ignore rule does not apply to a failed job",
+			exitCode:     2,
+			wantRestarts: 1,
+		},
+		{
+			name:         "unmatched exit code restarts the jobset",
+			exitCode:     3,
+			wantRestarts: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			js := testutils.MakeJobSet(jobSetName, ns).
+				FailurePolicy(&jobset.FailurePolicy{
+					MaxRestarts: 1,
+					Level:       jobset.FailureLevelPod,
+					Rules: []jobset.FailurePolicyRule{
+						{Action: jobset.FailurePolicyActionRestartJobSet, OnExitCodes: []int32{42}},
+						{Action: jobset.FailurePolicyActionFailJobSet, OnExitCodes: []int32{1}},
+						{Action: jobset.FailurePolicyActionIgnore, OnExitCodes: []int32{2}},
+					},
+				}).
+				ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					Replicas(1).
+					Obj()).Obj()
+			js.UID = "test-uid"
+			job := makeJob(&makeJobArgs{
+				jobSetName:        jobSetName,
+				replicatedJobName: "replicated-job",
+				jobName:           "test-jobset-replicated-job-0",
+				ns:                ns,
+				replicas:          1,
+			}).Failed(1).Obj()
+			job.UID = "job-uid"
+			job.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(js, jobset.GroupVersion.WithKind("JobSet"))}
+			if !tc.podFailure {
+				job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+			}
+			pod := testutils.MakePod("test-jobset-replicated-job-0-0", ns).
+				Labels(map[string]string{jobset.JobNameKey: job.Name}).
+				Phase(corev1.PodFailed).
+				ContainerStatuses([]corev1.ContainerStatus{{
+					Name:  "test-container",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: tc.exitCode}},
+				}}).Obj()
+			pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(job, batchv1.SchemeGroupVersion.WithKind("Job"))}
+
+			r := JobSetReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(testScheme(t)).
+					WithIndex(&batchv1.Job{}, jobOwnerKey, indexJobOwner).
+					WithObjects(js, job, pod).Build(),
+				Record: record.NewFakeRecorder(10),
+			}
+			ownedJobs, err := r.getChildJobs(context.TODO(), js)
+			if err != nil {
+				t.Fatalf("getChildJobs() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.wantActive, jobNames(ownedJobs.active)); diff != "" {
+				t.Errorf("unexpected active jobs (-want +got):\n%s", diff)
+			}
+			if len(tc.wantActive) > 0 {
+				// The ignored failures are recorded on the job, so its pods are not listed again.
+				if err := r.Delete(context.TODO(), pod); err != nil {
+					t.Fatalf("deleting pod: %v", err)
+				}
+				if ownedJobs, err = r.getChildJobs(context.TODO(), js); err != nil {
+					t.Fatalf("getChildJobs() error = %v", err)
+				}
+				if diff := cmp.Diff(tc.wantActive, jobNames(ownedJobs.active)); diff != "" {
+					t.Errorf("unexpected active jobs after recording ignored failures (-want +got):\n%s", diff)
+				}
+			}
+			if len(ownedJobs.failed) > 0 {
+				if _, err := r.newReconciliation(js).executeFailurePolicy(context.TODO(), js, ownedJobs); err != nil {
+					t.Fatalf("executeFailurePolicy() error = %v", err)
+				}
+			}
+			if js.Status.Restarts != tc.wantRestarts {
+				t.Errorf("got %d restarts, want %d", js.Status.Restarts, tc.wantRestarts)
+			}
+			cond := meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetFailed))
+			if tc.wantReason == "" {
+				if cond != nil {
+					t.Errorf("expected no condition %s, got %v", jobset.JobSetFailed, cond)
+				}
+			} else if cond == nil || cond.Reason != tc.wantReason {
+				t.Errorf("expected condition %s with reason %s, got %v", jobset.JobSetFailed, tc.wantReason, cond)
+			}
+		})
+	}
+}
+
+func TestReplicatedJobFailureLevel(t *testing.T) {
+	js := testutils.MakeJobSet("test-jobset", "default").
+		FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 1, Level: jobset.FailureLevelJob}).
+		ReplicatedJob(testutils.MakeReplicatedJob("driver").
+			Job(testutils.MakeJobTemplate("test-job", "default").Obj()).
+			Replicas(1).
+			FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 1, Level: jobset.FailureLevelPod}).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", "default").Obj()).
+			Replicas(1).
+			FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 5}).
+			Obj()).Obj()
+	for rjob, want := range map[string]jobset.FailureLevel{
+		"driver":  jobset.FailureLevelPod,
+		"workers": jobset.FailureLevelJob,
+	} {
+		if got := failureLevel(js, rjob); got != want {
+			t.Errorf("failureLevel(%s) = %s, want %s", rjob, got, want)
+		}
+	}
+}
+
+func TestNonControllingOwnerReferences(t *testing.T) {
+	for _, nonControlling := range []bool{false, true} {
+		t.Run(fmt.Sprintf("non-controlling %t", nonControlling), func(t *testing.T) {
+			js := testutils.MakeJobSet("test-jobset", "default").
+				SetAnnotations(map[string]string{jobset.ManagedServiceAccountKey: "true"}).
+				FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 1}).
+				ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+					Job(testutils.MakeJobTemplate("test-job", "default").Obj()).
+					EnableDNSHostnames(true).
+					ServiceRestartPolicy(jobset.ServiceRestartPolicyRecreate).
+					Replicas(1).
+					Obj()).Obj()
+			js.UID = "jobset-uid"
+			scheme := testScheme(t)
+			r := JobSetReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithIndex(&batchv1.Job{}, jobOwnerKey, indexJobOwner).
+					WithIndex(&batchv1.Job{}, jobOwnerReferenceKey, indexJobOwnerReference).
+					WithObjects(js).
+					Build(),
+				Scheme:                        scheme,
+				Record:                        record.NewFakeRecorder(10),
+				NonControllingOwnerReferences: nonControlling,
+			}
+			ctx := context.TODO()
+			svcKey := types.NamespacedName{Namespace: js.Namespace, Name: GenSubdomain(js, &js.Spec.ReplicatedJobs[0])}
+			if err := r.newReconciliation(js).createJobs(ctx, js, &childJobs{}); err != nil {
+				t.Fatalf("createJobs() error = %v", err)
+			}
+
+			// The child jobs are still found through their owner reference.
+			ownedJobs, err := r.getChildJobs(ctx, js)
+			if err != nil {
+				t.Fatalf("getChildJobs() error = %v", err)
+			}
+			if len(ownedJobs.active) != 1 {
+				t.Fatalf("got %d active jobs, want 1", len(ownedJobs.active))
+			}
+			var svc corev1.Service
+			if err := r.Get(ctx, svcKey, &svc); err != nil {
+				t.Fatalf("getting headless service: %v", err)
+			}
+			for _, obj := range []metav1.Object{ownedJobs.active[0], &svc} {
+				refs := obj.GetOwnerReferences()
+				if len(refs) != 1 || refs[0].UID != js.UID {
+					t.Fatalf("got owner references %v of %s, want the jobset", refs, obj.GetName())
+				}
+				if got := pointer.BoolDeref(refs[0].Controller, false); got != !nonControlling {
+					t.Errorf("got controller %t in owner reference of %s, want %t", got, obj.GetName(), !nonControlling)
+				}
+			}
+
+			// The next reconcile recognizes the service and service account it created.
+			if err := r.newReconciliation(js).createJobs(ctx, js, ownedJobs); err != nil {
+				t.Fatalf("createJobs() error = %v", err)
+			}
+			if meta.IsStatusConditionTrue(js.Status.Conditions, string(jobset.JobSetServiceAccountConflict)) {
+				t.Errorf("expected condition %s not to be true, got conditions %v", jobset.JobSetServiceAccountConflict, js.Status.Conditions)
+			}
+
+			// A restart deletes the service, and it is recreated along with the jobs of the
+			// next attempt.
+			if err := r.newReconciliation(js).restartPolicyRecreateAll(ctx, js, ownedJobs, js.Spec.FailurePolicy.MaxRestarts); err != nil {
+				t.Fatalf("restartPolicyRecreateAll() error = %v", err)
+			}
+			if err := r.Get(ctx, svcKey, &corev1.Service{}); !apierrors.IsNotFound(err) {
+				t.Fatalf("expected headless service to be deleted on restart, got error: %v", err)
+			}
+			if ownedJobs, err = r.getChildJobs(ctx, js); err != nil {
+				t.Fatalf("getChildJobs() error = %v", err)
+			}
+			if err := r.deleteJobs(ctx, ownedJobs.delete); err != nil {
+				t.Fatalf("deleteJobs() error = %v", err)
+			}
+			if err := r.newReconciliation(js).createJobs(ctx, js, &childJobs{}); err != nil {
+				t.Fatalf("createJobs() error = %v", err)
+			}
+			if err := r.Get(ctx, svcKey, &svc); err != nil {
+				t.Fatalf("getting recreated headless service: %v", err)
+			}
+			if !ownedBy(&svc, js) {
+				t.Errorf("got owner references %v of recreated headless service, want the jobset", svc.OwnerReferences)
+			}
+			if meta.IsStatusConditionTrue(js.Status.Conditions, string(jobset.JobSetServiceAccountConflict)) {
+				t.Errorf("expected condition %s not to be true, got conditions %v", jobset.JobSetServiceAccountConflict, js.Status.Conditions)
+			}
+		})
+	}
+}
+
+func TestDedupeChildOwnerReferences(t *testing.T) {
+	js := testutils.MakeJobSet("test-jobset", "default").
+		ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+			Job(testutils.MakeJobTemplate("test-job", "default").Obj()).
+			EnableDNSHostnames(true).
+			Replicas(1).
+			Obj()).Obj()
+	js.UID = "jobset-uid"
+	scheme := testScheme(t)
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithIndex(&batchv1.Job{}, jobOwnerKey, indexJobOwner).
+			WithObjects(js).
+			Build(),
+		Scheme: scheme,
+		Record: record.NewFakeRecorder(10),
+	}
+	ctx := context.TODO()
+	if err := r.newReconciliation(js).createJobs(ctx, js, &childJobs{}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+
+	// A mutating webhook appends a non-controlling copy of the owner reference to the children.
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: js.Namespace, Name: "test-jobset-replicated-job-0"}, job); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	svc := &corev1.Service{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: js.Namespace, Name: GenSubdomain(js, &js.Spec.ReplicatedJobs[0])}, svc); err != nil {
+		t.Fatalf("getting headless service: %v", err)
+	}
+	if _, err := r.createServiceAccountIfNotExist(ctx, js); err != nil {
+		t.Fatalf("createServiceAccountIfNotExist() error = %v", err)
+	}
+	sa := &corev1.ServiceAccount{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: js.Namespace, Name: js.Name}, sa); err != nil {
+		t.Fatalf("getting service account: %v", err)
+	}
+	slice, err := r.constructEndpointSlice(js, &js.Spec.ReplicatedJobs[0], nil)
+	if err != nil {
+		t.Fatalf("constructEndpointSlice() error = %v", err)
+	}
+	if err := r.Create(ctx, slice); err != nil {
+		t.Fatalf("creating endpoint slice: %v", err)
+	}
+	children := []client.Object{job, svc, sa, slice}
+	for _, obj := range children {
+		refs := obj.GetOwnerReferences()
+		duplicate := refs[0]
+		duplicate.Controller = nil
+		obj.SetOwnerReferences(append([]metav1.OwnerReference{duplicate}, refs...))
+		if err := r.Update(ctx, obj); err != nil {
+			t.Fatalf("updating %s: %v", obj.GetName(), err)
+		}
+	}
+
+	if err := r.dedupeChildOwnerReferences(ctx, js); err != nil {
+		t.Fatalf("dedupeChildOwnerReferences() error = %v", err)
+	}
+	for _, obj := range children {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			t.Fatalf("getting %s: %v", obj.GetName(), err)
+		}
+		refs := obj.GetOwnerReferences()
+		if len(refs) != 1 || refs[0].UID != js.UID {
+			t.Fatalf("got owner references %v of %T %s, want the jobset once", refs, obj, obj.GetName())
+		}
+		if !pointer.BoolDeref(refs[0].Controller, false) {
+			t.Errorf("got non-controlling owner reference of %s, want the controlling one kept", obj.GetName())
+		}
+	}
+}
+
+func TestRestartingCondition(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	js := testutils.MakeJobSet(jobSetName, ns).
+		FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 1}).
+		ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(1).
+			Obj()).Obj()
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	ctx := context.TODO()
+	oldJob := makeJob(&makeJobArgs{
+		jobSetName:        jobSetName,
+		replicatedJobName: "replicated-job",
+		jobName:           "test-jobset-replicated-job-0",
+		ns:                ns,
+		replicas:          1,
+		jobIdx:            0,
+	}).Obj()
+
+	wantRestarting := func(want metav1.ConditionStatus) {
+		t.Helper()
+		var got jobset.JobSet
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: jobSetName}, &got); err != nil {
+			t.Fatalf("getting jobset: %v", err)
+		}
+		cond := meta.FindStatusCondition(got.Status.Conditions, string(jobset.JobSetRestarting))
+		if cond == nil || cond.Status != want {
+			t.Errorf("got restarting condition %v, want status %s", cond, want)
+		}
+	}
+
+	// Restarting the JobSet sets the condition right away.
+	if err := r.newReconciliation(js).restartPolicyRecreateAll(ctx, js, &childJobs{active: []*batchv1.Job{oldJob}}, js.Spec.FailurePolicy.MaxRestarts); err != nil {
+		t.Fatalf("restartPolicyRecreateAll() error = %v", err)
+	}
+	wantRestarting(metav1.ConditionTrue)
+
+	// The condition remains while the jobs of the previous attempt are being deleted.
+	if err := r.newReconciliation(js).updateRestartingCondition(ctx, js, &childJobs{delete: []*batchv1.Job{oldJob}}); err != nil {
+		t.Fatalf("updateRestartingCondition() error = %v", err)
+	}
+	wantRestarting(metav1.ConditionTrue)
+
+	// The condition is cleared once the old jobs are gone and the new jobs were recreated.
+	if err := r.newReconciliation(js).updateRestartingCondition(ctx, js, &childJobs{}); err != nil {
+		t.Fatalf("updateRestartingCondition() error = %v", err)
+	}
+	wantRestarting(metav1.ConditionFalse)
+}
+
+func TestRestartDeletions(t *testing.T) {
+	var (
+		jobSetName        = "test-jobset"
+		replicatedJobName = "replicated-job"
+		ns                = "default"
+	)
+	makeJobSet := func(maxUnavailable *int32) *jobset.JobSet {
+		return testutils.MakeJobSet(jobSetName, ns).
+			FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 1, MaxUnavailable: maxUnavailable}).
+			ReplicatedJob(testutils.MakeReplicatedJob(replicatedJobName).
+				Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+				Replicas(4).
+				Obj()).Obj()
+	}
+	// job returns a child job whose single pod is ready, unless the job failed.
+	job := func(jobIdx int, failed bool) *batchv1.Job {
+		job := makeJob(&makeJobArgs{
+			jobSetName:        jobSetName,
+			replicatedJobName: replicatedJobName,
+			jobName:           fmt.Sprintf("test-jobset-replicated-job-%d", jobIdx),
+			ns:                ns,
+			replicas:          4,
+			jobIdx:            jobIdx,
+		}).Parallelism(1).Ready(1).Obj()
+		if failed {
+			job.Status.Ready = pointer.Int32(0)
+			job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+		}
+		return job
+	}
+	unready := func(job *batchv1.Job) *batchv1.Job {
+		job.Status.Ready = pointer.Int32(0)
+		return job
+	}
+	testCases := []struct {
+		name      string
+		js        *jobset.JobSet
+		ownedJobs *childJobs
+		want      []string
+	}{
+		{
+			name:      "all jobs deleted without maxUnavailable",
+			js:        makeJobSet(nil),
+			ownedJobs: &childJobs{delete: []*batchv1.Job{job(0, true), job(1, false), job(2, false), job(3, false)}},
+			want:      []string{"test-jobset-replicated-job-0", "test-jobset-replicated-job-1", "test-jobset-replicated-job-2", "test-jobset-replicated-job-3"},
+		},
+		{
+			name:      "running jobs deleted up to maxUnavailable",
+			js:        makeJobSet(pointer.Int32(2)),
+			ownedJobs: &childJobs{delete: []*batchv1.Job{job(3, false), job(2, false), job(1, false), job(0, false)}},
+			want:      []string{"test-jobset-replicated-job-0", "test-jobset-replicated-job-1"},
+		},
+		{
+			name:      "failed jobs count as unavailable",
+			js:        makeJobSet(pointer.Int32(1)),
+			ownedJobs: &childJobs{delete: []*batchv1.Job{job(0, true), job(1, false), job(2, false), job(3, false)}},
+			want:      []string{"test-jobset-replicated-job-0"},
+		},
+		{
+			name: "next job deleted once a recreated job is active",
+			js:   makeJobSet(pointer.Int32(1)),
+			ownedJobs: &childJobs{
+				active: []*batchv1.Job{job(0, false)},
+				delete: []*batchv1.Job{job(1, false), job(2, false), job(3, false)},
+			},
+			want: []string{"test-jobset-replicated-job-1"},
+		},
+		{
+			name: "next job not deleted while a recreated job is not ready",
+			js:   makeJobSet(pointer.Int32(1)),
+			ownedJobs: &childJobs{
+				active: []*batchv1.Job{unready(job(0, false))},
+				delete: []*batchv1.Job{job(1, false), job(2, false), job(3, false)},
+			},
+		},
+		{
+			name: "unready jobs deleted regardless of maxUnavailable",
+			js:   makeJobSet(pointer.Int32(1)),
+			ownedJobs: &childJobs{
+				active: []*batchv1.Job{unready(job(0, false))},
+				delete: []*batchv1.Job{job(1, false), unready(job(2, false)), job(3, false)},
+			},
+			want: []string{"test-jobset-replicated-job-2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, job := range restartDeletions(tc.js, tc.ownedJobs) {
+				got = append(got, job.Name)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected deletions (-want/+got): %s", diff)
+			}
+		})
+	}
+
+	// Roll through a full restart, recreating the deleted jobs before the next
+	// reconciliation, and check the budget is never exceeded.
+	t.Run("rolling restart never exceeds maxUnavailable", func(t *testing.T) {
+		js := makeJobSet(pointer.Int32(2))
+		ownedJobs := &childJobs{delete: []*batchv1.Job{job(0, true), job(1, false), job(2, false), job(3, false)}}
+		for step := 0; len(ownedJobs.delete) > 0; step++ {
+			if step > 4 {
+				t.Fatalf("restart did not finish, jobs left: %d", len(ownedJobs.delete))
+			}
+			deleted := map[string]*batchv1.Job{}
+			for _, job := range restartDeletions(js, ownedJobs) {
+				deleted[job.Name] = job
+			}
+			var remaining []*batchv1.Job
+			for _, job := range ownedJobs.delete {
+				if deleted[job.Name] == nil {
+					remaining = append(remaining, job)
+				}
+			}
+			if down := 4 - len(ownedJobs.active) - len(unfinishedJobs(remaining)); down > 2 {
+				t.Errorf("step %d: %d jobs down, want at most 2", step, down)
+			}
+			for _, deletedJob := range deleted {
+				jobIdx, _ := strconv.Atoi(deletedJob.Labels[jobset.JobIndexKey])
+				ownedJobs.active = append(ownedJobs.active, job(jobIdx, false))
+			}
+			ownedJobs.delete = remaining
+		}
+	})
+}
+
+func TestBlueGreenRestart(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	js := testutils.MakeJobSet(jobSetName, ns).
+		FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 1, RestartStrategy: jobset.RestartStrategyBlueGreen}).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(2).
+			Obj()).Obj()
+	js.Status.Restarts = 1
+	job := func(jobName string, jobIdx int, ready int32) *batchv1.Job {
+		return makeJob(&makeJobArgs{
+			jobSetName:        jobSetName,
+			replicatedJobName: "workers",
+			jobName:           jobName,
+			ns:                ns,
+			replicas:          2,
+			jobIdx:            jobIdx,
+		}).Parallelism(1).Ready(ready).Obj()
+	}
+	previous := []*batchv1.Job{job("test-jobset-workers-0", 0, 1), job("test-jobset-workers-1", 1, 1)}
+
+	// The jobs of the new attempt are created alongside those of the previous attempt.
+	scheme := testScheme(t)
+	c := &creationRecordingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(js).Build()}
+	r := JobSetReconciler{Client: c, Scheme: scheme, Record: record.NewFakeRecorder(10)}
+	if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{delete: previous}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"test-jobset-workers-0-r1", "test-jobset-workers-1-r1"}, c.createdJobs); diff != "" {
+		t.Errorf("unexpected jobs created (-want +got):\n%s", diff)
+	}
+
+	// The jobs of the previous attempt are only deleted once all new jobs are ready.
+	testCases := []struct {
+		name   string
+		active []*batchv1.Job
+		want   []string
+	}{
+		{
+			name:   "new jobs not ready",
+			active: []*batchv1.Job{job("test-jobset-workers-0-r1", 0, 1), job("test-jobset-workers-1-r1", 1, 0)},
+		},
+		{
+			name:   "new jobs ready",
+			active: []*batchv1.Job{job("test-jobset-workers-0-r1", 0, 1), job("test-jobset-workers-1-r1", 1, 1)},
+			want:   []string{"test-jobset-workers-0", "test-jobset-workers-1"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, job := range restartDeletions(js, &childJobs{active: tc.active, delete: previous}) {
+				got = append(got, job.Name)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected deletions (-want/+got): %s", diff)
+			}
+		})
+	}
+
+	// When the new attempt fails before it is ready, the JobSet restarts again, and only the
+	// attempt being replaced keeps running alongside the next one.
+	t.Run("replacement attempt failed", func(t *testing.T) {
+		js := js.DeepCopy()
+		js.Status.Restarts = 2
+		attemptJob := func(jobName string, jobIdx, restarts int) *batchv1.Job {
+			return makeJob(&makeJobArgs{
+				jobSetName:        jobSetName,
+				replicatedJobName: "workers",
+				jobName:           jobName,
+				ns:                ns,
+				replicas:          2,
+				jobIdx:            jobIdx,
+				restarts:          restarts,
+			}).Parallelism(1).Ready(1).Obj()
+		}
+		failedReplacement := attemptJob("test-jobset-workers-1-r1", 1, 1)
+		failedReplacement.Status.Ready = pointer.Int32(0)
+		failedReplacement.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+		ownedJobs := &childJobs{
+			active: []*batchv1.Job{attemptJob("test-jobset-workers-0-r2", 0, 2)},
+			delete: []*batchv1.Job{
+				attemptJob("test-jobset-workers-0", 0, 0),
+				attemptJob("test-jobset-workers-1", 1, 0),
+				attemptJob("test-jobset-workers-0-r1", 0, 1),
+				failedReplacement,
+			},
+		}
+		var got []string
+		for _, job := range restartDeletions(js, ownedJobs) {
+			got = append(got, job.Name)
+		}
+		if diff := cmp.Diff([]string{"test-jobset-workers-0", "test-jobset-workers-1"}, got); diff != "" {
+			t.Errorf("unexpected deletions (-want/+got): %s", diff)
+		}
+	})
+}
+
+// conflictingStatusClient fails every status update with a conflict.
+type conflictingStatusClient struct {
+	client.Client
+	statusUpdates int
+}
+
+func (c *conflictingStatusClient) Status() client.SubResourceWriter {
+	return &conflictingStatusWriter{SubResourceWriter: c.Client.Status(), client: c}
+}
+
+type conflictingStatusWriter struct {
+	client.SubResourceWriter
+	client *conflictingStatusClient
+}
+
+func (w *conflictingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	w.client.statusUpdates++
+	return apierrors.NewConflict(jobset.GroupVersion.WithResource("jobsets").GroupResource(), obj.GetName(), errors.New("object has been modified"))
+}
+
+func TestUpdateJobSetStatus(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	ctx := context.TODO()
+
+	t.Run("status persists after a conflicting update", func(t *testing.T) {
+		js := testutils.MakeJobSet(jobSetName, ns).Obj()
+		r := JobSetReconciler{
+			Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build(),
+			Record: record.NewFakeRecorder(10),
+		}
+		var stale jobset.JobSet
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: jobSetName}, &stale); err != nil {
+			t.Fatalf("getting jobset: %v", err)
+		}
+
+		// A concurrent writer updates the JobSet, making the copy held by the reconciler stale.
+		var latest jobset.JobSet
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: jobSetName}, &latest); err != nil {
+			t.Fatalf("getting jobset: %v", err)
+		}
+		latest.Labels = map[string]string{"updated": "true"}
+		if err := r.Update(ctx, &latest); err != nil {
+			t.Fatalf("updating jobset: %v", err)
+		}
+
+		rec := r.newReconciliation(&stale)
+		stale.Status.Restarts = 3
+		if err := rec.updateJobSetStatus(ctx, &stale); err != nil {
+			t.Fatalf("updateJobSetStatus() error = %v", err)
+		}
+		var got jobset.JobSet
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: jobSetName}, &got); err != nil {
+			t.Fatalf("getting jobset: %v", err)
+		}
+		if got.Status.Restarts != 3 {
+			t.Errorf("got %d restarts, want 3", got.Status.Restarts)
+		}
+		if got.Labels["updated"] != "true" {
+			t.Errorf("concurrent update was lost, got labels %v", got.Labels)
+		}
+	})
+
+	t.Run("concurrent status update is not overwritten", func(t *testing.T) {
+		js := testutils.MakeJobSet(jobSetName, ns).Obj()
+		r := JobSetReconciler{
+			Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build(),
+			Record: record.NewFakeRecorder(10),
+		}
+		var stale jobset.JobSet
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: jobSetName}, &stale); err != nil {
+			t.Fatalf("getting jobset: %v", err)
+		}
+		rec := r.newReconciliation(&stale)
+
+		// A concurrent writer updates the status, which the stale status was not computed from.
+		var latest jobset.JobSet
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: jobSetName}, &latest); err != nil {
+			t.Fatalf("getting jobset: %v", err)
+		}
+		latest.Status.Restarts = 2
+		if err := r.Status().Update(ctx, &latest); err != nil {
+			t.Fatalf("updating jobset status: %v", err)
+		}
+
+		stale.Status.Restarts = 1
+		if err := rec.updateJobSetStatus(ctx, &stale); !apierrors.IsConflict(err) {
+			t.Errorf("updateJobSetStatus() error = %v, want conflict", err)
+		}
+		var got jobset.JobSet
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: jobSetName}, &got); err != nil {
+			t.Fatalf("getting jobset: %v", err)
+		}
+		if got.Status.Restarts != 2 {
+			t.Errorf("got %d restarts, want the concurrently written 2", got.Status.Restarts)
+		}
+	})
+
+	t.Run("gives up after the configured number of retries", func(t *testing.T) {
+		js := testutils.MakeJobSet(jobSetName, ns).Obj()
+		c := &conflictingStatusClient{Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build()}
+		r := JobSetReconciler{Client: c, Record: record.NewFakeRecorder(10), StatusUpdateRetries: 2}
+
+		err := r.newReconciliation(js).updateJobSetStatus(ctx, js)
+		if !apierrors.IsConflict(err) {
+			t.Errorf("updateJobSetStatus() error = %v, want conflict", err)
+		}
+		if c.statusUpdates != 2 {
+			t.Errorf("got %d status update attempts, want 2", c.statusUpdates)
+		}
+	})
+}
+
+func TestWaitForCapacity(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	makeNode := func(name, cpu string, schedulable bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Unschedulable: !schedulable},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+				Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	// The JobSet runs 2 jobs with 2 pods each, requesting 1 CPU per pod.
+	makeJobSet := func(annotations map[string]string) *jobset.JobSet {
+		return testutils.MakeJobSet(jobSetName, ns).
+			SetAnnotations(annotations).
+			ReplicatedJob(testutils.MakeReplicatedJob("workers").
+				Job(testutils.MakeJobTemplate("test-job", ns).
+					Parallelism(2).
+					PodSpec(corev1.PodSpec{Containers: []corev1.Container{{
+						Name: "worker",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+						},
+					}}}).Obj()).
+				Replicas(2).
+				Obj()).Obj()
+	}
+	optIn := map[string]string{jobset.WaitForCapacityKey: "true"}
+
+	tests := []struct {
+		name          string
+		js            *jobset.JobSet
+		ownedJobs     *childJobs
+		nodes         []*corev1.Node
+		wantWaiting   bool
+		wantCondition metav1.ConditionStatus
+	}{
+		{
+			name:        "not opted in",
+			js:          makeJobSet(nil),
+			ownedJobs:   &childJobs{},
+			wantWaiting: false,
+		},
+		{
+			name:          "insufficient capacity",
+			js:            makeJobSet(optIn),
+			ownedJobs:     &childJobs{},
+			nodes:         []*corev1.Node{makeNode("node-1", "2", true), makeNode("node-2", "2", false)},
+			wantWaiting:   true,
+			wantCondition: metav1.ConditionTrue,
+		},
+		{
+			name:        "sufficient capacity",
+			js:          makeJobSet(optIn),
+			ownedJobs:   &childJobs{},
+			nodes:       []*corev1.Node{makeNode("node-1", "2", true), makeNode("node-2", "2", true)},
+			wantWaiting: false,
+		},
+		{
+			name: "jobs already created",
+			js:   makeJobSet(optIn),
+			ownedJobs: &childJobs{active: []*batchv1.Job{makeJob(&makeJobArgs{
+				jobSetName:        jobSetName,
+				replicatedJobName: "workers",
+				jobName:           "test-jobset-workers-0",
+				ns:                ns,
+				replicas:          2,
+				jobIdx:            0,
+			}).Obj()}},
+			wantWaiting: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(tc.js)
+			for _, node := range tc.nodes {
+				builder = builder.WithObjects(node)
+			}
+			r := JobSetReconciler{Client: builder.Build(), Record: record.NewFakeRecorder(10)}
+
+			waiting, err := r.newReconciliation(tc.js).waitForCapacity(context.TODO(), tc.js, tc.ownedJobs)
+			if err != nil {
+				t.Fatalf("waitForCapacity() error = %v", err)
+			}
+			if waiting != tc.wantWaiting {
+				t.Errorf("got waiting %v, want %v", waiting, tc.wantWaiting)
+			}
+			cond := meta.FindStatusCondition(tc.js.Status.Conditions, string(jobset.JobSetWaitingForCapacity))
+			if tc.wantCondition == "" && cond != nil {
+				t.Errorf("unexpected condition %v", cond)
+			}
+			if tc.wantCondition != "" && (cond == nil || cond.Status != tc.wantCondition) {
+				t.Errorf("got condition %v, want status %s", cond, tc.wantCondition)
+			}
+		})
+	}
+}
+
+func TestWaitForCapacityClearsCondition(t *testing.T) {
+	js := testutils.MakeJobSet("test-jobset", "default").
+		SetAnnotations(map[string]string{jobset.WaitForCapacityKey: "true"}).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", "default").
+				PodSpec(corev1.PodSpec{Containers: []corev1.Container{{
+					Name: "worker",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+				}}}).Obj()).
+			Replicas(1).
+			Obj()).Obj()
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build()
+	r := JobSetReconciler{Client: c, Record: record.NewFakeRecorder(10)}
+	ctx := context.TODO()
+
+	if waiting, err := r.newReconciliation(js).waitForCapacity(ctx, js, &childJobs{}); err != nil || !waiting {
+		t.Fatalf("waitForCapacity() = %v, %v, want waiting without nodes", waiting, err)
+	}
+
+	// A node joins the cluster, so the JobSet no longer waits.
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	if err := c.Create(ctx, node); err != nil {
+		t.Fatalf("creating node: %v", err)
+	}
+	if waiting, err := r.newReconciliation(js).waitForCapacity(ctx, js, &childJobs{}); err != nil || waiting {
+		t.Fatalf("waitForCapacity() = %v, %v, want not waiting", waiting, err)
+	}
+	if !meta.IsStatusConditionFalse(js.Status.Conditions, string(jobset.JobSetWaitingForCapacity)) {
+		t.Errorf("expected WaitingForCapacity condition to be cleared, got %v", js.Status.Conditions)
+	}
+}
+
+func TestHoldForQuota(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	// The JobSet runs 2 jobs with 2 pods each, requesting 1 CPU per pod.
+	js := testutils.MakeJobSet(jobSetName, ns).
+		SetAnnotations(map[string]string{jobset.SuspendOnQuotaExceededKey: "true"}).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).
+				Parallelism(2).
+				PodSpec(corev1.PodSpec{Containers: []corev1.Container{{
+					Name: "worker",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+				}}}).Obj()).
+			Replicas(2).
+			Obj()).Obj()
+	job := makeJob(&makeJobArgs{
+		jobSetName:        jobSetName,
+		replicatedJobName: "workers",
+		jobName:           "test-jobset-workers-0",
+		ns:                ns,
+		replicas:          2,
+		jobIdx:            0,
+	}).Suspend(false).Obj()
+	// Other workloads use 2 of the 4 CPUs of the quota.
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: ns},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4")},
+			Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("2")},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, job, quota).Build()
+	r := JobSetReconciler{Client: c, Record: record.NewFakeRecorder(10)}
+	ctx := context.TODO()
+
+	// Creating the remaining job is rejected by the quota, so the running job is suspended.
+	createErr := apierrors.NewForbidden(batchv1.Resource("jobs"), "test-jobset-workers-1", errors.New("exceeded quota: compute"))
+	if !quotaExceededError(createErr) {
+		t.Fatalf("expected %v to be detected as a quota error", createErr)
+	}
+	if err := r.newReconciliation(js).suspendForQuota(ctx, js, &childJobs{active: []*batchv1.Job{job}}, createErr.Error()); err != nil {
+		t.Fatalf("suspendForQuota() error = %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(job), job); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if !pointer.BoolDeref(job.Spec.Suspend, false) {
+		t.Errorf("expected job to be suspended")
+	}
+	if !meta.IsStatusConditionTrue(js.Status.Conditions, string(jobset.JobSetQuotaExceeded)) {
+		t.Errorf("expected QuotaExceeded condition, got %v", js.Status.Conditions)
+	}
+
+	// The 4 CPUs of the JobSet still do not fit in the 2 CPUs left, so the job stays suspended.
+	ownedJobs := &childJobs{active: []*batchv1.Job{job}}
+	if held, err := r.newReconciliation(js).holdForQuota(ctx, js, ownedJobs); err != nil || !held {
+		t.Fatalf("holdForQuota() = %v, %v, want held under quota pressure", held, err)
+	}
+
+	// The other workloads finish, so the JobSet fits in the quota and is no longer held.
+	quota.Status.Used = corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("0")}
+	if err := c.Update(ctx, quota); err != nil {
+		t.Fatalf("updating quota: %v", err)
+	}
+	if held, err := r.newReconciliation(js).holdForQuota(ctx, js, ownedJobs); err != nil || held {
+		t.Fatalf("holdForQuota() = %v, %v, want not held after quota release", held, err)
+	}
+	if !meta.IsStatusConditionFalse(js.Status.Conditions, string(jobset.JobSetQuotaExceeded)) {
+		t.Errorf("expected QuotaExceeded condition to be cleared, got %v", js.Status.Conditions)
+	}
+}
+
+func TestHoldForQuotaSkipped(t *testing.T) {
+	ns := "default"
+	makeJobSet := func(annotations map[string]string) *jobset.JobSet {
+		return testutils.MakeJobSet("test-jobset", ns).
+			SetAnnotations(annotations).
+			ReplicatedJob(testutils.MakeReplicatedJob("workers").
+				Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+				Replicas(2).
+				Obj()).Obj()
+	}
+	optIn := map[string]string{jobset.SuspendOnQuotaExceededKey: "true"}
+	// The quota has no room for any more pods.
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "pods", Namespace: ns},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")},
+			Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")},
+		},
+	}
+	runningJob := makeJob(&makeJobArgs{
+		jobSetName:        "test-jobset",
+		replicatedJobName: "workers",
+		jobName:           "test-jobset-workers-0",
+		ns:                ns,
+		replicas:          2,
+		jobIdx:            0,
+	}).Suspend(false).Obj()
+
+	tests := []struct {
+		name      string
+		js        *jobset.JobSet
+		ownedJobs *childJobs
+		wantHeld  bool
+	}{
+		{
+			name:      "not opted in",
+			js:        makeJobSet(nil),
+			ownedJobs: &childJobs{},
+		},
+		{
+			name:      "pod quota exceeded",
+			js:        makeJobSet(optIn),
+			ownedJobs: &childJobs{},
+			wantHeld:  true,
+		},
+		{
+			name:      "child jobs already running",
+			js:        makeJobSet(optIn),
+			ownedJobs: &childJobs{active: []*batchv1.Job{runningJob}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := JobSetReconciler{
+				Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(tc.js, quota).Build(),
+				Record: record.NewFakeRecorder(10),
+			}
+			held, err := r.newReconciliation(tc.js).holdForQuota(context.TODO(), tc.js, tc.ownedJobs)
+			if err != nil {
+				t.Fatalf("holdForQuota() error = %v", err)
+			}
+			if held != tc.wantHeld {
+				t.Errorf("holdForQuota() = %v, want %v", held, tc.wantHeld)
+			}
+		})
+	}
+}
+
+func TestSuspendedDuration(t *testing.T) {
+	js := testutils.MakeJobSet("test-jobset", "default").Obj()
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	ctx := context.TODO()
+
+	// suspendFor suspends the JobSet, backdates the suspension and resumes it again.
+	suspendFor := func(d time.Duration) {
+		t.Helper()
+		if err := r.newReconciliation(js).suspendJobSet(ctx, js, &childJobs{}); err != nil {
+			t.Fatalf("suspendJobSet() error = %v", err)
+		}
+		cond := meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetSuspended))
+		cond.LastTransitionTime = metav1.NewTime(time.Now().Add(-d))
+		if err := r.newReconciliation(js).resumeJobSetIfNecessary(ctx, js, &childJobs{}); err != nil {
+			t.Fatalf("resumeJobSetIfNecessary() error = %v", err)
+		}
+	}
+	wantSuspendedDuration := func(want time.Duration) {
+		t.Helper()
+		var got jobset.JobSet
+		if err := r.Get(ctx, client.ObjectKeyFromObject(js), &got); err != nil {
+			t.Fatalf("getting jobset: %v", err)
+		}
+		if got.Status.SuspendedDuration == nil {
+			t.Fatalf("expected suspended duration to be set")
+		}
+		if d := got.Status.SuspendedDuration.Duration; d < want || d > want+time.Minute {
+			t.Errorf("got suspended duration %v, want about %v", d, want)
+		}
+	}
+
+	suspendFor(10 * time.Minute)
+	wantSuspendedDuration(10 * time.Minute)
+
+	// Resuming a JobSet which is already running does not add to the duration.
+	if err := r.newReconciliation(js).resumeJobSetIfNecessary(ctx, js, &childJobs{}); err != nil {
+		t.Fatalf("resumeJobSetIfNecessary() error = %v", err)
+	}
+	wantSuspendedDuration(10 * time.Minute)
+
+	suspendFor(5 * time.Minute)
+	suspendFor(time.Hour)
+	wantSuspendedDuration(75 * time.Minute)
+}
+
+func TestQueueForConcurrencyKey(t *testing.T) {
+	ns := "default"
+	now := time.Now()
+	makeJobSet := func(name, key string, created time.Time) *jobset.JobSet {
+		js := testutils.MakeJobSet(name, ns).
+			SetAnnotations(map[string]string{jobset.ConcurrencyKeyKey: key}).Obj()
+		js.CreationTimestamp = metav1.NewTime(created)
+		return js
+	}
+	first := makeJobSet("first", "pipeline", now.Add(-3*time.Minute))
+	second := makeJobSet("second", "pipeline", now.Add(-2*time.Minute))
+	third := makeJobSet("third", "pipeline", now.Add(-time.Minute))
+	other := makeJobSet("other", "other-pipeline", now)
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(first, second, third, other).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	ctx := context.TODO()
+
+	wantQueued := func(want map[*jobset.JobSet]bool) {
+		t.Helper()
+		for js, wantQueued := range want {
+			queued, err := r.newReconciliation(js).queueForConcurrencyKey(ctx, js, &childJobs{})
+			if err != nil {
+				t.Fatalf("queueForConcurrencyKey(%s) error = %v", js.Name, err)
+			}
+			if queued != wantQueued {
+				t.Errorf("jobset %s: got queued %v, want %v", js.Name, queued, wantQueued)
+			}
+			if wantQueued && !meta.IsStatusConditionTrue(js.Status.Conditions, string(jobset.JobSetQueued)) {
+				t.Errorf("jobset %s: expected Queued condition, got %v", js.Name, js.Status.Conditions)
+			}
+		}
+	}
+
+	// Only the oldest JobSet sharing the key runs.
+	wantQueued(map[*jobset.JobSet]bool{first: false, second: true, third: true, other: false})
+
+	// The JobSets queued behind the first one are reconciled when it changes.
+	got := r.jobSetsSharingConcurrencyKey(first)
+	want := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: ns, Name: "second"}},
+		{NamespacedName: types.NamespacedName{Namespace: ns, Name: "third"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected requests (-want/+got): %s", diff)
+	}
+
+	// Once the first JobSet finishes, the next oldest one runs.
+	first.Status.Conditions = append(first.Status.Conditions, metav1.Condition{
+		Type:   string(jobset.JobSetCompleted),
+		Status: metav1.ConditionTrue,
+		Reason: "AllJobsCompleted",
+	})
+	if err := r.Status().Update(ctx, first); err != nil {
+		t.Fatalf("updating jobset status: %v", err)
+	}
+	wantQueued(map[*jobset.JobSet]bool{second: false, third: true})
+	if !meta.IsStatusConditionFalse(second.Status.Conditions, string(jobset.JobSetQueued)) {
+		t.Errorf("expected Queued condition of second jobset to be cleared, got %v", second.Status.Conditions)
+	}
+
+	// A suspended JobSet does not hold the key.
+	second.Spec.Suspend = pointer.Bool(true)
+	if err := r.Update(ctx, second); err != nil {
+		t.Fatalf("updating jobset: %v", err)
+	}
+	wantQueued(map[*jobset.JobSet]bool{third: false})
+	second.Spec.Suspend = nil
+	if err := r.Update(ctx, second); err != nil {
+		t.Fatalf("updating jobset: %v", err)
+	}
+
+	// Removing the annotation from a JobSet reconciles the JobSets sharing its previous key.
+	released := second.DeepCopy()
+	delete(released.Annotations, jobset.ConcurrencyKeyKey)
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+	handler.EnqueueRequestsFromMapFunc(r.jobSetsSharingConcurrencyKey).Update(event.UpdateEvent{ObjectOld: second, ObjectNew: released}, queue)
+	var requests []reconcile.Request
+	for queue.Len() > 0 {
+		item, _ := queue.Get()
+		requests = append(requests, item.(reconcile.Request))
+		queue.Done(item)
+	}
+	want = []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: ns, Name: "first"}},
+		{NamespacedName: types.NamespacedName{Namespace: ns, Name: "third"}},
+	}
+	if diff := cmp.Diff(want, requests, cmpopts.SortSlices(func(a, b reconcile.Request) bool { return a.Name < b.Name })); diff != "" {
+		t.Errorf("unexpected requests after removing the annotation (-want/+got): %s", diff)
+	}
+
+	// A JobSet which is already running is never queued.
+	running := &childJobs{active: []*batchv1.Job{makeJob(&makeJobArgs{
+		jobSetName:        "third",
+		replicatedJobName: "replicated-job",
+		jobName:           "third-replicated-job-0",
+		ns:                ns,
+		replicas:          1,
+		jobIdx:            0,
+	}).Obj()}}
+	if queued, err := r.newReconciliation(third).queueForConcurrencyKey(ctx, third, running); err != nil || queued {
+		t.Errorf("queueForConcurrencyKey() = %v, %v, want running jobset not to be queued", queued, err)
+	}
+}
+
+// failingListClient fails every List.
+type failingListClient struct {
+	client.Client
+}
+
+func (c *failingListClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return errors.New("list failed")
+}
+
+func TestJobSetsSharingConcurrencyKeyListError(t *testing.T) {
+	js := testutils.MakeJobSet("first", "default").
+		SetAnnotations(map[string]string{jobset.ConcurrencyKeyKey: "key"}).Obj()
+	var logged []string
+	r := JobSetReconciler{
+		Client: &failingListClient{Client: fake.NewClientBuilder().WithScheme(testScheme(t)).Build()},
+		log: funcr.New(func(prefix, args string) {
+			logged = append(logged, args)
+		}, funcr.Options{}),
+	}
+	if got := r.jobSetsSharingConcurrencyKey(js); got != nil {
+		t.Errorf("got requests %v, want none", got)
+	}
+	if len(logged) != 1 || !strings.Contains(logged[0], "list failed") {
+		t.Errorf("expected the list error to be logged, got %v", logged)
+	}
+}
+
+func TestUpdateReconcileErrorCondition(t *testing.T) {
+	js := testutils.MakeJobSet("test-jobset", "default").Obj()
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	ctx := context.TODO()
+
+	// A failed reconcile should set the condition with the error message, which is recorded
+	// verbatim in the event even if it contains format verbs.
+	firstErr := "first error: quota 100% used"
+	if err := r.newReconciliation(js).updateReconcileErrorCondition(ctx, js, errors.New(firstErr)); err != nil {
+		t.Fatalf("updateReconcileErrorCondition() error = %v", err)
+	}
+	condition := meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetReconcileError))
+	if condition == nil || condition.Status != metav1.ConditionTrue || condition.Message != firstErr {
+		t.Errorf("expected true %s condition with message %q, got: %v", jobset.JobSetReconcileError, firstErr, condition)
+	}
+	if got, want := <-r.Record.(*record.FakeRecorder).Events, "Warning ReconcileError "+firstErr; got != want {
+		t.Errorf("got event %q, want %q", got, want)
+	}
+
+	// A subsequent, different error should replace the message, bounded in length.
+	longErr := errors.New(strings.Repeat("x", maxReconcileErrorMessageLength+1))
+	if err := r.newReconciliation(js).updateReconcileErrorCondition(ctx, js, longErr); err != nil {
+		t.Fatalf("updateReconcileErrorCondition() error = %v", err)
+	}
+	condition = meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetReconcileError))
+	if condition == nil || len(condition.Message) != maxReconcileErrorMessageLength || !strings.HasSuffix(condition.Message, "...") {
+		t.Errorf("expected %s condition message truncated to %d characters, got: %v", jobset.JobSetReconcileError, maxReconcileErrorMessageLength, condition)
+	}
+
+	// A conflict leaves the condition as is, and records no event.
+	message := condition.Message
+	conflict := apierrors.NewConflict(jobset.GroupVersion.WithResource("jobsets").GroupResource(), js.Name, errors.New("object has been modified"))
+	if err := r.newReconciliation(js).updateReconcileErrorCondition(ctx, js, fmt.Errorf("updating job: %w", conflict)); err != nil {
+		t.Fatalf("updateReconcileErrorCondition() error = %v", err)
+	}
+	condition = meta.FindStatusCondition(js.Status.Conditions, string(jobset.JobSetReconcileError))
+	if condition == nil || condition.Message != message {
+		t.Errorf("expected %s condition to keep message %q after a conflict, got: %v", jobset.JobSetReconcileError, message, condition)
+	}
+	if events := len(r.Record.(*record.FakeRecorder).Events); events != 1 {
+		t.Errorf("got %d events, want 1 for the error before the conflict", events)
+	}
+
+	// A successful reconcile should clear the condition.
+	if err := r.newReconciliation(js).updateReconcileErrorCondition(ctx, js, nil); err != nil {
+		t.Fatalf("updateReconcileErrorCondition() error = %v", err)
+	}
+	if !meta.IsStatusConditionFalse(js.Status.Conditions, string(jobset.JobSetReconcileError)) {
+		t.Errorf("expected %s condition to be false, got conditions: %v", jobset.JobSetReconcileError, js.Status.Conditions)
+	}
+}
+
+func TestTruncateMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{name: "short", message: "abcdef", want: "abcdef"},
+		{name: "long", message: "abcdefghij", want: "abcde..."},
+		// "é" is encoded in two bytes, the second of which would be cut.
+		{name: "multi-byte rune at the cut", message: "abcdéfghij", want: "abcd..."},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := truncateMessage(tc.message, 8)
+			if got != tc.want {
+				t.Errorf("truncateMessage(%q) = %q, want %q", tc.message, got, tc.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateMessage(%q) = %q is not valid UTF-8", tc.message, got)
+			}
+		})
+	}
+}
+
+func TestMigrateChildLabels(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	js := testutils.MakeJobSet(jobSetName, ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+			Job(testutils.MakeJobTemplate("job", ns).Obj()).
+			Replicas(2).
+			Obj()).
+		Obj()
+	js.UID = "test-uid"
+	js.Status.Restarts = 1
+	ownerRef := *metav1.NewControllerRef(js, jobset.GroupVersion.WithKind("JobSet"))
+
+	// Children created with an older label schema, from the previous restart attempt.
+	oldLabels := map[string]string{"jobset-name": jobSetName, RestartsKey: "0"}
+	oldJob := testutils.MakeJob("test-jobset-replicated-job-1", ns).JobLabels(oldLabels).Obj()
+	oldJob.OwnerReferences = []metav1.OwnerReference{ownerRef}
+	oldPod := testutils.MakePod("test-jobset-replicated-job-1-0-abcde", ns).
+		Labels(map[string]string{jobset.JobNameKey: oldJob.Name, RestartsKey: "0"}).Obj()
+
+	// Children already using the current label schema must not be rewritten.
+	currentJob := makeJob(&makeJobArgs{
+		jobSetName:        jobSetName,
+		replicatedJobName: "replicated-job",
+		jobName:           "test-jobset-replicated-job-0",
+		ns:                ns,
+		replicas:          2,
+		restarts:          1,
+	}).Obj()
+	currentJob.OwnerReferences = []metav1.OwnerReference{ownerRef}
+
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().
+			WithScheme(testScheme(t)).
+			WithIndex(&batchv1.Job{}, jobOwnerKey, indexJobOwner).
+			WithObjects(js, oldJob, oldPod, currentJob).
+			Build(),
+	}
+	ctx := context.TODO()
+	var before batchv1.Job
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: currentJob.Name}, &before); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+
+	if err := r.migrateChildLabels(ctx, js); err != nil {
+		t.Fatalf("migrateChildLabels() error = %v", err)
+	}
+
+	wantLabels := map[string]string{
+		"jobset-name":                jobSetName,
+		jobset.JobSetNameKey:         jobSetName,
+		jobset.ReplicatedJobNameKey:  "replicated-job",
+		jobset.ReplicatedJobReplicas: "2",
+		jobset.JobIndexKey:           "1",
+		RestartsKey:                  "0",
+		LabelSchemaVersionKey:        labelSchemaVersion,
+	}
+	var job batchv1.Job
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: oldJob.Name}, &job); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if diff := cmp.Diff(wantLabels, job.Labels); diff != "" {
+		t.Errorf("unexpected job labels (-want +got):\n%s", diff)
+	}
+
+	var pod corev1.Pod
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: oldPod.Name}, &pod); err != nil {
+		t.Fatalf("getting pod: %v", err)
+	}
+	delete(wantLabels, "jobset-name")
+	wantLabels[jobset.JobNameKey] = oldJob.Name
+	if diff := cmp.Diff(wantLabels, pod.Labels); diff != "" {
+		t.Errorf("unexpected pod labels (-want +got):\n%s", diff)
+	}
+
+	var after batchv1.Job
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: currentJob.Name}, &after); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if after.ResourceVersion != before.ResourceVersion {
+		t.Errorf("job with the current label schema was updated, resourceVersion %s -> %s", before.ResourceVersion, after.ResourceVersion)
+	}
+}
+
+func TestGetChildJobsOfRemovedReplicatedJobs(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	// The JobSet is at generation 2, after replicated-job-b was removed from its spec.
+	js := testutils.MakeJobSet(jobSetName, ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("replicated-job-a").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(1).
+			Obj()).Obj()
+	js.UID = "test-uid"
+	js.Generation = 2
+	makeChildJob := func(replicatedJobName string, generation int64) *batchv1.Job {
+		job := makeJob(&makeJobArgs{
+			jobSetName:        jobSetName,
+			replicatedJobName: replicatedJobName,
+			jobName:           fmt.Sprintf("test-jobset-%s-0", replicatedJobName),
+			ns:                ns,
+			replicas:          1,
+			generation:        generation,
+		}).Obj()
+		job.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(js, jobset.GroupVersion.WithKind("JobSet"))}
+		return job
+	}
+	removedJob := makeChildJob("replicated-job-b", 1)
+	// A job of the current generation naming an unknown replicated job is left alone.
+	unknownJob := makeChildJob("replicated-job-c", 2)
+
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().
+			WithScheme(testScheme(t)).
+			WithIndex(&batchv1.Job{}, jobOwnerKey, indexJobOwner).
+			WithObjects(js, makeChildJob("replicated-job-a", 1), removedJob, unknownJob).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	ownedJobs, err := r.getChildJobs(context.TODO(), js)
+	if err != nil {
+		t.Fatalf("getChildJobs() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"test-jobset-replicated-job-a-0"}, jobNames(ownedJobs.active)); diff != "" {
+		t.Errorf("unexpected active jobs (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"test-jobset-replicated-job-b-0"}, jobNames(ownedJobs.removed)); diff != "" {
+		t.Errorf("unexpected removed jobs (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetChildJobsFailureLevel(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	tests := []struct {
+		name       string
+		level      jobset.FailureLevel
+		wantActive []string
+		wantFailed []string
+	}{
+		{
+			name:       "job level ignores failed pods of running jobs",
+			level:      jobset.FailureLevelJob,
+			wantActive: []string{"test-jobset-replicated-job-0", "test-jobset-replicated-job-1"},
+			wantFailed: []string{"test-jobset-replicated-job-2"},
+		},
+		{
+			name:       "pod level counts failed pods of running jobs",
+			level:      jobset.FailureLevelPod,
+			wantActive: []string{"test-jobset-replicated-job-0"},
+			wantFailed: []string{"test-jobset-replicated-job-1", "test-jobset-replicated-job-2"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			js := testutils.MakeJobSet(jobSetName, ns).
+				FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 1, Level: tc.level}).
+				ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					Replicas(3).
+					Obj()).Obj()
+			js.UID = "test-uid"
+			makeChildJob := func(jobName string) *testutils.JobWrapper {
+				job := makeJob(&makeJobArgs{
+					jobSetName:        jobSetName,
+					replicatedJobName: "replicated-job",
+					jobName:           jobName,
+					ns:                ns,
+					replicas:          3,
+				})
+				job.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(js, jobset.GroupVersion.WithKind("JobSet"))}
+				return job
+			}
+			failedJob := makeChildJob("test-jobset-replicated-job-2").Failed(1).Obj()
+			failedJob.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+
+			r := JobSetReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(testScheme(t)).
+					WithIndex(&batchv1.Job{}, jobOwnerKey, indexJobOwner).
+					WithObjects(
+						makeChildJob("test-jobset-replicated-job-0").Obj(),
+						makeChildJob("test-jobset-replicated-job-1").Failed(1).Obj(),
+						failedJob,
+					).Build(),
+			}
+			ownedJobs, err := r.getChildJobs(context.TODO(), js)
+			if err != nil {
+				t.Fatalf("getChildJobs() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.wantActive, jobNames(ownedJobs.active)); diff != "" {
+				t.Errorf("unexpected active jobs (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantFailed, jobNames(ownedJobs.failed)); diff != "" {
+				t.Errorf("unexpected failed jobs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func jobNames(jobs []*batchv1.Job) []string {
+	var names []string
+	for _, job := range jobs {
+		names = append(names, job.Name)
+	}
+	return names
+}
+
+func TestSetIndexNodeAffinity(t *testing.T) {
+	gpuRequirement := corev1.NodeSelectorRequirement{Key: "gpu", Operator: corev1.NodeSelectorOpExists}
+	zoneRequirement := corev1.NodeSelectorRequirement{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-b"}}
+	podSpec := corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{gpuRequirement}},
+				{MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-1"}}}},
+			},
+		},
+	}}}
+
+	setIndexNodeAffinity(&podSpec, &jobset.IndexNodeAffinity{Key: "topology.kubernetes.io/zone", Values: []string{"zone-a", "zone-b"}}, 3)
+
+	// The zone requirement is added to every existing term.
+	want := []corev1.NodeSelectorTerm{
+		{MatchExpressions: []corev1.NodeSelectorRequirement{gpuRequirement, zoneRequirement}},
+		{
+			MatchExpressions: []corev1.NodeSelectorRequirement{zoneRequirement},
+			MatchFields:      []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-1"}}},
+		},
+	}
+	if diff := cmp.Diff(want, podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms); diff != "" {
+		t.Errorf("unexpected node selector terms (-want/+got): %s", diff)
+	}
+}
+
+func TestConstructJobPodAnnotations(t *testing.T) {
+	ns := "default"
+	template := testutils.MakeJobTemplate("test-job", ns).Obj()
+	template.Spec.Template.Annotations = map[string]string{"team": "infra"}
+	js := testutils.MakeJobSet("test-jobset", ns).
+		PodAnnotations(map[string]string{
+			"sidecar.istio.io/inject": "true",
+			"team":                    "ml",
+		}).
+		ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+			Job(template).
+			Replicas(1).
+			Obj()).Obj()
+
+	job, err := constructJob(js, &js.Spec.ReplicatedJobs[0], 0)
+	if err != nil {
+		t.Fatalf("constructJob() error = %v", err)
+	}
+	// The annotations are only propagated to the pods, with the template taking precedence.
+	for key, want := range map[string]string{
+		"sidecar.istio.io/inject":   "true",
+		"team":                      "infra",
+		jobset.ReplicatedJobNameKey: "replicated-job",
+	} {
+		if got := job.Spec.Template.Annotations[key]; got != want {
+			t.Errorf("pod template annotation %s: got %q, want %q", key, got, want)
+		}
+	}
+	if _, ok := job.Annotations["sidecar.istio.io/inject"]; ok {
+		t.Errorf("expected pod annotations not to be propagated to the job, got %v", job.Annotations)
+	}
+	// The pod template of the JobSet spec is left untouched.
+	if _, ok := js.Spec.ReplicatedJobs[0].Template.Spec.Template.Annotations["sidecar.istio.io/inject"]; ok {
+		t.Errorf("expected replicated job template not to be modified")
+	}
+}
+
+func TestConstructJobPropagatedMetadata(t *testing.T) {
+	ns := "default"
+	template := testutils.MakeJobTemplate("test-job", ns).Obj()
+	template.Labels = map[string]string{"team": "infra"}
+	template.Spec.Template.Labels = map[string]string{"team": "infra"}
+	js := testutils.MakeJobSet("test-jobset", ns).
+		SetAnnotations(map[string]string{"owner": "alice", "unpropagated": "true"}).
+		PropagatedLabels("cost-center", "team", "missing", jobset.ReplicatedJobNameKey).
+		PropagatedAnnotations("owner").
+		ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+			Job(template).
+			Replicas(1).
+			Obj()).Obj()
+	js.Labels = map[string]string{
+		"cost-center":               "ml-research",
+		"team":                      "ml",
+		jobset.ReplicatedJobNameKey: "other",
+	}
+
+	job, err := constructJob(js, &js.Spec.ReplicatedJobs[0], 0)
+	if err != nil {
+		t.Fatalf("constructJob() error = %v", err)
+	}
+	// The selected labels and annotations are propagated to both the job and its pods,
+	// with the templates and the JobSet labels taking precedence.
+	for name, obj := range map[string]metav1.Object{"job": job, "pod template": &job.Spec.Template} {
+		for key, want := range map[string]string{
+			"cost-center":               "ml-research",
+			"team":                      "infra",
+			jobset.ReplicatedJobNameKey: "replicated-job",
+		} {
+			if got := obj.GetLabels()[key]; got != want {
+				t.Errorf("%s label %s: got %q, want %q", name, key, got, want)
+			}
+		}
+		if _, ok := obj.GetLabels()["missing"]; ok {
+			t.Errorf("expected %s not to have label missing from the jobset", name)
+		}
+		if got := obj.GetAnnotations()["owner"]; got != "alice" {
+			t.Errorf("%s annotation owner: got %q, want %q", name, got, "alice")
+		}
+		if _, ok := obj.GetAnnotations()["unpropagated"]; ok {
+			t.Errorf("expected %s not to have unselected annotation", name)
+		}
+	}
+}
+
+func TestConstructJobTotalPodsEnv(t *testing.T) {
+	ns := "default"
+	workers := testutils.MakeJobTemplate("test-job", ns).
+		PodSpec(corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init"}},
+			Containers:     []corev1.Container{{Name: "worker"}},
+		}).
+		Parallelism(3).Obj()
+	driver := testutils.MakeJobTemplate("test-job", ns).
+		PodSpec(corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "driver", Env: []corev1.EnvVar{{Name: "WORLD_SIZE", Value: "1"}}}},
+		}).Obj()
+	js := testutils.MakeJobSet("test-jobset", ns).
+		TotalPodsEnvName("WORLD_SIZE").
+		ParallelismOverrides(map[string]int32{"driver": 4}).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(workers).
+			Replicas(2).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("driver").
+			Job(driver).
+			Replicas(1).
+			Obj()).Obj()
+
+	// 2 worker jobs with 3 pods each, and 1 driver job with 4 pods.
+	wantEnv := []corev1.EnvVar{{Name: "WORLD_SIZE", Value: "10"}}
+	for restarts := 0; restarts < 2; restarts++ {
+		js.Status.Restarts = restarts
+		job, err := constructJob(js, &js.Spec.ReplicatedJobs[0], 1)
+		if err != nil {
+			t.Fatalf("constructJob() error = %v", err)
+		}
+		podSpec := job.Spec.Template.Spec
+		for _, c := range append(podSpec.InitContainers, podSpec.Containers...) {
+			if diff := cmp.Diff(wantEnv, c.Env); diff != "" {
+				t.Errorf("restart %d: unexpected env of container %s (-want/+got): %s", restarts, c.Name, diff)
+			}
+		}
+	}
+
+	// Variables set by the container take precedence.
+	job, err := constructJob(js, &js.Spec.ReplicatedJobs[1], 0)
+	if err != nil {
+		t.Fatalf("constructJob() error = %v", err)
+	}
+	if diff := cmp.Diff([]corev1.EnvVar{{Name: "WORLD_SIZE", Value: "1"}}, job.Spec.Template.Spec.Containers[0].Env); diff != "" {
+		t.Errorf("unexpected env of driver container (-want/+got): %s", diff)
+	}
+	// The pod template of the JobSet spec is left untouched.
+	if env := js.Spec.ReplicatedJobs[0].Template.Spec.Template.Spec.Containers[0].Env; len(env) != 0 {
+		t.Errorf("expected replicated job template not to be modified, got env %v", env)
+	}
+}
+
+func TestConstructJobCoordinatorEnv(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		Coordinator("driver", 1).
+		ReplicatedJob(testutils.MakeReplicatedJob("driver").
+			Job(testutils.MakeJobTemplate("test-job", ns).
+				PodSpec(corev1.PodSpec{Containers: []corev1.Container{{Name: "driver"}}}).Obj()).
+			EnableDNSHostnames(true).
+			Replicas(2).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).
+				PodSpec(corev1.PodSpec{
+					InitContainers: []corev1.Container{{Name: "init"}},
+					Containers:     []corev1.Container{{Name: "worker"}},
+				}).Obj()).
+			Replicas(3).
+			Obj()).Obj()
+
+	wantEnv := []corev1.EnvVar{{Name: jobset.CoordinatorEnvName, Value: "test-jobset-driver-1-0.test-jobset"}}
+	for i := range js.Spec.ReplicatedJobs {
+		job, err := constructJob(js, &js.Spec.ReplicatedJobs[i], 0)
+		if err != nil {
+			t.Fatalf("constructJob() error = %v", err)
+		}
+		podSpec := job.Spec.Template.Spec
+		for _, c := range append(podSpec.InitContainers, podSpec.Containers...) {
+			if diff := cmp.Diff(wantEnv, c.Env); diff != "" {
+				t.Errorf("unexpected env of container %s (-want/+got): %s", c.Name, diff)
+			}
+		}
+	}
+}
+
+func TestInvalidCoordinator(t *testing.T) {
+	ns := "default"
+	makeJobSet := func() *jobset.JobSet {
+		return testutils.MakeJobSet("test-jobset", ns).
+			Coordinator("driver", 1).
+			ReplicatedJob(testutils.MakeReplicatedJob("driver").
+				Job(testutils.MakeJobTemplate("test-job", ns).
+					Completions(1).
+					PodSpec(corev1.PodSpec{Containers: []corev1.Container{{Name: "driver"}}}).Obj()).
+				EnableDNSHostnames(true).
+				Replicas(2).
+				Obj()).Obj()
+	}
+	tests := []struct {
+		name          string
+		update        func(js *jobset.JobSet)
+		wantCondition bool
+	}{
+		{
+			name:   "valid coordinator",
+			update: func(js *jobset.JobSet) {},
+		},
+		{
+			name:          "replicas reduced below the coordinator job index",
+			update:        func(js *jobset.JobSet) { js.Spec.ReplicatedJobs[0].Replicas = 1 },
+			wantCondition: true,
+		},
+		{
+			name:          "completions reduced below the coordinator pod index",
+			update:        func(js *jobset.JobSet) { js.Spec.ReplicatedJobs[0].Template.Spec.Completions = pointer.Int32(0) },
+			wantCondition: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			js := makeJobSet()
+			tc.update(js)
+			r := JobSetReconciler{
+				Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js).Build(),
+				Record: record.NewFakeRecorder(10),
+			}
+			if err := r.newReconciliation(js).updateInvalidCoordinatorCondition(context.TODO(), js); err != nil {
+				t.Fatalf("updateInvalidCoordinatorCondition() error = %v", err)
+			}
+			got := meta.IsStatusConditionTrue(js.Status.Conditions, string(jobset.JobSetInvalidCoordinator))
+			if got != tc.wantCondition {
+				t.Errorf("got condition %v, want %v", got, tc.wantCondition)
+			}
+			// The hostname of a pod which does not exist is never injected.
+			job, err := constructJob(js, &js.Spec.ReplicatedJobs[0], 0)
+			if err != nil {
+				t.Fatalf("constructJob() error = %v", err)
+			}
+			injected := hasEnvVar(&job.Spec.Template.Spec.Containers[0], jobset.CoordinatorEnvName)
+			if injected == tc.wantCondition {
+				t.Errorf("got coordinator env injected %v, want %v", injected, !tc.wantCondition)
+			}
+		})
+	}
+}
+
+func TestJobDefaults(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		JobDefaults(&jobset.JobDefaults{Completions: pointer.Int32(4), Parallelism: pointer.Int32(2)}).
+		ParallelismOverrides(map[string]int32{"overridden": 8}).
+		ReplicatedJob(testutils.MakeReplicatedJob("defaulted").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(2).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("explicit").
+			Job(testutils.MakeJobTemplate("test-job", ns).Completions(1).Parallelism(1).Obj()).
+			Replicas(1).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("overridden").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(1).
+			Obj()).Obj()
+
+	want := map[string]struct{ completions, parallelism int32 }{
+		"defaulted":  {completions: 4, parallelism: 2},
+		"explicit":   {completions: 1, parallelism: 1},
+		"overridden": {completions: 4, parallelism: 8},
+	}
+	for i := range js.Spec.ReplicatedJobs {
+		rjob := &js.Spec.ReplicatedJobs[i]
+		job, err := constructJob(js, rjob, 0)
+		if err != nil {
+			t.Fatalf("constructJob() error = %v", err)
+		}
+		w := want[rjob.Name]
+		if got := pointer.Int32Deref(job.Spec.Completions, 0); got != w.completions {
+			t.Errorf("got completions %d for replicatedJob %s, want %d", got, rjob.Name, w.completions)
+		}
+		if got := pointer.Int32Deref(job.Spec.Parallelism, 0); got != w.parallelism {
+			t.Errorf("got parallelism %d for replicatedJob %s, want %d", got, rjob.Name, w.parallelism)
+		}
+		if got := jobParallelism(js, rjob); got != w.parallelism {
+			t.Errorf("got jobParallelism() %d for replicatedJob %s, want %d", got, rjob.Name, w.parallelism)
+		}
+	}
+	// The template is left untouched, the defaults only apply to the child jobs.
+	if js.Spec.ReplicatedJobs[0].Template.Spec.Completions != nil {
+		t.Errorf("unexpected completions set on the template of replicatedJob defaulted")
+	}
+}
+
+func TestGangScheduling(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		SchedulingPolicy(&jobset.SchedulingPolicy{Gang: &jobset.GangPolicy{}}).
+		ReplicatedJob(testutils.MakeReplicatedJob("driver").
+			Job(testutils.MakeJobTemplate("test-job", ns).Completions(1).Parallelism(1).Obj()).
+			Replicas(1).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Completions(4).Parallelism(4).Obj()).
+			Replicas(2).
+			Obj()).
+		// Only two pods run at once, since the job needs two completions.
+		ReplicatedJob(testutils.MakeReplicatedJob("evaluators").
+			Job(testutils.MakeJobTemplate("test-job", ns).Completions(2).Parallelism(4).Obj()).
+			Replicas(1).
+			Obj()).Obj()
+
+	for i := range js.Spec.ReplicatedJobs {
+		job, err := constructJob(js, &js.Spec.ReplicatedJobs[i], 0)
+		if err != nil {
+			t.Fatalf("constructJob() error = %v", err)
+		}
+		annotations := job.Spec.Template.Annotations
+		if got := annotations[jobset.PodGroupNameKey]; got != "test-jobset" {
+			t.Errorf("got pod group name %q, want %q", got, "test-jobset")
+		}
+		if got := annotations[jobset.PodGroupMinMemberKey]; got != "11" {
+			t.Errorf("got pod group min member %q, want %q", got, "11")
+		}
+		if _, ok := job.Annotations[jobset.PodGroupNameKey]; ok {
+			t.Errorf("unexpected pod group annotation on job %s", job.Name)
+		}
+	}
+
+	js.Spec.SchedulingPolicy.Gang.PodGroupName = "custom"
+	job, err := constructJob(js, &js.Spec.ReplicatedJobs[0], 0)
+	if err != nil {
+		t.Fatalf("constructJob() error = %v", err)
+	}
+	if got := job.Spec.Template.Annotations[jobset.PodGroupNameKey]; got != "custom" {
+		t.Errorf("got pod group name %q, want %q", got, "custom")
+	}
+}
+
+func TestExpectedPodHostnames(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("driver").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			EnableDNSHostnames(true).
+			Replicas(1).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Completions(2).Obj()).
+			EnableDNSHostnames(true).
+			Subdomain("custom").
+			Replicas(2).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("sidecar").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			EnableDNSHostnames(false).
+			Replicas(2).
+			Obj()).Obj()
+
+	want := []string{
+		"test-jobset-driver-0-0.test-jobset",
+		"test-jobset-workers-0-0.custom",
+		"test-jobset-workers-0-1.custom",
+		"test-jobset-workers-1-0.custom",
+		"test-jobset-workers-1-1.custom",
+	}
+	if diff := cmp.Diff(want, ExpectedPodHostnames(js)); diff != "" {
+		t.Errorf("unexpected pod hostnames (-want/+got): %s", diff)
+	}
+}
+
+func TestGenJobName(t *testing.T) {
+	ns := "default"
+	makeJobSet := func() *testutils.JobSetWrapper {
+		return testutils.MakeJobSet("test-jobset", ns).
+			ReplicatedJob(testutils.MakeReplicatedJob("workers").
+				Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+				Replicas(2).
+				Obj())
+	}
+	tests := []struct {
+		name          string
+		js            *jobset.JobSet
+		want          string
+		wantSubdomain string
+	}{
+		{
+			name:          "default format",
+			js:            makeJobSet().Obj(),
+			want:          "test-jobset-workers-1",
+			wantSubdomain: "test-jobset",
+		},
+		{
+			name:          "prefix and suffix",
+			js:            makeJobSet().JobNaming(&jobset.JobNaming{Prefix: "team-a-", Suffix: "-train"}).Obj(),
+			want:          "team-a-test-jobset-workers-1-train",
+			wantSubdomain: "test-jobset",
+		},
+		{
+			name: "blue-green restart attempt follows the suffix",
+			js: func() *jobset.JobSet {
+				js := makeJobSet().JobNaming(&jobset.JobNaming{Suffix: "-train"}).
+					FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 3, RestartStrategy: jobset.RestartStrategyBlueGreen}).Obj()
+				js.Status.Restarts = 2
+				return js
+			}(),
+			want:          "test-jobset-workers-1-train-r2",
+			wantSubdomain: "test-jobset",
+		},
+		{
+			name: "recreate restart attempt keeps the name",
+			js: func() *jobset.JobSet {
+				js := makeJobSet().FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 3}).Obj()
+				js.Status.Restarts = 2
+				return js
+			}(),
+			want:          "test-jobset-workers-1",
+			wantSubdomain: "test-jobset",
+		},
+		{
+			name: "separator",
+			js: func() *jobset.JobSet {
+				js := makeJobSet().JobNaming(&jobset.JobNaming{Separator: "--"}).
+					FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 3, RestartStrategy: jobset.RestartStrategyBlueGreen}).Obj()
+				js.Status.Restarts = 2
+				return js
+			}(),
+			want:          "test-jobset--workers--1--r2",
+			wantSubdomain: "test-jobset",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rjob := &tc.js.Spec.ReplicatedJobs[0]
+			got := GenJobName(tc.js, rjob, 1)
+			if got != tc.want {
+				t.Errorf("got job name %q, want %q", got, tc.want)
+			}
+			if errs := validation.IsDNS1123Label(got); len(errs) > 0 {
+				t.Errorf("got invalid job name %q: %v", got, errs)
+			}
+			if subdomain := GenSubdomain(tc.js, rjob); subdomain != tc.wantSubdomain {
+				t.Errorf("got subdomain %q, want %q", subdomain, tc.wantSubdomain)
+			}
+		})
+	}
+}
+
+func TestCustomSubdomain(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			EnableDNSHostnames(true).
+			Subdomain("custom").
+			Replicas(1).
+			Obj()).Obj()
+	scheme := testScheme(t)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(js).Build()
+	r := JobSetReconciler{Client: c, Scheme: scheme, Record: record.NewFakeRecorder(10)}
+	if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+
+	var svc corev1.Service
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "custom", Namespace: ns}, &svc); err != nil {
+		t.Fatalf("getting headless service: %v", err)
+	}
+	var job batchv1.Job
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "test-jobset-workers-0", Namespace: ns}, &job); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if got := job.Spec.Template.Spec.Subdomain; got != "custom" {
+		t.Errorf("got pod subdomain %q, want %q", got, "custom")
+	}
+}
+
+func TestHeadlessSvcOfAnotherOwner(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			EnableDNSHostnames(true).
+			Subdomain("custom").
+			Replicas(1).
+			Obj()).Obj()
+	js.UID = "jobset-uid"
+	// The service of another JobSet using the same custom subdomain.
+	other := testutils.MakeJobSet("other-jobset", ns).Obj()
+	other.UID = "other-uid"
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "custom",
+			Namespace:       ns,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(other, jobset.GroupVersion.WithKind("JobSet"))},
+		},
+	}
+	scheme := testScheme(t)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(js, svc).Build()
+	r := JobSetReconciler{Client: c, Scheme: scheme, Record: record.NewFakeRecorder(10)}
+	err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{})
+	if wantErr := "headless service custom of replicatedJob workers already exists and is not owned by the jobset"; err == nil || err.Error() != wantErr {
+		t.Fatalf("createJobs() error = %v, want %q", err, wantErr)
+	}
+}
+
+func TestHeadlessSvcLabeled(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			EnableDNSHostnames(true).
+			Replicas(1).
+			Obj()).Obj()
+	js.UID = "jobset-uid"
+	// A service created by an earlier version without the JobSet name label.
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-jobset",
+			Namespace:       ns,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(js, jobset.GroupVersion.WithKind("JobSet"))},
+		},
+	}
+	scheme := testScheme(t)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(js, svc).Build()
+	r := JobSetReconciler{Client: c, Scheme: scheme, Record: record.NewFakeRecorder(10)}
+	if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(svc), svc); err != nil {
+		t.Fatalf("getting headless service: %v", err)
+	}
+	if got := svc.Labels[jobset.JobSetNameKey]; got != js.Name {
+		t.Errorf("got label %s=%q on headless service, want %q", jobset.JobSetNameKey, got, js.Name)
+	}
+}
+
+func TestHeadlessSvcSelector(t *testing.T) {
+	ns := "default"
+	testCases := []struct {
+		name         string
+		rjob         *testutils.ReplicatedJobWrapper
+		wantSvc      string
+		wantSelector map[string]string
+	}{
+		{
+			name:    "shared service of the default subdomain",
+			rjob:    testutils.MakeReplicatedJob("workers"),
+			wantSvc: "test-jobset",
+			wantSelector: map[string]string{
+				jobset.JobSetNameKey: "test-jobset",
+			},
+		},
+		{
+			name:    "custom subdomain",
+			rjob:    testutils.MakeReplicatedJob("workers").Subdomain("custom"),
+			wantSvc: "custom",
+			wantSelector: map[string]string{
+				jobset.JobSetNameKey:        "test-jobset",
+				jobset.ReplicatedJobNameKey: "workers",
+			},
+		},
+		{
+			name:    "custom selector",
+			rjob:    testutils.MakeReplicatedJob("workers").Subdomain("custom").ServiceSelector(map[string]string{jobset.JobIndexKey: "0"}),
+			wantSvc: "custom",
+			wantSelector: map[string]string{
+				jobset.JobSetNameKey:        "test-jobset",
+				jobset.ReplicatedJobNameKey: "workers",
+				jobset.JobIndexKey:          "0",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			js := testutils.MakeJobSet("test-jobset", ns).
+				ReplicatedJob(tc.rjob.
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					EnableDNSHostnames(true).
+					Replicas(2).
+					Obj()).Obj()
+			scheme := testScheme(t)
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(js).Build()
+			r := JobSetReconciler{Client: c, Scheme: scheme, Record: record.NewFakeRecorder(10)}
+			if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{}); err != nil {
+				t.Fatalf("createJobs() error = %v", err)
+			}
+
+			var svc corev1.Service
+			if err := c.Get(context.TODO(), types.NamespacedName{Name: tc.wantSvc, Namespace: ns}, &svc); err != nil {
+				t.Fatalf("getting headless service: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantSelector, svc.Spec.Selector); diff != "" {
+				t.Errorf("unexpected service selector (-want/+got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestHeadlessSvcPublishNotReadyAddresses(t *testing.T) {
+	ns := "default"
+	testCases := []struct {
+		name string
+		rjob *testutils.ReplicatedJobWrapper
+		want bool
+	}{
+		{
+			name: "published by default",
+			rjob: testutils.MakeReplicatedJob("workers"),
+			want: true,
+		},
+		{
+			name: "disabled",
+			rjob: testutils.MakeReplicatedJob("workers").Subdomain("custom").PublishNotReadyAddresses(false),
+			want: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			js := testutils.MakeJobSet("test-jobset", ns).
+				ReplicatedJob(tc.rjob.
+					Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+					EnableDNSHostnames(true).
+					Replicas(1).
+					Obj()).Obj()
+			scheme := testScheme(t)
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(js).Build()
+			r := JobSetReconciler{Client: c, Scheme: scheme, Record: record.NewFakeRecorder(10)}
+			if err := r.newReconciliation(js).createJobs(context.TODO(), js, &childJobs{}); err != nil {
+				t.Fatalf("createJobs() error = %v", err)
+			}
+
+			var svc corev1.Service
+			if err := c.Get(context.TODO(), types.NamespacedName{Name: GenSubdomain(js, &js.Spec.ReplicatedJobs[0]), Namespace: ns}, &svc); err != nil {
+				t.Fatalf("getting headless service: %v", err)
+			}
+			if got := svc.Spec.PublishNotReadyAddresses; got != tc.want {
+				t.Errorf("got publishNotReadyAddresses %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConstructJobDNS(t *testing.T) {
+	ns := "default"
+	dnsConfig := &corev1.PodDNSConfig{Searches: []string{"example.com"}}
+	templateDNSConfig := &corev1.PodDNSConfig{Nameservers: []string{"1.2.3.4"}}
+	js := testutils.MakeJobSet("test-jobset", ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("inherited").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			DNSPolicy(corev1.DNSNone).
+			DNSConfig(dnsConfig).
+			Replicas(1).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("explicit").
+			Job(testutils.MakeJobTemplate("test-job", ns).
+				PodSpec(corev1.PodSpec{DNSPolicy: corev1.DNSDefault, DNSConfig: templateDNSConfig}).
+				Obj()).
+			DNSPolicy(corev1.DNSNone).
+			DNSConfig(dnsConfig).
+			Replicas(1).
+			Obj()).Obj()
+
+	want := map[string]struct {
+		policy corev1.DNSPolicy
+		config *corev1.PodDNSConfig
+	}{
+		"inherited": {policy: corev1.DNSNone, config: dnsConfig},
+		"explicit":  {policy: corev1.DNSDefault, config: templateDNSConfig},
+	}
+	for i := range js.Spec.ReplicatedJobs {
+		rjob := &js.Spec.ReplicatedJobs[i]
+		job, err := constructJob(js, rjob, 0)
+		if err != nil {
+			t.Fatalf("constructJob() error = %v", err)
+		}
+		w := want[rjob.Name]
+		if got := job.Spec.Template.Spec.DNSPolicy; got != w.policy {
+			t.Errorf("got DNS policy %q for replicatedJob %s, want %q", got, rjob.Name, w.policy)
+		}
+		if diff := cmp.Diff(w.config, job.Spec.Template.Spec.DNSConfig); diff != "" {
+			t.Errorf("unexpected DNS config for replicatedJob %s (-want/+got): %s", rjob.Name, diff)
+		}
+	}
+}
+
+func TestGenerationLabel(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		Suspend(true).
+		ReplicatedJob(testutils.MakeReplicatedJob("replicated-job").
+			Job(testutils.MakeJobTemplate("test-job", ns).
+				PodSpec(corev1.PodSpec{NodeSelector: map[string]string{"pool": "a"}}).Obj()).
+			Replicas(1).
+			Obj()).Obj()
+	js.Generation = 1
+
+	job, err := constructJob(js, &js.Spec.ReplicatedJobs[0], 0)
+	if err != nil {
+		t.Fatalf("constructJob() error = %v", err)
+	}
+	if got := job.Labels[jobset.JobSetGenerationKey]; got != "1" {
+		t.Errorf("got job generation label %q, want %q", got, "1")
+	}
+	if got := job.Spec.Template.Labels[jobset.JobSetGenerationKey]; got != "1" {
+		t.Errorf("got pod generation label %q, want %q", got, "1")
+	}
+
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, job).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	ctx := context.TODO()
+
+	// The spec changes while the JobSet is suspended, bumping its generation.
+	js.Spec.Suspend = pointer.Bool(false)
+	js.Spec.ReplicatedJobs[0].Template.Spec.Template.Spec.NodeSelector = map[string]string{"pool": "b"}
+	js.Generation = 2
+	if err := r.newReconciliation(js).resumeJobSetIfNecessary(ctx, js, &childJobs{active: []*batchv1.Job{job}}); err != nil {
+		t.Fatalf("resumeJobSetIfNecessary() error = %v", err)
+	}
+
+	var got batchv1.Job
+	if err := r.Get(ctx, client.ObjectKeyFromObject(job), &got); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if gotLabel := got.Labels[jobset.JobSetGenerationKey]; gotLabel != "2" {
+		t.Errorf("got job generation label %q after resume, want %q", gotLabel, "2")
+	}
+	if gotLabel := got.Spec.Template.Labels[jobset.JobSetGenerationKey]; gotLabel != "2" {
+		t.Errorf("got pod template generation label %q after resume, want %q", gotLabel, "2")
+	}
+	if diff := cmp.Diff(map[string]string{"pool": "b"}, got.Spec.Template.Spec.NodeSelector); diff != "" {
+		t.Errorf("unexpected node selector (-want/+got): %s", diff)
+	}
+}
+
+func TestResumeWithUpdatedParallelismOverride(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		Suspend(true).
+		ParallelismOverrides(map[string]int32{"workers": 2}).
+		SchedulingPolicy(&jobset.SchedulingPolicy{Gang: &jobset.GangPolicy{}}).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Completions(4).Obj()).
+			Replicas(1).
+			Obj()).Obj()
+	js.Generation = 1
+
+	job, err := constructJob(js, &js.Spec.ReplicatedJobs[0], 0)
+	if err != nil {
+		t.Fatalf("constructJob() error = %v", err)
+	}
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, job).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	ctx := context.TODO()
+
+	// The workers are scaled up while the JobSet is suspended, bumping its generation.
+	js.Spec.Suspend = pointer.Bool(false)
+	js.Spec.ParallelismOverrides["workers"] = 4
+	js.Generation = 2
+	if err := r.newReconciliation(js).resumeJobSetIfNecessary(ctx, js, &childJobs{active: []*batchv1.Job{job}}); err != nil {
+		t.Fatalf("resumeJobSetIfNecessary() error = %v", err)
+	}
+
+	var got batchv1.Job
+	if err := r.Get(ctx, client.ObjectKeyFromObject(job), &got); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if parallelism := pointer.Int32Deref(got.Spec.Parallelism, 0); parallelism != 4 {
+		t.Errorf("got parallelism %d after resume, want %d", parallelism, 4)
+	}
+	// The pod group of the gang scheduled JobSet grows with the parallelism.
+	if minMember := got.Spec.Template.Annotations[jobset.PodGroupMinMemberKey]; minMember != "4" {
+		t.Errorf("got pod group min member %q after resume, want %q", minMember, "4")
+	}
+}
+
+func TestResumeWithUpdatedTotalPods(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		Suspend(true).
+		TotalPodsEnvName("WORLD_SIZE").
+		ParallelismOverrides(map[string]int32{"workers": 2}).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).PodSpec(testutils.TestPodSpec).Obj()).
+			Replicas(1).
+			Obj()).Obj()
+	js.Generation = 1
+
+	job, err := constructJob(js, &js.Spec.ReplicatedJobs[0], 0)
+	if err != nil {
+		t.Fatalf("constructJob() error = %v", err)
+	}
+	if totalPodsEnvOutdated(js, job) {
+		t.Fatalf("expected total pods of a new job to be up to date")
+	}
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, job).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	ctx := context.TODO()
+
+	// The workers are scaled up while the JobSet is suspended, changing the total number of pods.
+	js.Spec.Suspend = pointer.Bool(false)
+	js.Spec.ParallelismOverrides["workers"] = 4
+	js.Generation = 2
+	if err := r.newReconciliation(js).resumeJobSetIfNecessary(ctx, js, &childJobs{active: []*batchv1.Job{job}}); err != nil {
+		t.Fatalf("resumeJobSetIfNecessary() error = %v", err)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(job), &batchv1.Job{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected job with outdated total pods to be deleted, got error %v", err)
+	}
+}
+
+func TestResumeWithUpdatedTolerations(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Suspend(true).
+			Replicas(1).
+			Obj()).Obj()
+	js.Generation = 1
+
+	job, err := constructJob(js, &js.Spec.ReplicatedJobs[0], 0)
+	if err != nil {
+		t.Fatalf("constructJob() error = %v", err)
+	}
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, job).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	ctx := context.TODO()
+
+	// The workers are moved to tainted nodes while suspended, then resumed.
+	tolerations := []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}
+	js.Spec.ReplicatedJobs[0].Suspend = pointer.Bool(false)
+	js.Spec.ReplicatedJobs[0].Template.Spec.Template.Spec.Tolerations = tolerations
+	js.Generation = 2
+	if err := r.newReconciliation(js).resumeJobSetIfNecessary(ctx, js, &childJobs{active: []*batchv1.Job{job}}); err != nil {
+		t.Fatalf("resumeJobSetIfNecessary() error = %v", err)
+	}
+
+	var got batchv1.Job
+	if err := r.Get(ctx, client.ObjectKeyFromObject(job), &got); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if diff := cmp.Diff(tolerations, got.Spec.Template.Spec.Tolerations); diff != "" {
+		t.Errorf("unexpected tolerations after resume (-want/+got): %s", diff)
+	}
+}
+
+func TestSuspendPolicy(t *testing.T) {
+	ns := "default"
+	tests := []struct {
+		policy          jobset.SuspendPolicy
+		wantSuspend     bool
+		wantParallelism int32
+	}{
+		{
+			policy:          "",
+			wantSuspend:     true,
+			wantParallelism: 4,
+		},
+		{
+			policy:          jobset.SuspendPolicyDeletePods,
+			wantSuspend:     true,
+			wantParallelism: 4,
+		},
+		{
+			// Running pods are kept, but no new pods are started.
+			policy:          jobset.SuspendPolicyKeepPods,
+			wantSuspend:     false,
+			wantParallelism: 2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("policy %q", tc.policy), func(t *testing.T) {
+			js := testutils.MakeJobSet("test-jobset", ns).
+				Suspend(true).
+				ReplicatedJob(testutils.MakeReplicatedJob("workers").
+					Job(testutils.MakeJobTemplate("test-job", ns).Parallelism(4).Obj()).
+					Replicas(1).
+					Obj()).Obj()
+			js.Spec.SuspendPolicy = tc.policy
+			job := makeJob(&makeJobArgs{
+				jobSetName:        "test-jobset",
+				replicatedJobName: "workers",
+				jobName:           "test-jobset-workers-0",
+				ns:                ns,
+				replicas:          1,
+			}).Suspend(false).Parallelism(4).Active(2).Obj()
+			r := JobSetReconciler{
+				Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, job).Build(),
+				Record: record.NewFakeRecorder(10),
+			}
+			ctx := context.TODO()
+			if err := r.newReconciliation(js).suspendJobSet(ctx, js, &childJobs{active: []*batchv1.Job{job}}); err != nil {
+				t.Fatalf("suspendJobSet() error = %v", err)
+			}
+			var got batchv1.Job
+			if err := r.Get(ctx, client.ObjectKeyFromObject(job), &got); err != nil {
+				t.Fatalf("getting job: %v", err)
+			}
+			if suspend := pointer.BoolDeref(got.Spec.Suspend, false); suspend != tc.wantSuspend {
+				t.Errorf("got suspend %t, want %t", suspend, tc.wantSuspend)
+			}
+			if parallelism := pointer.Int32Deref(got.Spec.Parallelism, 0); parallelism != tc.wantParallelism {
+				t.Errorf("got parallelism %d, want %d", parallelism, tc.wantParallelism)
+			}
+
+			// A kept pod finishes, so the parallelism is lowered again and the pod is not replaced.
+			if !tc.wantSuspend {
+				got.Status.Active = 1
+				if err := r.newReconciliation(js).suspendJobSet(ctx, js, &childJobs{active: []*batchv1.Job{&got}}); err != nil {
+					t.Fatalf("suspendJobSet() error = %v", err)
+				}
+				if err := r.Get(ctx, client.ObjectKeyFromObject(job), &got); err != nil {
+					t.Fatalf("getting job: %v", err)
+				}
+				if parallelism := pointer.Int32Deref(got.Spec.Parallelism, 0); parallelism != 1 {
+					t.Errorf("got parallelism %d after a pod finished, want %d", parallelism, 1)
+				}
+			}
+
+			// On resume, the job runs with the parallelism of its replicated job again.
+			js.Spec.Suspend = pointer.Bool(false)
+			if err := r.newReconciliation(js).resumeJobSetIfNecessary(ctx, js, &childJobs{active: []*batchv1.Job{&got}}); err != nil {
+				t.Fatalf("resumeJobSetIfNecessary() error = %v", err)
+			}
+			if err := r.Get(ctx, client.ObjectKeyFromObject(job), &got); err != nil {
+				t.Fatalf("getting job: %v", err)
+			}
+			if pointer.BoolDeref(got.Spec.Suspend, false) {
+				t.Errorf("got suspended job after resume")
+			}
+			if parallelism := pointer.Int32Deref(got.Spec.Parallelism, 0); parallelism != 4 {
+				t.Errorf("got parallelism %d after resume, want %d", parallelism, 4)
+			}
+		})
+	}
+}
+
+func TestReplicatedJobSuspendKeepPods(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Parallelism(4).Obj()).
+			Suspend(true).
+			Replicas(1).
+			Obj()).Obj()
+	js.Spec.SuspendPolicy = jobset.SuspendPolicyKeepPods
+	job := makeJob(&makeJobArgs{
+		jobSetName:        "test-jobset",
+		replicatedJobName: "workers",
+		jobName:           "test-jobset-workers-0",
+		ns:                ns,
+		replicas:          1,
+	}).Suspend(false).Parallelism(4).Active(3).Obj()
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, job).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	ctx := context.TODO()
+
+	// The suspended replicated job keeps its running pods, like a suspended JobSet.
+	if err := r.newReconciliation(js).resumeJobSetIfNecessary(ctx, js, &childJobs{active: []*batchv1.Job{job}}); err != nil {
+		t.Fatalf("resumeJobSetIfNecessary() error = %v", err)
+	}
+	var got batchv1.Job
+	if err := r.Get(ctx, client.ObjectKeyFromObject(job), &got); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if pointer.BoolDeref(got.Spec.Suspend, false) {
+		t.Errorf("got suspended job of a replicated job suspended with the KeepPods policy")
+	}
+	if parallelism := pointer.Int32Deref(got.Spec.Parallelism, 0); parallelism != 3 {
+		t.Errorf("got parallelism %d while suspended, want %d", parallelism, 3)
+	}
+
+	// On resume of the replicated job, the job gets back its parallelism.
+	js.Spec.ReplicatedJobs[0].Suspend = pointer.Bool(false)
+	if err := r.newReconciliation(js).resumeJobSetIfNecessary(ctx, js, &childJobs{active: []*batchv1.Job{&got}}); err != nil {
+		t.Fatalf("resumeJobSetIfNecessary() error = %v", err)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(job), &got); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if parallelism := pointer.Int32Deref(got.Spec.Parallelism, 0); parallelism != 4 {
+		t.Errorf("got parallelism %d after resume, want %d", parallelism, 4)
+	}
+}
+
+func TestResumeWithUpdatedContainerImages(t *testing.T) {
+	ns := "default"
+	makeJobSet := func() *jobset.JobSet {
+		return testutils.MakeJobSet("test-jobset", ns).
+			ReplicatedJob(testutils.MakeReplicatedJob("workers").
+				Job(testutils.MakeJobTemplate("test-job", ns).PodSpec(testutils.TestPodSpec).Obj()).
+				Replicas(1).
+				Obj()).Obj()
+	}
+	// outdatedJob returns the child job of the JobSet as created before its image was updated.
+	outdatedJob := func(js *jobset.JobSet) *batchv1.Job {
+		job, err := constructJob(js, &js.Spec.ReplicatedJobs[0], 0)
+		if err != nil {
+			t.Fatalf("constructJob() error = %v", err)
+		}
+		job.Spec.Template.Spec.Containers[0].Image = "busybox:broken"
+		return job
+	}
+
+	t.Run("failed jobset is restarted", func(t *testing.T) {
+		js := makeJobSet()
+		js.Status.Conditions = []metav1.Condition{{
+			Type:   string(jobset.JobSetFailed),
+			Status: metav1.ConditionTrue,
+			Reason: "ReachedMaxRestarts",
+		}}
+		js.Status.Restarts = 1
+		js.Status.RestartsCountTowardsMax = 1
+		job := outdatedJob(js)
+		r := JobSetReconciler{
+			Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, job).Build(),
+			Record: record.NewFakeRecorder(10),
+		}
+		restarted, err := r.newReconciliation(js).restartFailedJobSetWithUpdatedImages(context.TODO(), js, &childJobs{failed: []*batchv1.Job{job}})
+		if err != nil {
+			t.Fatalf("restartFailedJobSetWithUpdatedImages() error = %v", err)
+		}
+		if !restarted {
+			t.Fatalf("expected failed jobset with updated images to be restarted")
+		}
+		if js.Status.Restarts != 2 {
+			t.Errorf("got %d restarts, want 2", js.Status.Restarts)
+		}
+		// The restarted JobSet gets a fresh budget of restarts.
+		if js.Status.RestartsCountTowardsMax != 0 {
+			t.Errorf("got %d restarts counting towards the maximum, want 0", js.Status.RestartsCountTowardsMax)
+		}
+		if jobSetFinished(js) {
+			t.Errorf("expected restarted jobset not to be finished, got conditions %v", js.Status.Conditions)
+		}
+	})
+
+	t.Run("failed jobset without updated images is not restarted", func(t *testing.T) {
+		js := makeJobSet()
+		js.Status.Conditions = []metav1.Condition{{
+			Type:   string(jobset.JobSetFailed),
+			Status: metav1.ConditionTrue,
+			Reason: "FailedJobs",
+		}}
+		job, err := constructJob(js, &js.Spec.ReplicatedJobs[0], 0)
+		if err != nil {
+			t.Fatalf("constructJob() error = %v", err)
+		}
+		r := JobSetReconciler{
+			Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, job).Build(),
+			Record: record.NewFakeRecorder(10),
+		}
+		restarted, err := r.newReconciliation(js).restartFailedJobSetWithUpdatedImages(context.TODO(), js, &childJobs{failed: []*batchv1.Job{job}})
+		if err != nil {
+			t.Fatalf("restartFailedJobSetWithUpdatedImages() error = %v", err)
+		}
+		if restarted {
+			t.Errorf("expected failed jobset without updated images not to be restarted")
+		}
+	})
+
+	t.Run("jobset which exceeded its deadline is not restarted", func(t *testing.T) {
+		js := makeJobSet()
+		js.Status.Conditions = []metav1.Condition{{
+			Type:   string(jobset.JobSetFailed),
+			Status: metav1.ConditionTrue,
+			Reason: reasonDeadlineExceeded,
+		}}
+		job := outdatedJob(js)
+		r := JobSetReconciler{
+			Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, job).Build(),
+			Record: record.NewFakeRecorder(10),
+		}
+		restarted, err := r.newReconciliation(js).restartFailedJobSetWithUpdatedImages(context.TODO(), js, &childJobs{failed: []*batchv1.Job{job}})
+		if err != nil {
+			t.Fatalf("restartFailedJobSetWithUpdatedImages() error = %v", err)
+		}
+		if restarted {
+			t.Errorf("expected jobset which exceeded its deadline not to be restarted")
+		}
+	})
+
+	t.Run("suspended jobs with updated images are recreated on resume", func(t *testing.T) {
+		js := makeJobSet()
+		job := outdatedJob(js)
+		job.Spec.Suspend = pointer.Bool(true)
+		r := JobSetReconciler{
+			Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, job).Build(),
+			Record: record.NewFakeRecorder(10),
+		}
+		ctx := context.TODO()
+		if err := r.newReconciliation(js).resumeJobSetIfNecessary(ctx, js, &childJobs{active: []*batchv1.Job{job}}); err != nil {
+			t.Fatalf("resumeJobSetIfNecessary() error = %v", err)
+		}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(job), &batchv1.Job{}); !apierrors.IsNotFound(err) {
+			t.Errorf("expected job with outdated image to be deleted, got error %v", err)
+		}
+	})
+}
+
+func TestReplicatedJobSuspend(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("coordinator").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(1).
+			Obj()).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(1).
+			Suspend(true).
+			Obj()).Obj()
+
+	var jobs []*batchv1.Job
+	for i := range js.Spec.ReplicatedJobs {
+		job, err := constructJob(js, &js.Spec.ReplicatedJobs[i], 0)
+		if err != nil {
+			t.Fatalf("constructJob() error = %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if pointer.BoolDeref(jobs[0].Spec.Suspend, false) {
+		t.Errorf("expected job %s not to be suspended", jobs[0].Name)
+	}
+	if !pointer.BoolDeref(jobs[1].Spec.Suspend, false) {
+		t.Errorf("expected job %s to be suspended", jobs[1].Name)
+	}
+
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(js, jobs[0], jobs[1]).Build(),
+		Record: record.NewFakeRecorder(10),
+	}
+	ctx := context.TODO()
+
+	// Suspending the coordinator and resuming the workers only affects their own jobs.
+	js.Spec.ReplicatedJobs[0].Suspend = pointer.Bool(true)
+	js.Spec.ReplicatedJobs[1].Suspend = pointer.Bool(false)
+	if err := r.newReconciliation(js).resumeJobSetIfNecessary(ctx, js, &childJobs{active: jobs}); err != nil {
+		t.Fatalf("resumeJobSetIfNecessary() error = %v", err)
+	}
+	for i, wantSuspended := range []bool{true, false} {
+		var got batchv1.Job
+		if err := r.Get(ctx, client.ObjectKeyFromObject(jobs[i]), &got); err != nil {
+			t.Fatalf("getting job: %v", err)
+		}
+		if gotSuspended := pointer.BoolDeref(got.Spec.Suspend, false); gotSuspended != wantSuspended {
+			t.Errorf("got job %s suspended %t, want %t", got.Name, gotSuspended, wantSuspended)
+		}
+	}
+	if meta.IsStatusConditionTrue(js.Status.Conditions, string(jobset.JobSetSuspended)) {
+		t.Errorf("expected jobset not to be suspended")
+	}
+}
+
+func TestLifecycleEvents(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	js := testutils.MakeJobSet(jobSetName, ns).
+		SuccessPolicy(&jobset.SuccessPolicy{Operator: jobset.OperatorAll}).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(1).
+			Obj()).Obj()
+	scheme := testScheme(t)
+	recorder := record.NewFakeRecorder(10)
+	r := JobSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(js).Build(),
+		Scheme: scheme,
+		Record: recorder,
+	}
+	ctx := context.TODO()
+	job := makeJob(&makeJobArgs{
+		jobSetName:        jobSetName,
+		replicatedJobName: "workers",
+		jobName:           "test-jobset-workers-0",
+		ns:                ns,
+		replicas:          1,
+	}).Obj()
+
+	if err := r.newReconciliation(js).createJobs(ctx, js, &childJobs{}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+	if _, err := r.newReconciliation(js).executeSuccessPolicy(ctx, js.DeepCopy(), &childJobs{successful: []*batchv1.Job{job}}); err != nil {
+		t.Fatalf("executeSuccessPolicy() error = %v", err)
+	}
+	// The failure policy is executed on the latest version of the JobSet, as in a later reconcile.
+	var latest jobset.JobSet
+	if err := r.Get(ctx, client.ObjectKeyFromObject(js), &latest); err != nil {
+		t.Fatalf("getting jobset: %v", err)
+	}
+	if _, err := r.newReconciliation(&latest).executeFailurePolicy(ctx, &latest, &childJobs{failed: []*batchv1.Job{job}}); err != nil {
+		t.Fatalf("executeFailurePolicy() error = %v", err)
+	}
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	for _, want := range []string{
+		"Normal JobCreated created job test-jobset-workers-0 of replicatedJob workers",
+		"Normal SuccessPolicySatisfied success policy with operator All satisfied by replicatedJobs workers",
+		"Normal Completed AllJobsCompleted: jobset completed successfully",
+		"Warning FailurePolicyTriggered failure policy triggered by failed job test-jobset-workers-0 of replicatedJob workers",
+		"Warning Failed FailedJobs: jobset failed due to one or more job failures",
+	} {
+		if !util.Contains(events, want) {
+			t.Errorf("missing event %q, got %v", want, events)
+		}
+	}
+}
+
+func TestRecordProgressEvent(t *testing.T) {
+	ns := "default"
+	js := testutils.MakeJobSet("test-jobset", ns).
+		ReplicatedJob(testutils.MakeReplicatedJob("workers").
+			Job(testutils.MakeJobTemplate("test-job", ns).Obj()).
+			Replicas(2).
+			Obj()).Obj()
+	ownedJobs := &childJobs{
+		successful: []*batchv1.Job{makeJob(&makeJobArgs{
+			jobSetName:        "test-jobset",
+			replicatedJobName: "workers",
+			jobName:           "test-jobset-workers-0",
+			ns:                ns,
+			replicas:          2,
+		}).Obj()},
+	}
+	recorder := record.NewFakeRecorder(10)
+	r := JobSetReconciler{Record: recorder, ProgressEventInterval: 10 * time.Minute}
+	start := time.Now()
+
+	steps := []struct {
+		after         time.Duration
+		wantNextEvent time.Duration
+		wantEvent     bool
+	}{
+		// The first event is due one interval after the JobSet is first seen running.
+		{after: 0, wantNextEvent: 10 * time.Minute},
+		{after: 4 * time.Minute, wantNextEvent: 6 * time.Minute},
+		{after: 10 * time.Minute, wantNextEvent: 10 * time.Minute, wantEvent: true},
+		// Reconciles within the interval do not record another event.
+		{after: 11 * time.Minute, wantNextEvent: 9 * time.Minute},
+		{after: 19 * time.Minute, wantNextEvent: time.Minute},
+		{after: 21 * time.Minute, wantNextEvent: 10 * time.Minute, wantEvent: true},
+	}
+	for i, step := range steps {
+		if got := r.recordProgressEvent(js, ownedJobs, start.Add(step.after)); got != step.wantNextEvent {
+			t.Errorf("step %d: got next event in %v, want %v", i, got, step.wantNextEvent)
+		}
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		var want []string
+		if step.wantEvent {
+			want = []string{"Normal Progressing 1/2 jobs completed"}
+		}
+		if diff := cmp.Diff(want, events); diff != "" {
+			t.Errorf("step %d: unexpected events (-want/+got): %s", i, diff)
+		}
+	}
+
+	// No events are recorded while the JobSet is suspended.
+	js.Spec.Suspend = pointer.Bool(true)
+	if got := r.recordProgressEvent(js, ownedJobs, start.Add(time.Hour)); got != 0 {
+		t.Errorf("got next event in %v while suspended, want 0", got)
+	}
+	if len(recorder.Events) > 0 {
+		t.Errorf("got event %q while suspended", <-recorder.Events)
+	}
+}
+
+// zoneAffinity returns an affinity requiring nodes in the given zone.
+func zoneAffinity(zone string) *corev1.Affinity {
+	return &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      "topology.kubernetes.io/zone",
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{zone},
+				}},
+			}},
+		},
+	}}
+}
+
+// testScheme returns a scheme with the core Kubernetes and JobSet types registered.
+func testScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding client-go types to scheme: %v", err)
+	}
+	if err := jobset.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding jobset types to scheme: %v", err)
+	}
+	return scheme
+}
+
+type makeJobArgs struct {
+	jobSetName        string
+	replicatedJobName string
+	jobName           string
+	ns                string
+	replicas          int
+	jobIdx            int
+	restarts          int
+	generation        int64
+	workloadType      jobset.WorkloadType
+}
+
+func makeJob(args *makeJobArgs) *testutils.JobWrapper {
+	jobWrapper := testutils.MakeJob(args.jobName, args.ns).
+		JobLabels(map[string]string{
+			jobset.JobSetNameKey:         args.jobSetName,
+			jobset.ReplicatedJobNameKey:  args.replicatedJobName,
+			jobset.ReplicatedJobReplicas: strconv.Itoa(args.replicas),
+			jobset.JobIndexKey:           strconv.Itoa(args.jobIdx),
+			RestartsKey:                  strconv.Itoa(args.restarts),
+			jobset.JobSetGenerationKey:   strconv.FormatInt(args.generation, 10),
+			LabelSchemaVersionKey:        labelSchemaVersion,
+		}).
+		JobAnnotations(map[string]string{
+			jobset.JobSetNameKey:         args.jobSetName,
+			jobset.ReplicatedJobNameKey:  args.replicatedJobName,
+			jobset.ReplicatedJobReplicas: strconv.Itoa(args.replicas),
+			jobset.JobIndexKey:           strconv.Itoa(args.jobIdx),
+		}).
+		PodLabels(map[string]string{
+			jobset.JobSetNameKey:         args.jobSetName,
+			jobset.ReplicatedJobNameKey:  args.replicatedJobName,
+			jobset.ReplicatedJobReplicas: strconv.Itoa(args.replicas),
 			jobset.JobIndexKey:           strconv.Itoa(args.jobIdx),
 			RestartsKey:                  strconv.Itoa(args.restarts),
+			jobset.JobSetGenerationKey:   strconv.FormatInt(args.generation, 10),
+			LabelSchemaVersionKey:        labelSchemaVersion,
 		}).
 		PodAnnotations(map[string]string{
 			jobset.JobSetNameKey:         args.jobSetName,
@@ -992,5 +5865,66 @@ func makeJob(args *makeJobArgs) *testutils.JobWrapper {
 			jobset.ReplicatedJobReplicas: strconv.Itoa(args.replicas),
 			jobset.JobIndexKey:           strconv.Itoa(args.jobIdx),
 		})
+	if args.workloadType != "" {
+		jobWrapper.Labels[jobset.WorkloadTypeKey] = string(args.workloadType)
+		jobWrapper.Spec.Template.Labels[jobset.WorkloadTypeKey] = string(args.workloadType)
+	}
 	return jobWrapper
 }
+
+func TestCreatedNotification(t *testing.T) {
+	received := make(chan notifier.Payload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var payload notifier.Payload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			t.Errorf("decoding payload: %v", err)
+		}
+		received <- payload
+	}))
+	defer server.Close()
+	n := notifier.New(server.URL, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		if err := n.Start(ctx); err != nil {
+			t.Errorf("Start() error = %v", err)
+		}
+	}()
+
+	makeJobSet := func(name string, replicas int) *jobset.JobSet {
+		return testutils.MakeJobSet(name, "default").
+			ReplicatedJob(testutils.MakeReplicatedJob("workers").
+				Job(testutils.MakeJobTemplate("test-job", "default").Obj()).
+				Replicas(replicas).
+				Obj()).Obj()
+	}
+	// Without replicas, no jobs are created, so the JobSet is not reported as created
+	// however often it is reconciled.
+	empty := makeJobSet("empty", 0)
+	created := makeJobSet("created", 1)
+	scheme := testScheme(t)
+	r := JobSetReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(empty, created).Build(),
+		Scheme:   scheme,
+		Record:   record.NewFakeRecorder(10),
+		Notifier: n,
+	}
+	for i := 0; i < 2; i++ {
+		if err := r.newReconciliation(empty).createJobs(ctx, empty, &childJobs{}); err != nil {
+			t.Fatalf("createJobs() error = %v", err)
+		}
+	}
+	if err := r.newReconciliation(created).createJobs(ctx, created, &childJobs{}); err != nil {
+		t.Fatalf("createJobs() error = %v", err)
+	}
+
+	// Notifications are delivered in order, so the first one is the only one expected.
+	select {
+	case payload := <-received:
+		if payload.Event != notifier.EventCreated || payload.Name != "created" {
+			t.Errorf("got %s notification for jobset %s, want %s for jobset created", payload.Event, payload.Name, notifier.EventCreated)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for the created notification")
+	}
+}
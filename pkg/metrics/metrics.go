@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the Prometheus metrics reported by the JobSet controller.
+// The depth, adds and latency of its workqueue are reported by controller-runtime, as
+// workqueue_depth, workqueue_adds_total and workqueue_queue_duration_seconds with the
+// name="jobset" label, including requeues and rate limited adds.
+// They are served on the metrics endpoint of the controller manager.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespaceLabel = "namespace"
+
+var (
+	ReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "jobset_reconcile_duration_seconds",
+		Help:    "Duration of JobSet reconciliations, in seconds.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 15),
+	}, []string{namespaceLabel})
+
+	ActiveCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jobset_active_count",
+		Help: "Number of JobSets which have neither completed nor failed.",
+	}, []string{namespaceLabel})
+
+	CompletedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "jobset_completed_total",
+		Help: "Number of JobSets which completed successfully.",
+	}, []string{namespaceLabel})
+
+	FailedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "jobset_failed_total",
+		Help: "Number of JobSets which failed.",
+	}, []string{namespaceLabel})
+
+	RestartsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "jobset_restarts_total",
+		Help: "Number of JobSet restarts.",
+	}, []string{namespaceLabel})
+)
+
+// active holds the JobSets counted by ActiveCount, so that repeated
+// reconciliations of the same JobSet do not count it more than once.
+var active = struct {
+	sync.Mutex
+	jobSets map[types.NamespacedName]bool
+}{jobSets: map[types.NamespacedName]bool{}}
+
+// Register registers the JobSet metrics with the controller-runtime registry.
+func Register() {
+	ctrlmetrics.Registry.MustRegister(
+		ReconcileDuration,
+		ActiveCount,
+		CompletedTotal,
+		FailedTotal,
+		RestartsTotal,
+	)
+}
+
+// ReconcileDone records the duration of a reconciliation started at the given time.
+func ReconcileDone(namespace string, start time.Time) {
+	ReconcileDuration.WithLabelValues(namespace).Observe(time.Since(start).Seconds())
+}
+
+// SetActive records whether the JobSet is active, i.e. neither finished nor deleted.
+func SetActive(key types.NamespacedName, isActive bool) {
+	active.Lock()
+	defer active.Unlock()
+	if active.jobSets[key] == isActive {
+		return
+	}
+	if isActive {
+		active.jobSets[key] = true
+		ActiveCount.WithLabelValues(key.Namespace).Inc()
+		return
+	}
+	delete(active.jobSets, key)
+	ActiveCount.WithLabelValues(key.Namespace).Dec()
+}
+
+// JobSetCompleted records that a JobSet in the namespace completed.
+func JobSetCompleted(namespace string) {
+	CompletedTotal.WithLabelValues(namespace).Inc()
+}
+
+// JobSetFailed records that a JobSet in the namespace failed.
+func JobSetFailed(namespace string) {
+	FailedTotal.WithLabelValues(namespace).Inc()
+}
+
+// JobSetRestarted records that a JobSet in the namespace was restarted.
+func JobSetRestarted(namespace string) {
+	RestartsTotal.WithLabelValues(namespace).Inc()
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCounters(t *testing.T) {
+	ns := "counters"
+	JobSetCompleted(ns)
+	JobSetFailed(ns)
+	JobSetFailed(ns)
+	JobSetRestarted(ns)
+	JobSetRestarted(ns)
+	JobSetRestarted(ns)
+	JobSetCompleted("other")
+
+	for name, tc := range map[string]struct {
+		got  float64
+		want float64
+	}{
+		"completed": {got: testutil.ToFloat64(CompletedTotal.WithLabelValues(ns)), want: 1},
+		"failed":    {got: testutil.ToFloat64(FailedTotal.WithLabelValues(ns)), want: 2},
+		"restarts":  {got: testutil.ToFloat64(RestartsTotal.WithLabelValues(ns)), want: 3},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("got %s count %v, want %v", name, tc.got, tc.want)
+		}
+	}
+}
+
+func TestSetActive(t *testing.T) {
+	ns := "active"
+	a := types.NamespacedName{Namespace: ns, Name: "a"}
+	b := types.NamespacedName{Namespace: ns, Name: "b"}
+
+	steps := []struct {
+		key      types.NamespacedName
+		isActive bool
+		want     float64
+	}{
+		{key: a, isActive: true, want: 1},
+		// Repeated reconciliations of an active JobSet do not count it again.
+		{key: a, isActive: true, want: 1},
+		{key: b, isActive: true, want: 2},
+		{key: a, isActive: false, want: 1},
+		{key: a, isActive: false, want: 1},
+		{key: b, isActive: false, want: 0},
+	}
+	for i, step := range steps {
+		SetActive(step.key, step.isActive)
+		if got := testutil.ToFloat64(ActiveCount.WithLabelValues(ns)); got != step.want {
+			t.Errorf("step %d: got active count %v, want %v", i, got, step.want)
+		}
+	}
+}
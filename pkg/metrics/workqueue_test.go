@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestWorkqueueMetrics checks that the workqueue of the JobSet controller, which
+// controller-runtime names after the controller, is reported in the metrics registry.
+func TestWorkqueueMetrics(t *testing.T) {
+	q := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "jobset")
+	defer q.ShutDown()
+	q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "a"}})
+	q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "b"}})
+
+	if got := workqueueMetric(t, "workqueue_adds_total"); got != 2 {
+		t.Errorf("got %v workqueue adds, want 2", got)
+	}
+	if got := workqueueMetric(t, "workqueue_depth"); got != 2 {
+		t.Errorf("got workqueue depth %v, want 2", got)
+	}
+	item, _ := q.Get()
+	q.Done(item)
+	if got := workqueueMetric(t, "workqueue_depth"); got != 1 {
+		t.Errorf("got workqueue depth %v after an item was processed, want 1", got)
+	}
+}
+
+// workqueueMetric returns the value of the named counter or gauge of the JobSet workqueue.
+func workqueueMetric(t *testing.T, name string) float64 {
+	t.Helper()
+	families, err := ctrlmetrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("gathering metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "name" && label.GetValue() == "jobset" {
+					return m.GetCounter().GetValue() + m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	t.Fatalf("metric %s of the jobset workqueue not found", name)
+	return 0
+}
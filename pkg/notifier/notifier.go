@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package notifier posts JobSet state changes to an HTTP endpoint, for
+// integration with external systems which do not watch the API server.
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha1"
+)
+
+// Event is a JobSet state change reported by the notifier.
+type Event string
+
+const (
+	EventCreated   Event = "Created"
+	EventCompleted Event = "Completed"
+	EventFailed    Event = "Failed"
+)
+
+// Payload is the JSON body posted for each event.
+type Payload struct {
+	Event     Event       `json:"event"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace"`
+	UID       string      `json:"uid"`
+	Restarts  int         `json:"restarts"`
+	Time      metav1.Time `json:"time"`
+}
+
+// queueSize bounds the number of events waiting to be delivered, so an unreachable
+// endpoint cannot make the notifier buffer an unbounded number of them.
+const queueSize = 1000
+
+// Notifier posts a Payload to URL for each event, retrying failed requests.
+type Notifier struct {
+	URL    string
+	Client *http.Client
+	// Backoff bounds the attempts made to deliver a single event.
+	Backoff wait.Backoff
+
+	// queue holds the bodies of the enqueued events until Start delivers them.
+	queue chan []byte
+}
+
+// New returns a Notifier posting to url, attempting each delivery up to retries times.
+func New(url string, retries int) *Notifier {
+	backoff := retry.DefaultBackoff
+	if retries > 0 {
+		backoff.Steps = retries
+	}
+	return &Notifier{
+		URL:     url,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+		Backoff: backoff,
+		queue:   make(chan []byte, queueSize),
+	}
+}
+
+// Notify posts the given event for the JobSet, returning the last error if
+// every attempt failed.
+func (n *Notifier) Notify(ctx context.Context, js *jobset.JobSet, event Event) error {
+	body, err := payload(js, event)
+	if err != nil {
+		return err
+	}
+	return n.deliver(ctx, body)
+}
+
+// Enqueue queues the given event for the JobSet for delivery by Start, without waiting
+// for it to be delivered. The event is dropped and an error returned if the queue is full.
+func (n *Notifier) Enqueue(js *jobset.JobSet, event Event) error {
+	body, err := payload(js, event)
+	if err != nil {
+		return err
+	}
+	select {
+	case n.queue <- body:
+		return nil
+	default:
+		return fmt.Errorf("notifier queue is full, dropping %s event", event)
+	}
+}
+
+// Start delivers the enqueued events one at a time until the context is done, so a slow
+// endpoint never blocks the callers of Enqueue. It implements manager.Runnable.
+func (n *Notifier) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("notifier")
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case body := <-n.queue:
+			if err := n.deliver(ctx, body); err != nil {
+				log.Error(err, "delivering jobset notification")
+			}
+		}
+	}
+}
+
+// payload returns the JSON body posted for the given event for the JobSet.
+func payload(js *jobset.JobSet, event Event) ([]byte, error) {
+	return json.Marshal(Payload{
+		Event:     event,
+		Name:      js.Name,
+		Namespace: js.Namespace,
+		UID:       string(js.UID),
+		Restarts:  js.Status.Restarts,
+		Time:      metav1.Now(),
+	})
+}
+
+// deliver posts the body, retrying failed requests within the backoff.
+func (n *Notifier) deliver(ctx context.Context, body []byte) error {
+	return retry.OnError(n.Backoff, func(error) bool { return ctx.Err() == nil }, func() error {
+		return n.post(ctx, body)
+	})
+}
+
+func (n *Notifier) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notifier endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	testutils "sigs.k8s.io/jobset/pkg/util/testing"
+)
+
+func TestNotify(t *testing.T) {
+	testCases := []struct {
+		name string
+		// failures is the number of requests the server fails before accepting payloads.
+		failures     int
+		retries      int
+		events       []Event
+		wantPayloads []Payload
+		wantErr      bool
+	}{
+		{
+			name:    "payloads posted for each event",
+			retries: 3,
+			events:  []Event{EventCreated, EventCompleted},
+			wantPayloads: []Payload{
+				{Event: EventCreated, Name: "test-jobset", Namespace: "default", UID: "uid", Restarts: 1},
+				{Event: EventCompleted, Name: "test-jobset", Namespace: "default", UID: "uid", Restarts: 1},
+			},
+		},
+		{
+			name:     "failed requests are retried",
+			failures: 2,
+			retries:  3,
+			events:   []Event{EventFailed},
+			wantPayloads: []Payload{
+				{Event: EventFailed, Name: "test-jobset", Namespace: "default", UID: "uid", Restarts: 1},
+			},
+		},
+		{
+			name:     "error after retries are exhausted",
+			failures: 3,
+			retries:  3,
+			events:   []Event{EventFailed},
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				requests int
+				payloads []Payload
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				requests++
+				if requests <= tc.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				if got := req.Header.Get("Content-Type"); got != "application/json" {
+					t.Errorf("unexpected content type %q", got)
+				}
+				var payload Payload
+				if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+					t.Errorf("decoding payload: %v", err)
+				}
+				payloads = append(payloads, payload)
+			}))
+			defer server.Close()
+
+			js := testutils.MakeJobSet("test-jobset", "default").Obj()
+			js.UID = "uid"
+			js.Status.Restarts = 1
+
+			n := New(server.URL, tc.retries)
+			n.Backoff.Duration = time.Millisecond
+			var gotErr error
+			for _, event := range tc.events {
+				if err := n.Notify(context.Background(), js, event); err != nil {
+					gotErr = err
+				}
+			}
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("Notify() error = %v, wantErr %v", gotErr, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantPayloads, payloads, cmpopts.IgnoreFields(Payload{}, "Time")); diff != "" {
+				t.Errorf("unexpected payloads (-want/+got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan Payload)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// Hold the requests until released, so enqueueing is shown not to wait for delivery.
+		<-release
+		var payload Payload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			t.Errorf("decoding payload: %v", err)
+		}
+		received <- payload
+	}))
+	defer server.Close()
+
+	js := testutils.MakeJobSet("test-jobset", "default").Obj()
+	n := New(server.URL, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		if err := n.Start(ctx); err != nil {
+			t.Errorf("Start() error = %v", err)
+		}
+	}()
+
+	for _, event := range []Event{EventCreated, EventCompleted} {
+		if err := n.Enqueue(js, event); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+	close(release)
+	// Events are delivered in the order they were enqueued.
+	for _, want := range []Event{EventCreated, EventCompleted} {
+		select {
+		case payload := <-received:
+			if payload.Event != want {
+				t.Errorf("got event %s, want %s", payload.Event, want)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for event %s", want)
+		}
+	}
+
+	// Events are dropped once the queue is full.
+	full := New(server.URL, 1)
+	full.queue = make(chan []byte, 1)
+	if err := full.Enqueue(js, EventCreated); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if err := full.Enqueue(js, EventFailed); err == nil {
+		t.Errorf("expected an error enqueueing to a full queue")
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package summary provides a compact representation of a JobSet, for use by
+// dashboards and other tools which only need an overview of its state.
+package summary
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha1"
+)
+
+// Phase is a single word describing the state of a JobSet.
+type Phase string
+
+const (
+	PhaseRunning    Phase = "Running"
+	PhaseSuspended  Phase = "Suspended"
+	PhaseRestarting Phase = "Restarting"
+	PhaseCompleted  Phase = "Completed"
+	PhaseFailed     Phase = "Failed"
+)
+
+// JobSetSummary is a compact summary of a JobSet.
+type JobSetSummary struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Phase     Phase  `json:"phase"`
+	// ReadyJobs is the number of child jobs which are ready.
+	ReadyJobs int32 `json:"readyJobs"`
+	// TotalJobs is the number of child jobs the JobSet runs.
+	TotalJobs  int32              `json:"totalJobs"`
+	Restarts   int                `json:"restarts"`
+	Conditions []ConditionSummary `json:"conditions,omitempty"`
+}
+
+// ConditionSummary is a compact summary of a JobSet condition.
+type ConditionSummary struct {
+	Type   string                 `json:"type"`
+	Status metav1.ConditionStatus `json:"status"`
+	Reason string                 `json:"reason,omitempty"`
+}
+
+// Summarize returns the summary of the given JobSet, computed from its spec and status.
+func Summarize(js *jobset.JobSet) JobSetSummary {
+	summary := JobSetSummary{
+		Name:      js.Name,
+		Namespace: js.Namespace,
+		Phase:     phase(js),
+		Restarts:  js.Status.Restarts,
+	}
+	for _, rjob := range js.Spec.ReplicatedJobs {
+		summary.TotalJobs += int32(rjob.Replicas)
+	}
+	for _, status := range js.Status.ReplicatedJobsStatus {
+		summary.ReadyJobs += status.Ready
+	}
+	for _, c := range js.Status.Conditions {
+		summary.Conditions = append(summary.Conditions, ConditionSummary{
+			Type:   c.Type,
+			Status: c.Status,
+			Reason: c.Reason,
+		})
+	}
+	return summary
+}
+
+// phase returns the phase of the JobSet. Terminal conditions take precedence
+// over transient ones.
+func phase(js *jobset.JobSet) Phase {
+	conditions := js.Status.Conditions
+	switch {
+	case meta.IsStatusConditionTrue(conditions, string(jobset.JobSetFailed)):
+		return PhaseFailed
+	case meta.IsStatusConditionTrue(conditions, string(jobset.JobSetCompleted)):
+		return PhaseCompleted
+	case meta.IsStatusConditionTrue(conditions, string(jobset.JobSetSuspended)):
+		return PhaseSuspended
+	case meta.IsStatusConditionTrue(conditions, string(jobset.JobSetRestarting)):
+		return PhaseRestarting
+	default:
+		return PhaseRunning
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package summary
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha1"
+	testutils "sigs.k8s.io/jobset/pkg/util/testing"
+)
+
+func TestSummarize(t *testing.T) {
+	var (
+		jobSetName = "test-jobset"
+		ns         = "default"
+	)
+	makeJobSet := func(conditions ...metav1.Condition) *jobset.JobSet {
+		js := testutils.MakeJobSet(jobSetName, ns).
+			ReplicatedJob(testutils.MakeReplicatedJob("driver").
+				Job(testutils.MakeJobTemplate("job", ns).Obj()).
+				Replicas(1).
+				Obj()).
+			ReplicatedJob(testutils.MakeReplicatedJob("workers").
+				Job(testutils.MakeJobTemplate("job", ns).Obj()).
+				Replicas(3).
+				Obj()).
+			Obj()
+		js.Status.Restarts = 1
+		js.Status.ReplicatedJobsStatus = []jobset.ReplicatedJobStatus{
+			{Name: "driver", Ready: 1},
+			{Name: "workers", Ready: 2},
+		}
+		js.Status.Conditions = conditions
+		return js
+	}
+	condition := func(conditionType jobset.JobSetConditionType, status metav1.ConditionStatus, reason string) metav1.Condition {
+		return metav1.Condition{Type: string(conditionType), Status: status, Reason: reason}
+	}
+	summary := func(phase Phase, conditions ...ConditionSummary) JobSetSummary {
+		return JobSetSummary{
+			Name:       jobSetName,
+			Namespace:  ns,
+			Phase:      phase,
+			ReadyJobs:  3,
+			TotalJobs:  4,
+			Restarts:   1,
+			Conditions: conditions,
+		}
+	}
+
+	tests := []struct {
+		name string
+		js   *jobset.JobSet
+		want JobSetSummary
+	}{
+		{
+			name: "running",
+			js:   makeJobSet(),
+			want: summary(PhaseRunning),
+		},
+		{
+			name: "suspended",
+			js:   makeJobSet(condition(jobset.JobSetSuspended, metav1.ConditionTrue, "SuspendedJobs")),
+			want: summary(PhaseSuspended, ConditionSummary{Type: "Suspended", Status: metav1.ConditionTrue, Reason: "SuspendedJobs"}),
+		},
+		{
+			name: "resumed",
+			js:   makeJobSet(condition(jobset.JobSetSuspended, metav1.ConditionFalse, "ResumeJobs")),
+			want: summary(PhaseRunning, ConditionSummary{Type: "Suspended", Status: metav1.ConditionFalse, Reason: "ResumeJobs"}),
+		},
+		{
+			name: "restarting",
+			js:   makeJobSet(condition(jobset.JobSetRestarting, metav1.ConditionTrue, "DeletingPreviousJobs")),
+			want: summary(PhaseRestarting, ConditionSummary{Type: "Restarting", Status: metav1.ConditionTrue, Reason: "DeletingPreviousJobs"}),
+		},
+		{
+			name: "completed",
+			js:   makeJobSet(condition(jobset.JobSetCompleted, metav1.ConditionTrue, "AllJobsCompleted")),
+			want: summary(PhaseCompleted, ConditionSummary{Type: "Completed", Status: metav1.ConditionTrue, Reason: "AllJobsCompleted"}),
+		},
+		{
+			name: "failed while restarting",
+			js: makeJobSet(
+				condition(jobset.JobSetRestarting, metav1.ConditionTrue, "DeletingPreviousJobs"),
+				condition(jobset.JobSetFailed, metav1.ConditionTrue, "ReachedMaxRestarts"),
+			),
+			want: summary(PhaseFailed,
+				ConditionSummary{Type: "Restarting", Status: metav1.ConditionTrue, Reason: "DeletingPreviousJobs"},
+				ConditionSummary{Type: "Failed", Status: metav1.ConditionTrue, Reason: "ReachedMaxRestarts"},
+			),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Summarize(tc.js)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected summary (-want/+got): %s", diff)
+			}
+		})
+	}
+}
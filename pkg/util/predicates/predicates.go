@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package predicates provides filters for watching objects created by JobSets,
+// for use by the JobSet controller and by controllers integrating with JobSets.
+package predicates
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha1"
+)
+
+// JobSetOwnerName returns the name of the JobSet controlling the object, or an
+// empty string if the object is not controlled by a JobSet.
+func JobSetOwnerName(obj metav1.Object) string {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil {
+		return ""
+	}
+	if owner.APIVersion != jobset.GroupVersion.String() || owner.Kind != "JobSet" {
+		return ""
+	}
+	return owner.Name
+}
+
+// JobSetOwnerReferenceName returns the name of the JobSet owning the object, or an empty
+// string if the object has no JobSet owner. Unlike JobSetOwnerName, the JobSet need not
+// be the controller of the object. If several JobSets own the object, the controller is
+// preferred.
+func JobSetOwnerReferenceName(obj metav1.Object) string {
+	if owner := JobSetOwnerName(obj); owner != "" {
+		return owner
+	}
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.APIVersion == jobset.GroupVersion.String() && ref.Kind == "JobSet" {
+			return ref.Name
+		}
+	}
+	return ""
+}
+
+// IsJobSetOwned returns true if the object is controlled by a JobSet.
+func IsJobSetOwned(obj metav1.Object) bool {
+	return JobSetOwnerName(obj) != ""
+}
+
+// JobSetOwnedPredicate filters events to objects controlled by a JobSet, such as child Jobs.
+func JobSetOwnedPredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return IsJobSetOwned(obj)
+	})
+}
+
+// JobSetOwnerReferencePredicate filters events to objects owned by a JobSet, whether or
+// not the JobSet is their controller.
+func JobSetOwnerReferencePredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return JobSetOwnerReferenceName(obj) != ""
+	})
+}
+
+// JobSetLabeledPredicate filters events to objects labeled with the name of a JobSet,
+// such as the pods of child Jobs.
+func JobSetLabeledPredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		_, ok := obj.GetLabels()[jobset.JobSetNameKey]
+		return ok
+	})
+}
+
+// JobSetLabeledSelector returns a label selector matching the objects labeled with the name
+// of a JobSet, like JobSetLabeledPredicate.
+func JobSetLabeledSelector() labels.Selector {
+	requirement, err := labels.NewRequirement(jobset.JobSetNameKey, selection.Exists, nil)
+	if err != nil {
+		panic(err)
+	}
+	return labels.NewSelector().Add(*requirement)
+}
+
+// JobSetSelector returns a label selector matching the child Jobs and pods of the named JobSet.
+func JobSetSelector(jobSetName string) labels.Selector {
+	return labels.SelectorFromSet(labels.Set{jobset.JobSetNameKey: jobSetName})
+}
+
+// ReplicatedJobSelector returns a label selector matching the child Jobs and pods created
+// from the named replicatedJob of the named JobSet.
+func ReplicatedJobSelector(jobSetName, replicatedJobName string) labels.Selector {
+	return labels.SelectorFromSet(labels.Set{
+		jobset.JobSetNameKey:        jobSetName,
+		jobset.ReplicatedJobNameKey: replicatedJobName,
+	})
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package predicates
+
+import (
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha1"
+)
+
+func TestJobSetOwnedPredicate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		owners    []metav1.OwnerReference
+		wantOwned bool
+		wantOwner string
+	}{
+		{
+			name:      "no owner",
+			wantOwned: false,
+		},
+		{
+			name: "controlled by a JobSet",
+			owners: []metav1.OwnerReference{
+				{APIVersion: jobset.GroupVersion.String(), Kind: "JobSet", Name: "js", Controller: pointer.Bool(true)},
+			},
+			wantOwned: true,
+			wantOwner: "js",
+		},
+		{
+			name: "owned but not controlled by a JobSet",
+			owners: []metav1.OwnerReference{
+				{APIVersion: jobset.GroupVersion.String(), Kind: "JobSet", Name: "js"},
+			},
+			wantOwned: false,
+		},
+		{
+			name: "controlled by another kind",
+			owners: []metav1.OwnerReference{
+				{APIVersion: "batch/v1", Kind: "CronJob", Name: "cron", Controller: pointer.Bool(true)},
+			},
+			wantOwned: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "job", OwnerReferences: tc.owners}}
+			if got := JobSetOwnerName(job); got != tc.wantOwner {
+				t.Errorf("JobSetOwnerName() = %q, want %q", got, tc.wantOwner)
+			}
+			if got := JobSetOwnedPredicate().Create(event.CreateEvent{Object: job}); got != tc.wantOwned {
+				t.Errorf("JobSetOwnedPredicate().Create() = %t, want %t", got, tc.wantOwned)
+			}
+		})
+	}
+}
+
+func TestJobSetOwnerReferencePredicate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		owners    []metav1.OwnerReference
+		wantOwner string
+	}{
+		{
+			name: "no owner",
+		},
+		{
+			name: "controlled by a JobSet",
+			owners: []metav1.OwnerReference{
+				{APIVersion: jobset.GroupVersion.String(), Kind: "JobSet", Name: "js", Controller: pointer.Bool(true)},
+			},
+			wantOwner: "js",
+		},
+		{
+			name: "owned but not controlled by a JobSet",
+			owners: []metav1.OwnerReference{
+				{APIVersion: "batch/v1", Kind: "CronJob", Name: "cron", Controller: pointer.Bool(true)},
+				{APIVersion: jobset.GroupVersion.String(), Kind: "JobSet", Name: "js"},
+			},
+			wantOwner: "js",
+		},
+		{
+			name: "controlling JobSet preferred",
+			owners: []metav1.OwnerReference{
+				{APIVersion: jobset.GroupVersion.String(), Kind: "JobSet", Name: "other"},
+				{APIVersion: jobset.GroupVersion.String(), Kind: "JobSet", Name: "js", Controller: pointer.Bool(true)},
+			},
+			wantOwner: "js",
+		},
+		{
+			name: "owned by another kind",
+			owners: []metav1.OwnerReference{
+				{APIVersion: "batch/v1", Kind: "CronJob", Name: "cron"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "job", OwnerReferences: tc.owners}}
+			if got := JobSetOwnerReferenceName(job); got != tc.wantOwner {
+				t.Errorf("JobSetOwnerReferenceName() = %q, want %q", got, tc.wantOwner)
+			}
+			if got := JobSetOwnerReferencePredicate().Create(event.CreateEvent{Object: job}); got != (tc.wantOwner != "") {
+				t.Errorf("JobSetOwnerReferencePredicate().Create() = %t, want %t", got, tc.wantOwner != "")
+			}
+		})
+	}
+}
+
+func TestJobSetLabeledPredicate(t *testing.T) {
+	labeled := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{jobset.JobSetNameKey: "js"}}}
+	if !JobSetLabeledPredicate().Create(event.CreateEvent{Object: labeled}) {
+		t.Errorf("expected object labeled with a JobSet name to be accepted")
+	}
+	unlabeled := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "js"}}}
+	if JobSetLabeledPredicate().Create(event.CreateEvent{Object: unlabeled}) {
+		t.Errorf("expected object without a JobSet name label to be rejected")
+	}
+}
+
+func TestSelectors(t *testing.T) {
+	childLabels := labels.Set{
+		jobset.JobSetNameKey:        "js",
+		jobset.ReplicatedJobNameKey: "workers",
+	}
+	if !JobSetSelector("js").Matches(childLabels) {
+		t.Errorf("expected JobSetSelector to match children of the JobSet")
+	}
+	if JobSetSelector("other").Matches(childLabels) {
+		t.Errorf("expected JobSetSelector not to match children of another JobSet")
+	}
+	if !ReplicatedJobSelector("js", "workers").Matches(childLabels) {
+		t.Errorf("expected ReplicatedJobSelector to match children of the replicatedJob")
+	}
+	if ReplicatedJobSelector("js", "driver").Matches(childLabels) {
+		t.Errorf("expected ReplicatedJobSelector not to match children of another replicatedJob")
+	}
+	if !JobSetLabeledSelector().Matches(childLabels) {
+		t.Errorf("expected JobSetLabeledSelector to match children of any JobSet")
+	}
+	if JobSetLabeledSelector().Matches(labels.Set{"app": "js"}) {
+		t.Errorf("expected JobSetLabeledSelector not to match objects without a JobSet name label")
+	}
+}
@@ -71,6 +71,90 @@ func (j *JobSetWrapper) SetAnnotations(annotations map[string]string) *JobSetWra
 	return j
 }
 
+// ParallelismOverrides sets the value of jobSet.spec.parallelismOverrides.
+func (j *JobSetWrapper) ParallelismOverrides(overrides map[string]int32) *JobSetWrapper {
+	j.Spec.ParallelismOverrides = overrides
+	return j
+}
+
+// JobDefaults sets the value of jobSet.spec.jobDefaults.
+func (j *JobSetWrapper) JobDefaults(defaults *jobset.JobDefaults) *JobSetWrapper {
+	j.Spec.JobDefaults = defaults
+	return j
+}
+
+// JobNaming sets the value of jobSet.spec.jobNaming.
+func (j *JobSetWrapper) JobNaming(naming *jobset.JobNaming) *JobSetWrapper {
+	j.Spec.JobNaming = naming
+	return j
+}
+
+// SchedulingPolicy sets the value of jobSet.spec.schedulingPolicy.
+func (j *JobSetWrapper) SchedulingPolicy(policy *jobset.SchedulingPolicy) *JobSetWrapper {
+	j.Spec.SchedulingPolicy = policy
+	return j
+}
+
+// SchedulerName sets the value of jobSet.spec.schedulerName.
+func (j *JobSetWrapper) SchedulerName(name string) *JobSetWrapper {
+	j.Spec.SchedulerName = name
+	return j
+}
+
+// PodAnnotations sets the value of jobSet.spec.podAnnotations.
+func (j *JobSetWrapper) PodAnnotations(annotations map[string]string) *JobSetWrapper {
+	j.Spec.PodAnnotations = annotations
+	return j
+}
+
+// PropagatedLabels sets the value of jobSet.spec.propagatedLabels.
+func (j *JobSetWrapper) PropagatedLabels(keys ...string) *JobSetWrapper {
+	j.Spec.PropagatedLabels = keys
+	return j
+}
+
+// PropagatedAnnotations sets the value of jobSet.spec.propagatedAnnotations.
+func (j *JobSetWrapper) PropagatedAnnotations(keys ...string) *JobSetWrapper {
+	j.Spec.PropagatedAnnotations = keys
+	return j
+}
+
+// WorkloadType sets the value of jobSet.spec.workloadType.
+func (j *JobSetWrapper) WorkloadType(workloadType jobset.WorkloadType) *JobSetWrapper {
+	j.Spec.WorkloadType = workloadType
+	return j
+}
+
+// TotalPodsEnvName sets the value of jobSet.spec.totalPodsEnvName.
+func (j *JobSetWrapper) TotalPodsEnvName(name string) *JobSetWrapper {
+	j.Spec.TotalPodsEnvName = name
+	return j
+}
+
+// Coordinator sets the value of jobSet.spec.coordinator.
+func (j *JobSetWrapper) Coordinator(replicatedJob string, jobIndex int) *JobSetWrapper {
+	j.Spec.Coordinator = &jobset.Coordinator{ReplicatedJob: replicatedJob, JobIndex: jobIndex}
+	return j
+}
+
+// TTLSecondsAfterFinished sets the value of jobSet.spec.ttlSecondsAfterFinished.
+func (j *JobSetWrapper) TTLSecondsAfterFinished(seconds int32) *JobSetWrapper {
+	j.Spec.TTLSecondsAfterFinished = &seconds
+	return j
+}
+
+// SharedVolumes sets the value of jobSet.spec.sharedVolumes.
+func (j *JobSetWrapper) SharedVolumes(volumes ...jobset.SharedVolume) *JobSetWrapper {
+	j.Spec.SharedVolumes = volumes
+	return j
+}
+
+// ActiveDeadlineSeconds sets the value of jobSet.spec.activeDeadlineSeconds.
+func (j *JobSetWrapper) ActiveDeadlineSeconds(seconds int64) *JobSetWrapper {
+	j.Spec.ActiveDeadlineSeconds = &seconds
+	return j
+}
+
 // Obj returns the inner JobSet.
 func (j *JobSetWrapper) Obj() *jobset.JobSet {
 	return &j.JobSet
@@ -115,12 +199,108 @@ func (r *ReplicatedJobWrapper) EnableDNSHostnames(val bool) *ReplicatedJobWrappe
 	return r
 }
 
+// AnnotatePodHostnames sets the value of ReplicatedJob.Network.AnnotatePodHostnames.
+func (r *ReplicatedJobWrapper) AnnotatePodHostnames(val bool) *ReplicatedJobWrapper {
+	r.ReplicatedJob.Network.AnnotatePodHostnames = pointer.Bool(val)
+	return r
+}
+
+// PublishEndpointSlice sets the value of ReplicatedJob.Network.PublishEndpointSlice.
+func (r *ReplicatedJobWrapper) PublishEndpointSlice(val bool) *ReplicatedJobWrapper {
+	r.ReplicatedJob.Network.PublishEndpointSlice = pointer.Bool(val)
+	return r
+}
+
+// ServiceRestartPolicy sets the value of ReplicatedJob.Network.ServiceRestartPolicy.
+func (r *ReplicatedJobWrapper) ServiceRestartPolicy(policy jobset.ServiceRestartPolicy) *ReplicatedJobWrapper {
+	r.ReplicatedJob.Network.ServiceRestartPolicy = policy
+	return r
+}
+
+// Subdomain sets the value of ReplicatedJob.Network.Subdomain.
+func (r *ReplicatedJobWrapper) Subdomain(subdomain string) *ReplicatedJobWrapper {
+	r.ReplicatedJob.Network.Subdomain = subdomain
+	return r
+}
+
+// ServiceSelector sets the value of ReplicatedJob.Network.ServiceSelector.
+func (r *ReplicatedJobWrapper) ServiceSelector(selector map[string]string) *ReplicatedJobWrapper {
+	r.ReplicatedJob.Network.ServiceSelector = selector
+	return r
+}
+
+// PublishNotReadyAddresses sets the value of ReplicatedJob.Network.PublishNotReadyAddresses.
+func (r *ReplicatedJobWrapper) PublishNotReadyAddresses(val bool) *ReplicatedJobWrapper {
+	r.ReplicatedJob.Network.PublishNotReadyAddresses = &val
+	return r
+}
+
+// DNSPolicy sets the value of ReplicatedJob.Network.DNSPolicy.
+func (r *ReplicatedJobWrapper) DNSPolicy(policy corev1.DNSPolicy) *ReplicatedJobWrapper {
+	r.ReplicatedJob.Network.DNSPolicy = policy
+	return r
+}
+
+// DNSConfig sets the value of ReplicatedJob.Network.DNSConfig.
+func (r *ReplicatedJobWrapper) DNSConfig(config *corev1.PodDNSConfig) *ReplicatedJobWrapper {
+	r.ReplicatedJob.Network.DNSConfig = config
+	return r
+}
+
 // Replicas sets the value of the ReplicatedJob.Replicas.
 func (r *ReplicatedJobWrapper) Replicas(val int) *ReplicatedJobWrapper {
 	r.ReplicatedJob.Replicas = val
 	return r
 }
 
+// CreationPriority sets the value of the ReplicatedJob.CreationPriority.
+func (r *ReplicatedJobWrapper) CreationPriority(priority int32) *ReplicatedJobWrapper {
+	r.ReplicatedJob.CreationPriority = priority
+	return r
+}
+
+// HostNamespaces sets the value of the ReplicatedJob.HostNamespaces.
+func (r *ReplicatedJobWrapper) HostNamespaces(hostNamespaces *jobset.HostNamespaces) *ReplicatedJobWrapper {
+	r.ReplicatedJob.HostNamespaces = hostNamespaces
+	return r
+}
+
+// IndexNodeAffinity sets the value of the ReplicatedJob.IndexNodeAffinity.
+func (r *ReplicatedJobWrapper) IndexNodeAffinity(key string, values ...string) *ReplicatedJobWrapper {
+	r.ReplicatedJob.IndexNodeAffinity = &jobset.IndexNodeAffinity{Key: key, Values: values}
+	return r
+}
+
+// ExclusivePlacement sets the value of the ReplicatedJob.ExclusivePlacement.
+func (r *ReplicatedJobWrapper) ExclusivePlacement(topologyKey string) *ReplicatedJobWrapper {
+	r.ReplicatedJob.ExclusivePlacement = &jobset.ExclusivePlacement{TopologyKey: topologyKey}
+	return r
+}
+
+// SharedVolumes sets the value of the ReplicatedJob.SharedVolumes.
+func (r *ReplicatedJobWrapper) SharedVolumes(volumes ...jobset.SharedVolume) *ReplicatedJobWrapper {
+	r.ReplicatedJob.SharedVolumes = volumes
+	return r
+}
+
+// FailurePolicy sets the value of the ReplicatedJob.FailurePolicy.
+func (r *ReplicatedJobWrapper) FailurePolicy(policy *jobset.FailurePolicy) *ReplicatedJobWrapper {
+	r.ReplicatedJob.FailurePolicy = policy
+	return r
+}
+
+// DependsOn sets the value of the ReplicatedJob.DependsOn.
+func (r *ReplicatedJobWrapper) DependsOn(names ...string) *ReplicatedJobWrapper {
+	r.ReplicatedJob.DependsOn = names
+	return r
+}
+
+// Suspend sets the value of ReplicatedJob.Suspend.
+func (r *ReplicatedJobWrapper) Suspend(suspend bool) *ReplicatedJobWrapper {
+	r.ReplicatedJob.Suspend = pointer.Bool(suspend)
+	return r
+}
+
 // Obj returns the inner ReplicatedJob.
 func (r *ReplicatedJobWrapper) Obj() jobset.ReplicatedJob {
 	return r.ReplicatedJob
@@ -154,6 +334,24 @@ func (j *JobTemplateWrapper) CompletionMode(mode batchv1.CompletionMode) *JobTem
 	return j
 }
 
+// Completions sets the value of job.spec.completions
+func (j *JobTemplateWrapper) Completions(completions int32) *JobTemplateWrapper {
+	j.Spec.Completions = &completions
+	return j
+}
+
+// Parallelism sets the value of job.spec.parallelism
+func (j *JobTemplateWrapper) Parallelism(parallelism int32) *JobTemplateWrapper {
+	j.Spec.Parallelism = &parallelism
+	return j
+}
+
+// PodFailurePolicy sets the value of job.spec.podFailurePolicy
+func (j *JobTemplateWrapper) PodFailurePolicy(policy *batchv1.PodFailurePolicy) *JobTemplateWrapper {
+	j.Spec.PodFailurePolicy = policy
+	return j
+}
+
 // Containers sets the pod template spec containers.
 func (j *JobTemplateWrapper) PodSpec(podSpec corev1.PodSpec) *JobTemplateWrapper {
 	j.Spec.Template.Spec = podSpec
@@ -253,6 +451,18 @@ func (j *JobWrapper) Succeeded(succeeded int32) *JobWrapper {
 	return j
 }
 
+// Failed sets the job status failed.
+func (j *JobWrapper) Failed(failed int32) *JobWrapper {
+	j.Status.Failed = failed
+	return j
+}
+
+// Active sets the job status active.
+func (j *JobWrapper) Active(active int32) *JobWrapper {
+	j.Status.Active = active
+	return j
+}
+
 // Ready sets the job status ready.
 func (j *JobWrapper) Ready(ready int32) *JobWrapper {
 	j.Status.Ready = pointer.Int32(ready)
@@ -263,3 +473,57 @@ func (j *JobWrapper) Ready(ready int32) *JobWrapper {
 func (j *JobWrapper) Obj() *batchv1.Job {
 	return &j.Job
 }
+
+// PodWrapper wraps a Pod.
+type PodWrapper struct {
+	corev1.Pod
+}
+
+// MakePod creates a wrapper for a Pod.
+func MakePod(podName, ns string) *PodWrapper {
+	return &PodWrapper{
+		corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName,
+				Namespace: ns,
+			},
+			Spec: corev1.PodSpec{},
+		},
+	}
+}
+
+// Labels sets the pod labels.
+func (p *PodWrapper) Labels(labels map[string]string) *PodWrapper {
+	p.ObjectMeta.Labels = labels
+	return p
+}
+
+// Annotations sets the pod annotations.
+func (p *PodWrapper) Annotations(annotations map[string]string) *PodWrapper {
+	p.ObjectMeta.Annotations = annotations
+	return p
+}
+
+// Hostname sets the pod hostname and subdomain.
+func (p *PodWrapper) Hostname(hostname, subdomain string) *PodWrapper {
+	p.Spec.Hostname = hostname
+	p.Spec.Subdomain = subdomain
+	return p
+}
+
+// Phase sets the pod status phase.
+func (p *PodWrapper) Phase(phase corev1.PodPhase) *PodWrapper {
+	p.Status.Phase = phase
+	return p
+}
+
+// ContainerStatuses sets the pod status container statuses.
+func (p *PodWrapper) ContainerStatuses(statuses []corev1.ContainerStatus) *PodWrapper {
+	p.Status.ContainerStatuses = statuses
+	return p
+}
+
+// Obj returns the wrapped Pod.
+func (p *PodWrapper) Obj() *corev1.Pod {
+	return &p.Pod
+}
@@ -26,6 +26,8 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
@@ -478,6 +480,49 @@ var _ = ginkgo.Describe("JobSet controller", func() {
 				},
 			},
 		}),
+		ginkgo.Entry("parallelism override updated while suspended applies on resume", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testJobSet(ns).Suspend(true)
+			},
+			updates: []*update{
+				{
+					checkJobSetCondition: testutil.JobSetSuspended,
+				},
+				{
+					jobSetUpdateFn: func(js *jobset.JobSet) {
+						gomega.Eventually(func() error {
+							var jsGet jobset.JobSet
+							if err := k8sClient.Get(ctx, types.NamespacedName{Name: js.Name, Namespace: js.Namespace}, &jsGet); err != nil {
+								return err
+							}
+							jsGet.Spec.ParallelismOverrides = map[string]int32{"replicated-job-b": 2}
+							return k8sClient.Update(ctx, &jsGet)
+						}, timeout, interval).Should(gomega.Succeed())
+					},
+				},
+				{
+					jobSetUpdateFn: func(js *jobset.JobSet) {
+						suspendJobSet(js, false)
+					},
+					checkJobSetState: func(js *jobset.JobSet) {
+						ginkgo.By("checking jobs have the overridden parallelism")
+						gomega.Eventually(func() (bool, error) {
+							var jobList batchv1.JobList
+							if err := k8sClient.List(ctx, &jobList, client.InNamespace(js.Namespace)); err != nil {
+								return false, err
+							}
+							for _, job := range jobList.Items {
+								if job.Labels[jobset.ReplicatedJobNameKey] == "replicated-job-b" && pointer.Int32Deref(job.Spec.Parallelism, 0) != 2 {
+									return false, nil
+								}
+							}
+							return len(jobList.Items) == testutil.NumExpectedJobs(js), nil
+						}, timeout, interval).Should(gomega.Equal(true))
+					},
+					checkJobSetCondition: testutil.JobSetResumed,
+				},
+			},
+		}),
 		ginkgo.Entry("suspend a running jobset", &testCase{
 			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
 				return testJobSet(ns).Suspend(false)
@@ -521,6 +566,33 @@ var _ = ginkgo.Describe("JobSet controller", func() {
 				},
 			},
 		}),
+		ginkgo.Entry("custom subdomain names the headless service and the pod subdomain", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("test-js", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("workers").
+						Job(testing.MakeJobTemplate("test-job", ns.Name).PodSpec(testing.TestPodSpec).CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Subdomain("custom").
+						Replicas(2).
+						Obj())
+			},
+			updates: []*update{
+				{
+					checkJobSetState: func(js *jobset.JobSet) {
+						ginkgo.By("checking the headless service uses the custom subdomain")
+						gomega.Eventually(k8sClient.Get, timeout, interval).WithArguments(ctx, types.NamespacedName{Name: "custom", Namespace: js.Namespace}, &corev1.Service{}).Should(gomega.Succeed())
+
+						ginkgo.By("checking the pods of all jobs use the custom subdomain")
+						var jobList batchv1.JobList
+						gomega.Expect(k8sClient.List(ctx, &jobList, client.InNamespace(js.Namespace))).To(gomega.Succeed())
+						gomega.Expect(jobList.Items).To(gomega.HaveLen(2))
+						for _, job := range jobList.Items {
+							gomega.Expect(job.Spec.Template.Spec.Subdomain).To(gomega.Equal("custom"))
+						}
+					},
+				},
+			},
+		}),
 		ginkgo.Entry("jobset replicatedJobsStatuses should create and update", &testCase{
 			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
 				return testJobSet(ns).Suspend(false)
@@ -545,6 +617,24 @@ var _ = ginkgo.Describe("JobSet controller", func() {
 				},
 			},
 		}),
+		ginkgo.Entry("jobset is ready once all jobs are ready", &testCase{
+			makeJobSet: testJobSet,
+			updates: []*update{
+				{
+					jobUpdateFn:          makeAllJobsReady,
+					checkJobSetCondition: testutil.JobSetReady,
+				},
+				{
+					checkJobSetState: func(js *jobset.JobSet) {
+						gomega.Eventually(func() (int32, error) {
+							var fetched jobset.JobSet
+							err := k8sClient.Get(ctx, types.NamespacedName{Name: js.Name, Namespace: js.Namespace}, &fetched)
+							return fetched.Status.Ready, err
+						}, timeout, interval).Should(gomega.Equal(int32(testutil.NumExpectedJobs(js))))
+					},
+				},
+			},
+		}),
 		ginkgo.Entry("active jobs are deleted after jobset succeeds", &testCase{
 			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
 				return testJobSet(ns).
@@ -587,9 +677,218 @@ var _ = ginkgo.Describe("JobSet controller", func() {
 				},
 			},
 		}),
+		ginkgo.Entry("jobset is deleted after its ttl after finishing expires", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testJobSet(ns).TTLSecondsAfterFinished(1)
+			},
+			updates: []*update{
+				{
+					jobUpdateFn: completeAllJobs,
+					checkJobSetCondition: func(ctx context.Context, k8sClient client.Client, js *jobset.JobSet, timeout time.Duration) {
+						ginkgo.By("checking jobset is deleted")
+						gomega.Eventually(func() bool {
+							return apierrors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Name: js.Name, Namespace: js.Namespace}, &jobset.JobSet{}))
+						}, timeout, interval).Should(gomega.Equal(true))
+					},
+				},
+			},
+		}),
+		ginkgo.Entry("jobset fails and deletes its jobs once its active deadline is exceeded", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testJobSet(ns).ActiveDeadlineSeconds(1)
+			},
+			updates: []*update{
+				{
+					checkJobSetCondition: testutil.JobSetFailed,
+				},
+				{
+					checkJobSetState: func(js *jobset.JobSet) {
+						checkNoActiveJobs(js, 0)
+					},
+				},
+			},
+		}),
+		ginkgo.Entry("jobset with the never operator only completes on request", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testJobSet(ns).SuccessPolicy(&jobset.SuccessPolicy{Operator: jobset.OperatorNever})
+			},
+			updates: []*update{
+				{
+					jobUpdateFn: completeAllJobs,
+					checkJobSetState: func(js *jobset.JobSet) {
+						gomega.Consistently(func() (bool, error) {
+							var fetched jobset.JobSet
+							if err := k8sClient.Get(ctx, types.NamespacedName{Name: js.Name, Namespace: js.Namespace}, &fetched); err != nil {
+								return false, err
+							}
+							return meta.IsStatusConditionTrue(fetched.Status.Conditions, string(jobset.JobSetCompleted)), nil
+						}, timeout, interval).Should(gomega.Equal(false))
+					},
+				},
+				{
+					jobSetUpdateFn: func(js *jobset.JobSet) {
+						gomega.Eventually(func() error {
+							var jsGet jobset.JobSet
+							if err := k8sClient.Get(ctx, types.NamespacedName{Name: js.Name, Namespace: js.Namespace}, &jsGet); err != nil {
+								return err
+							}
+							if jsGet.Annotations == nil {
+								jsGet.Annotations = map[string]string{}
+							}
+							jsGet.Annotations[jobset.CompleteNowKey] = "true"
+							return k8sClient.Update(ctx, &jsGet)
+						}, timeout, interval).Should(gomega.Succeed())
+					},
+					checkJobSetCondition: testutil.JobSetCompleted,
+				},
+			},
+		}),
+		ginkgo.Entry("pod failure policy is passed through to the child jobs", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("test-js", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("workers").
+						Job(testing.MakeJobTemplate("test-job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							PodFailurePolicy(testPodFailurePolicy()).
+							Obj()).
+						Replicas(2).
+						Obj())
+			},
+			updates: []*update{
+				{
+					checkJobSetState: func(js *jobset.JobSet) {
+						ginkgo.By("checking the child jobs have the pod failure policy")
+						gomega.Eventually(func() (int, error) {
+							var jobList batchv1.JobList
+							if err := k8sClient.List(ctx, &jobList, client.InNamespace(js.Namespace)); err != nil {
+								return 0, err
+							}
+							return len(jobList.Items), nil
+						}, timeout, interval).Should(gomega.Equal(2))
+						var jobList batchv1.JobList
+						gomega.Expect(k8sClient.List(ctx, &jobList, client.InNamespace(js.Namespace))).To(gomega.Succeed())
+						for _, job := range jobList.Items {
+							gomega.Expect(apiequality.Semantic.DeepEqual(job.Spec.PodFailurePolicy, testPodFailurePolicy())).To(gomega.BeTrue())
+						}
+					},
+				},
+			},
+		}),
+		ginkgo.Entry("jobs of a replicated job removed while suspended are deleted on resume", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testJobSet(ns).Suspend(true)
+			},
+			updates: []*update{
+				{
+					checkJobSetState: func(js *jobset.JobSet) {
+						ginkgo.By("checking all jobs were created")
+						gomega.Eventually(func() (int, error) {
+							return numJobsOfReplicatedJob(js, "replicated-job-b")
+						}, timeout, interval).Should(gomega.Equal(3))
+					},
+					checkJobSetCondition: testutil.JobSetSuspended,
+				},
+				{
+					jobSetUpdateFn: func(js *jobset.JobSet) {
+						gomega.Eventually(func() error {
+							var jsGet jobset.JobSet
+							if err := k8sClient.Get(ctx, types.NamespacedName{Name: js.Name, Namespace: js.Namespace}, &jsGet); err != nil {
+								return err
+							}
+							jsGet.Spec.ReplicatedJobs = jsGet.Spec.ReplicatedJobs[:1]
+							return k8sClient.Update(ctx, &jsGet)
+						}, timeout, interval).Should(gomega.Succeed())
+					},
+					checkJobSetState: func(js *jobset.JobSet) {
+						ginkgo.By("checking the jobs of the removed replicated job are kept while suspended")
+						gomega.Consistently(func() (int, error) {
+							return numJobsOfReplicatedJob(js, "replicated-job-b")
+						}, timeout, interval).Should(gomega.Equal(3))
+					},
+				},
+				{
+					jobSetUpdateFn: func(js *jobset.JobSet) {
+						suspendJobSet(js, false)
+					},
+					checkJobSetState: func(js *jobset.JobSet) {
+						ginkgo.By("checking the jobs of the removed replicated job are deleted")
+						gomega.Eventually(func() (int, error) {
+							return numJobsOfReplicatedJob(js, "replicated-job-b")
+						}, timeout, interval).Should(gomega.Equal(0))
+						gomega.Expect(numJobsOfReplicatedJob(js, "replicated-job-a")).To(gomega.Equal(1))
+					},
+					checkJobSetCondition: testutil.JobSetResumed,
+				},
+			},
+		}),
+		ginkgo.Entry("jobs of a replicated job with exclusive placement get exclusive affinities", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("test-js", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("workers").
+						Job(testing.MakeJobTemplate("test-job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							Obj()).
+						ExclusivePlacement("cloud.google.com/gke-nodepool").
+						Replicas(2).
+						Obj())
+			},
+			updates: []*update{
+				{
+					checkJobSetState: func(js *jobset.JobSet) {
+						ginkgo.By("checking the child jobs require exclusive node pools")
+						gomega.Eventually(func() (int, error) {
+							return numJobsOfReplicatedJob(js, "workers")
+						}, timeout, interval).Should(gomega.Equal(2))
+						var jobList batchv1.JobList
+						gomega.Expect(k8sClient.List(ctx, &jobList, client.InNamespace(js.Namespace))).To(gomega.Succeed())
+						for _, job := range jobList.Items {
+							affinity := job.Spec.Template.Spec.Affinity
+							gomega.Expect(affinity).NotTo(gomega.BeNil())
+							gomega.Expect(affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(gomega.Equal([]corev1.PodAffinityTerm{{
+								LabelSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+									{Key: jobset.JobNameKey, Operator: metav1.LabelSelectorOpIn, Values: []string{job.Name}},
+								}},
+								TopologyKey:       "cloud.google.com/gke-nodepool",
+								NamespaceSelector: &metav1.LabelSelector{},
+							}}))
+							gomega.Expect(affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(gomega.Equal([]corev1.PodAffinityTerm{{
+								LabelSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+									{Key: jobset.JobNameKey, Operator: metav1.LabelSelectorOpExists},
+									{Key: jobset.JobNameKey, Operator: metav1.LabelSelectorOpNotIn, Values: []string{job.Name}},
+								}},
+								TopologyKey:       "cloud.google.com/gke-nodepool",
+								NamespaceSelector: &metav1.LabelSelector{},
+							}}))
+						}
+					},
+				},
+			},
+		}),
 	) // end of DescribeTable
 }) // end of Describe
 
+// numJobsOfReplicatedJob returns the number of child jobs of the named replicated job.
+func numJobsOfReplicatedJob(js *jobset.JobSet, replicatedJobName string) (int, error) {
+	var jobList batchv1.JobList
+	if err := k8sClient.List(ctx, &jobList, client.InNamespace(js.Namespace), client.MatchingLabels{jobset.ReplicatedJobNameKey: replicatedJobName}); err != nil {
+		return 0, err
+	}
+	return len(jobList.Items), nil
+}
+
+// testPodFailurePolicy returns a pod failure policy failing the job on exit code 42.
+func testPodFailurePolicy() *batchv1.PodFailurePolicy {
+	return &batchv1.PodFailurePolicy{
+		Rules: []batchv1.PodFailurePolicyRule{{
+			Action: batchv1.PodFailurePolicyActionFailJob,
+			OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+				Operator: batchv1.PodFailurePolicyOnExitCodesOpIn,
+				Values:   []int32{42},
+			},
+		}},
+	}
+}
+
 func makeAllJobsReady(jl *batchv1.JobList) {
 	for _, job := range jl.Items {
 		job.Status.Ready = job.Spec.Parallelism
@@ -622,14 +921,14 @@ func checkJobSetReplicatedJobsStatus(js *jobset.JobSet) bool {
 }
 
 func numExpectedServices(js *jobset.JobSet) int {
-	// Expect 1 headless service per replicatedJob.
-	expected := 0
-	for _, rjob := range js.Spec.ReplicatedJobs {
+	// Expect 1 headless service per subdomain of the replicatedJobs with DNS hostnames.
+	subdomains := map[string]bool{}
+	for i, rjob := range js.Spec.ReplicatedJobs {
 		if rjob.Network != nil && rjob.Network.EnableDNSHostnames != nil && *rjob.Network.EnableDNSHostnames {
-			expected += 1
+			subdomains[controllers.GenSubdomain(js, &js.Spec.ReplicatedJobs[i])] = true
 		}
 	}
-	return expected
+	return len(subdomains)
 }
 
 func completeAllJobs(jobList *batchv1.JobList) {
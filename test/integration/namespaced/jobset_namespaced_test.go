@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespacedtest
+
+import (
+	"context"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	jobset "sigs.k8s.io/jobset/api/jobset/v1alpha1"
+	"sigs.k8s.io/jobset/pkg/util/testing"
+	testutil "sigs.k8s.io/jobset/test/util"
+)
+
+const (
+	timeout  = 5 * time.Second
+	interval = time.Millisecond * 250
+)
+
+var _ = ginkgo.Describe("JobSet controller scoped to a namespace", func() {
+	ginkgo.It("should only reconcile JobSets in the watched namespace", func() {
+		ctx := context.Background()
+
+		watched := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: watchedNamespace}}
+		gomega.Expect(k8sClient.Create(ctx, watched)).To(gomega.Succeed())
+		unwatched := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "jobset-ns-"}}
+		gomega.Expect(k8sClient.Create(ctx, unwatched)).To(gomega.Succeed())
+		defer func() {
+			gomega.Expect(testutil.DeleteNamespace(ctx, k8sClient, watched)).To(gomega.Succeed())
+			gomega.Expect(testutil.DeleteNamespace(ctx, k8sClient, unwatched)).To(gomega.Succeed())
+		}()
+
+		ginkgo.By("creating a jobset in the watched namespace")
+		watchedJobSet := testJobSet(watched).Obj()
+		gomega.Expect(k8sClient.Create(ctx, watchedJobSet)).To(gomega.Succeed())
+
+		ginkgo.By("creating a jobset outside the watched namespace")
+		unwatchedJobSet := testJobSet(unwatched).Obj()
+		gomega.Expect(k8sClient.Create(ctx, unwatchedJobSet)).To(gomega.Succeed())
+
+		ginkgo.By("checking jobs are only created for the jobset in the watched namespace")
+		gomega.Eventually(testutil.NumJobs, timeout, interval).WithArguments(ctx, k8sClient, watchedJobSet).Should(gomega.Equal(testutil.NumExpectedJobs(watchedJobSet)))
+		gomega.Consistently(testutil.NumJobs, timeout, interval).WithArguments(ctx, k8sClient, unwatchedJobSet).Should(gomega.Equal(0))
+	})
+})
+
+func testJobSet(ns *corev1.Namespace) *testing.JobSetWrapper {
+	return testing.MakeJobSet("test-js", ns.Name).
+		SuccessPolicy(&jobset.SuccessPolicy{Operator: jobset.OperatorAll, TargetReplicatedJobs: []string{}}).
+		ReplicatedJob(testing.MakeReplicatedJob("replicated-job").
+			Job(testing.MakeJobTemplate("test-job", ns.Name).PodSpec(testing.TestPodSpec).CompletionMode(batchv1.IndexedCompletion).Obj()).
+			EnableDNSHostnames(true).
+			Replicas(2).
+			Obj())
+}
@@ -17,12 +17,14 @@ package webhooktest
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
@@ -185,6 +187,368 @@ var _ = ginkgo.Describe("jobset webhook defaulting", func() {
 			},
 			updateShouldFail: false,
 		}),
+		ginkgo.Entry("validate jobSet should not fail on NodeSelectors Update when replicatedJob is suspended", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-hostnames-non-indexed", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Suspend(true).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ReplicatedJobs[0].Template.Spec.Template.Spec.NodeSelector = map[string]string{"test": "test"}
+			},
+			updateShouldFail: false,
+		}),
+		ginkgo.Entry("validate jobSet should not fail on NodeSelectors and Tolerations Update of a replicatedJob when jobset is suspended", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-rjob-scheduling", ns.Name).
+					Suspend(true).
+					ReplicatedJob(testing.MakeReplicatedJob("driver").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj()).
+					ReplicatedJob(testing.MakeReplicatedJob("workers").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ReplicatedJobs[1].Template.Spec.Template.Spec.NodeSelector = map[string]string{"test": "test"}
+				js.Spec.ReplicatedJobs[1].Template.Spec.Template.Spec.Tolerations = []corev1.Toleration{{Key: "test", Operator: corev1.TolerationOpExists}}
+			},
+			updateShouldFail: false,
+		}),
+		ginkgo.Entry("validate jobSet should not fail on NodeSelectors and Tolerations Update of a suspended replicatedJob", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-rjob-scheduling", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("driver").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj()).
+					ReplicatedJob(testing.MakeReplicatedJob("workers").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Suspend(true).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ReplicatedJobs[1].Template.Spec.Template.Spec.NodeSelector = map[string]string{"test": "test"}
+				js.Spec.ReplicatedJobs[1].Template.Spec.Template.Spec.Tolerations = []corev1.Toleration{{Key: "test", Operator: corev1.TolerationOpExists}}
+			},
+			updateShouldFail: false,
+		}),
+		ginkgo.Entry("validate jobSet should fail on NodeSelectors Update of a replicatedJob which is not suspended", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-rjob-scheduling", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("driver").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj()).
+					ReplicatedJob(testing.MakeReplicatedJob("workers").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Suspend(true).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ReplicatedJobs[0].Template.Spec.Template.Spec.NodeSelector = map[string]string{"test": "test"}
+			},
+			updateShouldFail: true,
+		}),
+		ginkgo.Entry("validate jobSet should fail on Tolerations Update of a replicatedJob when nothing is suspended", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-rjob-scheduling", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("driver").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj()).
+					ReplicatedJob(testing.MakeReplicatedJob("workers").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ReplicatedJobs[1].Template.Spec.Template.Spec.Tolerations = []corev1.Toleration{{Key: "test", Operator: corev1.TolerationOpExists}}
+			},
+			updateShouldFail: true,
+		}),
+		ginkgo.Entry("validate jobSet immutable for fields other than NodeSelector and Tolerations of a suspended replicatedJob", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-rjob-scheduling", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("driver").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj()).
+					ReplicatedJob(testing.MakeReplicatedJob("workers").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Suspend(true).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ReplicatedJobs[1].Template.Spec.Template.Spec.Tolerations = []corev1.Toleration{{Key: "test", Operator: corev1.TolerationOpExists}}
+				js.Spec.ReplicatedJobs[1].Template.Spec.Template.Spec.Hostname = "test"
+			},
+			updateShouldFail: true,
+		}),
+		ginkgo.Entry("validate jobSet should not fail on replicatedJob suspend Update", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-hostnames-non-indexed", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ReplicatedJobs[0].Suspend = pointer.Bool(true)
+			},
+			updateShouldFail: false,
+		}),
+		ginkgo.Entry("validate jobSet should not fail on parallelism override Update when jobset is suspended", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-parallelism", ns.Name).
+					Suspend(true).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ParallelismOverrides = map[string]int32{"rjob": 2}
+			},
+			updateShouldFail: false,
+		}),
+		ginkgo.Entry("validate jobSet should not fail on container image Update when jobset is failed", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-image", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				meta.SetStatusCondition(&js.Status.Conditions, metav1.Condition{
+					Type:   string(jobset.JobSetFailed),
+					Status: metav1.ConditionTrue,
+					Reason: "FailedJobs",
+				})
+				gomega.Expect(k8sClient.Status().Update(ctx, js)).Should(gomega.Succeed())
+				js.Spec.ReplicatedJobs[0].Template.Spec.Template.Spec.Containers[0].Image = "busybox:stable"
+			},
+			updateShouldFail: false,
+		}),
+		ginkgo.Entry("validate jobSet should fail on container image Update when the restarted jobs would have too long names", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				// The last job name, js-image-aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa-rjob-0-r9, is 63 characters long.
+				return testing.MakeJobSet("js-image-aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", ns.Name).
+					FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 9, RestartStrategy: jobset.RestartStrategyBlueGreen}).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				// After restarting with updated images, the jobs of the last attempt would be
+				// suffixed with r19.
+				js.Status.Restarts = 9
+				meta.SetStatusCondition(&js.Status.Conditions, metav1.Condition{
+					Type:   string(jobset.JobSetFailed),
+					Status: metav1.ConditionTrue,
+					Reason: "ReachedMaxRestarts",
+				})
+				gomega.Expect(k8sClient.Status().Update(ctx, js)).Should(gomega.Succeed())
+				js.Spec.ReplicatedJobs[0].Template.Spec.Template.Spec.Containers[0].Image = "busybox:stable"
+			},
+			updateShouldFail: true,
+		}),
+		ginkgo.Entry("validate jobSet should not fail on container image Update when jobset is suspended", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-image", ns.Name).
+					Suspend(true).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ReplicatedJobs[0].Template.Spec.Template.Spec.Containers[0].Image = "busybox:stable"
+			},
+			updateShouldFail: false,
+		}),
+		ginkgo.Entry("validate jobSet should fail on container image Update when jobset is running", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-image", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ReplicatedJobs[0].Template.Spec.Template.Spec.Containers[0].Image = "busybox:stable"
+			},
+			updateShouldFail: true,
+		}),
+		ginkgo.Entry("validate jobSet should fail on other pod template Update when jobset is failed", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-image", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				meta.SetStatusCondition(&js.Status.Conditions, metav1.Condition{
+					Type:   string(jobset.JobSetFailed),
+					Status: metav1.ConditionTrue,
+					Reason: "FailedJobs",
+				})
+				gomega.Expect(k8sClient.Status().Update(ctx, js)).Should(gomega.Succeed())
+				js.Spec.ReplicatedJobs[0].Template.Spec.Template.Spec.Containers[0].Args = []string{"--fixed"}
+			},
+			updateShouldFail: true,
+		}),
+		ginkgo.Entry("validate jobSet should fail on parallelism override Update", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-parallelism", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ParallelismOverrides = map[string]int32{"rjob": 2}
+			},
+			updateShouldFail: true,
+		}),
+		ginkgo.Entry("validate jobSet should fail on parallelism override Update of a suspended replicatedJob when gang scheduled", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-gang-parallelism", ns.Name).
+					SchedulingPolicy(&jobset.SchedulingPolicy{Gang: &jobset.GangPolicy{}}).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							Completions(1).
+							Parallelism(1).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Suspend(true).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ParallelismOverrides = map[string]int32{"rjob": 2}
+			},
+			updateShouldFail: true,
+		}),
+		ginkgo.Entry("validate jobSet should fail on parallelism override Update of a suspended replicatedJob when injecting the total pods", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-total-pods-parallelism", ns.Name).
+					TotalPodsEnvName("WORLD_SIZE").
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							Completions(1).
+							Parallelism(1).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Suspend(true).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ParallelismOverrides = map[string]int32{"rjob": 2}
+			},
+			updateShouldFail: true,
+		}),
+		ginkgo.Entry("validate jobSet should fail on adding the managed service account annotation when a pod template sets its service account", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				podSpec := testing.TestPodSpec.DeepCopy()
+				podSpec.ServiceAccountName = "custom"
+				return testing.MakeJobSet("js-managed-sa", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(*podSpec).Obj()).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Annotations = map[string]string{jobset.ManagedServiceAccountKey: "true"}
+			},
+			updateShouldFail: true,
+		}),
+		ginkgo.Entry("validate jobSet should not fail on replicatedJob removal when jobset is suspended", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-remove-rjob", ns.Name).
+					Suspend(true).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob-a").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Obj()).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob-b").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ReplicatedJobs = js.Spec.ReplicatedJobs[:1]
+			},
+			updateShouldFail: false,
+		}),
+		ginkgo.Entry("validate jobSet should fail on replicatedJob removal", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-remove-rjob", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob-a").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Obj()).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob-b").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ReplicatedJobs = js.Spec.ReplicatedJobs[:1]
+			},
+			updateShouldFail: true,
+		}),
+		ginkgo.Entry("validate jobSet should fail on removal of a replicatedJob other replicatedJobs depend on", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("js-remove-rjob", ns.Name).
+					Suspend(true).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob-a").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).Obj()).
+						Obj()).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob-b").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).Obj()).
+						DependsOn("rjob-a").
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Spec.ReplicatedJobs = js.Spec.ReplicatedJobs[1:]
+			},
+			updateShouldFail: true,
+		}),
 		ginkgo.Entry("validate jobSet should fail on NodeSelectors Update", &testCase{
 			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
 				return testing.MakeJobSet("js-hostnames-non-indexed", ns.Name).
@@ -271,6 +635,172 @@ var _ = ginkgo.Describe("jobset webhook defaulting", func() {
 			},
 			jobSetCreationShouldFail: true,
 		}),
+		ginkgo.Entry("coordinator in a non-indexed replicatedJob is rejected", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("coordinator", ns.Name).
+					Coordinator("rjob", 0).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.NonIndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj())
+			},
+			jobSetCreationShouldFail: true,
+		}),
+		ginkgo.Entry("pod template subdomain is rejected when DNS hostnames are enabled", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				podSpec := testing.TestPodSpec.DeepCopy()
+				podSpec.Subdomain = "custom"
+				return testing.MakeJobSet("template-subdomain", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(*podSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj())
+			},
+			jobSetCreationShouldFail: true,
+		}),
+		ginkgo.Entry("pod template with duplicate container names is rejected", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				podSpec := testing.TestPodSpec.DeepCopy()
+				podSpec.InitContainers = []corev1.Container{{Name: "test-container", Image: "busybox:latest"}}
+				return testing.MakeJobSet("duplicate-containers", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(*podSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj())
+			},
+			jobSetCreationShouldFail: true,
+		}),
+		ginkgo.Entry("DNS hostnames enabled and disabled on the shared headless service are rejected", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("mixed-dns-hostnames", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("leader").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj()).
+					ReplicatedJob(testing.MakeReplicatedJob("workers").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(false).
+						Obj())
+			},
+			jobSetCreationShouldFail: true,
+		}),
+		ginkgo.Entry("jobset name which is not a valid DNS subdomain is rejected", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("Invalid_JobSet_Name", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj())
+			},
+			jobSetCreationShouldFail: true,
+		}),
+		ginkgo.Entry("exclusive placement annotation added to a jobset with the BlueGreen restart strategy is rejected", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("blue-green", ns.Name).
+					FailurePolicy(&jobset.FailurePolicy{MaxRestarts: 1, RestartStrategy: jobset.RestartStrategyBlueGreen}).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj())
+			},
+			updateJobSet: func(js *jobset.JobSet) {
+				js.Annotations = map[string]string{jobset.ExclusiveKey: "topology.kubernetes.io/zone"}
+			},
+			updateShouldFail: true,
+		}),
+		ginkgo.Entry("jobset with the maximum number of replicatedJobs is accepted", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return makeJobSetWithReplicatedJobs("max-rjobs", ns.Name, jobset.MaxReplicatedJobs)
+			},
+		}),
+		ginkgo.Entry("jobset with more than the maximum number of replicatedJobs is rejected", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return makeJobSetWithReplicatedJobs("too-many-rjobs", ns.Name, jobset.MaxReplicatedJobs+1)
+			},
+			jobSetCreationShouldFail: true,
+		}),
+		ginkgo.Entry("shared volume which collides with a pod template volume is rejected", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				podSpec := testing.TestPodSpec.DeepCopy()
+				podSpec.Volumes = []corev1.Volume{{
+					Name:         "dshm",
+					VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+				}}
+				return testing.MakeJobSet("shared-volumes", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(*podSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						SharedVolumes(jobset.SharedVolume{Name: "dshm", MountPath: "/dev/shm", Medium: corev1.StorageMediumMemory}).
+						EnableDNSHostnames(true).
+						Obj())
+			},
+			jobSetCreationShouldFail: true,
+		}),
+		ginkgo.Entry("jobset shared volume which collides with a pod template volume is rejected", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				podSpec := testing.TestPodSpec.DeepCopy()
+				podSpec.Volumes = []corev1.Volume{{
+					Name:         "dshm",
+					VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+				}}
+				return testing.MakeJobSet("jobset-shared-volumes", ns.Name).
+					SharedVolumes(jobset.SharedVolume{Name: "dshm", MountPath: "/dev/shm", Medium: corev1.StorageMediumMemory}).
+					ReplicatedJob(testing.MakeReplicatedJob("leader").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj()).
+					ReplicatedJob(testing.MakeReplicatedJob("workers").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(*podSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(true).
+						Obj())
+			},
+			jobSetCreationShouldFail: true,
+		}),
+		ginkgo.Entry("annotating pod hostnames without DNS hostnames is rejected", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("annotate-hostnames", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						EnableDNSHostnames(false).
+						AnnotatePodHostnames(true).
+						Obj())
+			},
+			jobSetCreationShouldFail: true,
+		}),
+		ginkgo.Entry("host network with DNS hostnames is rejected", &testCase{
+			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
+				return testing.MakeJobSet("host-network", ns.Name).
+					ReplicatedJob(testing.MakeReplicatedJob("rjob").
+						Job(testing.MakeJobTemplate("job", ns.Name).
+							PodSpec(testing.TestPodSpec).
+							CompletionMode(batchv1.IndexedCompletion).Obj()).
+						HostNamespaces(&jobset.HostNamespaces{Network: pointer.Bool(true)}).
+						EnableDNSHostnames(true).
+						Obj())
+			},
+			jobSetCreationShouldFail: true,
+		}),
 		ginkgo.Entry("suspend jobset", &testCase{
 			makeJobSet: func(ns *corev1.Namespace) *testing.JobSetWrapper {
 				return testing.MakeJobSet("js-hostnames-non-indexed", ns.Name).
@@ -337,3 +867,17 @@ var _ = ginkgo.Describe("jobset webhook defaulting", func() {
 		}),
 	) // end of DescribeTable
 }) // end of Describe
+
+// makeJobSetWithReplicatedJobs returns a JobSet defining the given number of replicatedJobs.
+func makeJobSetWithReplicatedJobs(name, ns string, n int) *testing.JobSetWrapper {
+	js := testing.MakeJobSet(name, ns)
+	for i := 0; i < n; i++ {
+		js.ReplicatedJob(testing.MakeReplicatedJob(fmt.Sprintf("rjob-%d", i)).
+			Job(testing.MakeJobTemplate("job", ns).
+				PodSpec(testing.TestPodSpec).
+				CompletionMode(batchv1.IndexedCompletion).Obj()).
+			EnableDNSHostnames(true).
+			Obj())
+	}
+	return js
+}